@@ -12,6 +12,8 @@ import (
 	"github.com/ppacher/system-conf/conf"
 	"github.com/tierklinik-dobersberg/cis/internal/app"
 	"github.com/tierklinik-dobersberg/cis/pkg/svcenv"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/fileprovider"
 )
 
 // newLineReader returns an io.Reader that just emits a new line.
@@ -22,20 +24,56 @@ func newLineReader() io.Reader {
 	return strings.NewReader("\n")
 }
 
-// trunk-ignore(golangci-lint/cyclop)
-func loadConfig() (*app.Config, *conf.File, error) {
-	env := svcenv.Env()
+// configDirectory returns the directory that holds cis.conf. It's either
+// env.ConfigurationDirectory or the current working-directory of the
+// service.
+func configDirectory() (string, error) {
+	if dir := svcenv.Env().ConfigurationDirectory; dir != "" {
+		return dir, nil
+	}
 
-	// The configuration file is either located in env.ConfigurationDirectory
-	// or in the current working-directory of the service.
+	return os.Getwd()
+}
+
+// fileConfigProviders returns a read-only runtime.ConfigProvider for
+// cis.conf and each .conf file in conf.d. Configuration instances
+// defined in those files are merged with the ones stored in the
+// writable provider.
+func fileConfigProviders() ([]runtime.ConfigProvider, error) {
+	dir, err := configDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "conf.d", "*.conf"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
 
-	dir := env.ConfigurationDirectory
-	if dir == "" {
-		var err error
-		dir, err = os.Getwd()
+	paths := append([]string{filepath.Join(dir, "cis.conf")}, matches...)
+
+	providers := make([]runtime.ConfigProvider, 0, len(paths))
+	for _, path := range paths {
+		file, err := conf.LoadFile(path)
 		if err != nil {
-			return nil, nil, err
+			return nil, fmt.Errorf("failed to load %s: %w", path, err)
 		}
+
+		provider := fileprovider.New(file)
+		provider.SetMigrations(runtime.GlobalSchema.Schemas()...)
+
+		providers = append(providers, provider)
+	}
+
+	return providers, nil
+}
+
+// trunk-ignore(golangci-lint/cyclop)
+func loadConfig() (*app.Config, *conf.File, error) {
+	dir, err := configDirectory()
+	if err != nil {
+		return nil, nil, err
 	}
 	log.Printf("configuration directory: %s", dir)
 
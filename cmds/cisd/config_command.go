@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/logger"
+)
+
+func getConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Export and import configuration",
+	}
+
+	cmd.AddCommand(
+		getConfigExportCommand(),
+		getConfigImportCommand(),
+		getConfigRotateSecretCommand(),
+	)
+
+	return cmd
+}
+
+func getConfigExportCommand() *cobra.Command {
+	var (
+		format  string
+		output  string
+		schemas []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export all non-internal configuration schemas",
+		Run: func(_ *cobra.Command, _ []string) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			_, _, ctx = getApp(ctx)
+
+			if format == "" {
+				format = bundleFormatFromPath(output)
+			}
+			if format == "" {
+				format = runtime.BundleFormatConf
+			}
+
+			bundle, err := runtime.GlobalSchema.Export(ctx, schemas...)
+			if err != nil {
+				logger.Fatalf(ctx, err.Error())
+			}
+
+			var w io.Writer = os.Stdout
+			if output != "" && output != "-" {
+				f, err := os.Create(output)
+				if err != nil {
+					logger.Fatalf(ctx, err.Error())
+				}
+				defer f.Close()
+
+				w = f
+			}
+
+			if err := runtime.EncodeBundle(w, bundle, format); err != nil {
+				logger.Fatalf(ctx, err.Error())
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	{
+		flags.StringVarP(&format, "format", "f", "", "Output format, either conf or json. Defaults to the extension of --output or conf")
+		flags.StringVarP(&output, "output", "o", "", "Write to the file instead of stdout")
+		flags.StringSliceVarP(&schemas, "schema", "s", nil, "Only export the given schemas")
+	}
+
+	return cmd
+}
+
+func getConfigImportCommand() *cobra.Command {
+	var (
+		format string
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import [file]",
+		Short: "Import configuration from a .conf file or JSON bundle",
+		Long:  "Import configuration from a .conf file or JSON bundle. Reads from stdin if no file or - is given.",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			_, _, ctx = getApp(ctx)
+
+			var r io.Reader = os.Stdin
+			if len(args) == 1 && args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					logger.Fatalf(ctx, err.Error())
+				}
+				defer f.Close()
+
+				r = f
+
+				if format == "" {
+					format = bundleFormatFromPath(args[0])
+				}
+			}
+
+			bundle, err := runtime.DecodeBundle(r, format)
+			if err != nil {
+				logger.Fatalf(ctx, err.Error())
+			}
+
+			results := runtime.GlobalSchema.Import(ctx, bundle, dryRun)
+
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(results); err != nil {
+				logger.Fatalf(ctx, err.Error())
+			}
+
+			for _, res := range results {
+				if res.Error != "" {
+					logger.Fatalf(ctx, "failed to import one or more sections")
+				}
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	{
+		flags.StringVarP(&format, "format", "f", "", "Input format, either conf or json. Detected from the file if empty")
+		flags.BoolVar(&dryRun, "dry-run", false, "Only validate the configuration")
+	}
+
+	return cmd
+}
+
+func getConfigRotateSecretCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate-secret",
+		Short: "Re-encrypt secret configuration options using the current Secret",
+		Long: "Re-encrypt all secret configuration options that are stored unencrypted or using a previous secret.\n" +
+			"Update Secret in cis.conf first and pass all secrets that have been used before using --previous.",
+		Run: func(_ *cobra.Command, _ []string) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			_, _, ctx = getApp(ctx)
+
+			if secrets == nil {
+				logger.Fatalf(ctx, "Secret must be configured to encrypt secret options")
+			}
+
+			count, err := rotateSecrets(ctx)
+			if err != nil {
+				logger.Fatalf(ctx, err.Error())
+			}
+
+			fmt.Printf("re-encrypted %d configuration instances\n", count)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&previousSecrets, "previous", nil, "A secret that has been used to encrypt configuration values before")
+
+	return cmd
+}
+
+// bundleFormatFromPath returns the bundle format for the extension of
+// path. It returns an empty string for unknown extensions.
+func bundleFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return runtime.BundleFormatJSON
+	case ".conf":
+		return runtime.BundleFormatConf
+	}
+
+	return ""
+}
+
+// rotateSecrets re-encrypts all secret options that are stored
+// unencrypted or using a previous secret. Updates store the current
+// schema version so all migrations must have been applied before.
+func rotateSecrets(ctx context.Context) (int, error) {
+	if !migrationsApplied {
+		return 0, errors.New("schema migrations are pending, apply them using \"cisd migrate up\" first")
+	}
+
+	return secrets.Rotate(ctx, runtime.GlobalSchema.Schemas()...)
+}
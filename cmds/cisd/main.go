@@ -9,6 +9,7 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	idmv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/idm/v1"
+	"github.com/tierklinik-dobersberg/cis/gen/go/tkd/cis/v1/cisv1connect"
 	"github.com/tierklinik-dobersberg/cis/internal/api/configapi"
 	"github.com/tierklinik-dobersberg/cis/internal/api/doorapi"
 	"github.com/tierklinik-dobersberg/cis/internal/api/openinghoursapi"
@@ -27,9 +29,13 @@ import (
 	"github.com/tierklinik-dobersberg/cis/internal/cfgspec"
 	"github.com/tierklinik-dobersberg/cis/internal/door"
 	"github.com/tierklinik-dobersberg/cis/internal/openinghours"
+	"github.com/tierklinik-dobersberg/cis/pkg/svcenv"
 	tracemw "github.com/tierklinik-dobersberg/cis/pkg/trace"
 	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/dirprovider"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/layeredprovider"
 	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/mongoprovider"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/secretprovider"
 	"github.com/tierklinik-dobersberg/cis/runtime/session"
 	"github.com/tierklinik-dobersberg/logger"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -49,6 +55,25 @@ import (
 //go:embed ui
 var static embed.FS
 
+// configCollection is the mongodb collection that stores configuration
+// instances.
+const configCollection = "config"
+
+// autoMigrate is set by the --migrate flag.
+var autoMigrate = true
+
+// previousSecrets may hold secrets that have been used to encrypt
+// configuration values before. secrets is set by getApp if encryption
+// of secret options is enabled.
+var (
+	previousSecrets []string
+	secrets         *secretprovider.SecretProvider
+)
+
+// migrationsApplied is set by getApp if all stored configuration
+// instances are at the current schema version.
+var migrationsApplied bool
+
 func main() {
 	cmd := getRootCommand()
 
@@ -65,8 +90,12 @@ func getRootCommand() *cobra.Command {
 		},
 	}
 
+	cmd.PersistentFlags().BoolVar(&autoMigrate, "migrate", true, "Apply pending schema migrations on startup")
+
 	cmd.AddCommand(
 		getDoorCommand(),
+		getConfigCommand(),
+		getMigrateCommand(),
 	)
 
 	return cmd
@@ -96,6 +125,13 @@ func getApp(baseCtx context.Context) (*app.App, *tracesdk.TracerProvider, contex
 	}
 	logApt.setMaxSeverity(lvl)
 
+	// restrict access to the configuration of the entry door.
+	for _, name := range []string{"Door", "OpeningHour", "HolidayCalendar"} {
+		if err := runtime.GlobalSchema.SetRoles(name, cfg.ConfigReadRoles, cfg.ConfigWriteRoles); err != nil {
+			log.Fatalf("configuration: %s", err)
+		}
+	}
+
 	//
 	// prepare tracing
 	//
@@ -112,9 +148,83 @@ func getApp(baseCtx context.Context) (*app.App, *tracesdk.TracerProvider, contex
 	//
 	// prepare the mongodb client and maybe setup log-forwarding to mongodb
 	//
+	// Without MONGO_URL the configuration is stored in the state
+	// directory which is sufficient for small installations.
 	databaseName := os.Getenv("MONGO_DATABASE")
-	mongoClient := getMongoClient(ctx, os.Getenv("MONGO_URL"))
-	runtime.GlobalSchema.SetProvider(mongoprovider.New(mongoClient, databaseName, "config"))
+
+	var (
+		mongoClient *mongo.Client
+		writable    runtime.ConfigProvider
+	)
+	if mongoURL := os.Getenv("MONGO_URL"); mongoURL != "" {
+		mongoClient = getMongoClient(ctx, mongoURL)
+
+		provider := mongoprovider.New(mongoClient, databaseName, configCollection)
+		provider.SetVersionFunc(runtime.GlobalSchema.SchemaVersion)
+		writable = provider
+	} else {
+		configDir := filepath.Join(svcenv.Env().StateDirectory, "config")
+		logger.Infof(ctx, "MONGO_URL not set, storing configuration in %s", configDir)
+
+		provider, err := dirprovider.New(configDir)
+		if err != nil {
+			logger.Fatalf(ctx, "config-provider: %s", err.Error())
+		}
+
+		// files don't record a schema version so option migrations
+		// are applied when reading.
+		provider.SetMigrations(runtime.GlobalSchema.Schemas()...)
+		writable = provider
+	}
+
+	// encrypt the values of secret options at rest.
+	if cfg.Secret != "" {
+		keys, err := secretprovider.NewKeyring(cfg.Secret, previousSecrets...)
+		if err != nil {
+			logger.Fatalf(ctx, "config-provider: %s", err.Error())
+		}
+
+		secrets = secretprovider.New(writable, keys, runtime.GlobalSchema.Schemas()...)
+		writable = secrets
+	} else {
+		logger.Errorf(ctx, "Secret is not configured, secret configuration options are stored unencrypted")
+	}
+
+	// configuration instances from cis.conf and conf.d are available
+	// as read-only defaults.
+	fileProviders, err := fileConfigProviders()
+	if err != nil {
+		logger.Fatalf(ctx, "config-provider: %s", err.Error())
+	}
+	runtime.GlobalSchema.SetProvider(layeredprovider.New(writable, fileProviders...))
+
+	if mongoClient != nil {
+		revisions, err := mongoprovider.NewRevisionStore(ctx, mongoClient, databaseName, "config-revisions")
+		if err != nil {
+			logger.Fatalf(ctx, "config-revisions: %s", err.Error())
+		}
+		runtime.GlobalSchema.SetRevisionStore(revisions)
+
+		// migrate stored configuration instances before any controller
+		// loads them.
+		addConfigMigrations()
+
+		if autoMigrate {
+			if err := applyMigrations(ctx, mongoClient.Database(databaseName)); err != nil {
+				logger.Fatalf(ctx, "schema: %s", err.Error())
+			}
+		} else {
+			logger.Infof(ctx, "skipping schema migrations, use \"cisd migrate up\" to apply them")
+		}
+	}
+
+	// configuration files are migrated when they are read.
+	migrationsApplied = mongoClient == nil || autoMigrate
+
+	// validate references to roles and users against the identity provider.
+	idmProvider := idm.New(os.Getenv("IDM_URL"), http.DefaultClient)
+	runtime.GlobalSchema.AddReferenceResolver(runtime.RolesRef, idmProvider.RoleResolver())
+	runtime.GlobalSchema.AddReferenceResolver(runtime.UsersRef, idmProvider.UserResolver())
 
 	//
 	// prepare opeing hours controller
@@ -123,6 +233,24 @@ func getApp(baseCtx context.Context) (*app.App, *tracesdk.TracerProvider, contex
 	if err != nil {
 		logger.Fatalf(ctx, "opening-hours-controler: %s", err.Error())
 	}
+	if mongoClient != nil {
+		historyDB, err := openinghours.NewHistoryDatabase(ctx, mongoClient, databaseName)
+		if err != nil {
+			logger.Fatalf(ctx, "opening-hours-history: %s", err.Error())
+		}
+
+		if err := openingHoursCtrl.EnableHistory(ctx, historyDB); err != nil {
+			logger.Fatalf(ctx, "opening-hours-history: %s", err.Error())
+		}
+	}
+
+	eventSource, err := openinghours.NewEventSource(ctx, cfg.Config, openingHoursCtrl.Location())
+	if err != nil {
+		logger.Fatalf(ctx, "opening-hours-events: %s", err.Error())
+	}
+	if eventSource != nil {
+		go openingHoursCtrl.WatchEvents(ctx, eventSource, cfg.OverridePollInterval)
+	}
 
 	//
 	// prepare entry door controller
@@ -132,6 +260,13 @@ func getApp(baseCtx context.Context) (*app.App, *tracesdk.TracerProvider, contex
 		logger.Fatalf(ctx, "door-controler: %s", err.Error())
 	}
 
+	//
+	// pick up configuration changes made by other instances
+	//
+	if err := runtime.GlobalSchema.Watch(ctx); err != nil {
+		logger.Errorf(ctx, "failed to watch configuration changes: %s", err.Error())
+	}
+
 	//
 	// Create a new application context and make sure it's added
 	// to each incoming HTTP Request.
@@ -140,7 +275,7 @@ func getApp(baseCtx context.Context) (*app.App, *tracesdk.TracerProvider, contex
 		cfg,
 		doorController,
 		os.Getenv("ROSTERD_SERVER"),
-		idm.New(os.Getenv("IDM_URL"), http.DefaultClient),
+		idmProvider,
 	)
 
 	ctx = app.With(baseCtx, appCtx)
@@ -222,6 +357,20 @@ func setupAPI(app *app.App, grp *echo.Echo) {
 		// openinghoursapi provides access to the configured openinghours
 		openinghoursapi.Setup(app, apis.Group("openinghours/", session.Require()))
 
+		// the public opening hours API does not require authentication and
+		// must be explicitly enabled.
+		if app.Config.PublicOpeningHours {
+			openinghoursapi.SetupPublic(app, apis.Group("public/openinghours/"))
+		}
+
+		// connect services are mounted at the server root as required by
+		// the connect and gRPC protocols.
+		path, handler := cisv1connect.NewOpeningHoursServiceHandler(openinghoursapi.NewService(app))
+		grp.Any(path+"*", echo.WrapHandler(handler), session.Middleware(userProvider), session.Require())
+
+		path, handler = cisv1connect.NewDoorServiceHandler(doorapi.NewService(app))
+		grp.Any(path+"*", echo.WrapHandler(handler), session.Middleware(userProvider), session.Require())
+
 		// grp.StaticFS("", webapp)
 		// grp.FileFS("/*", "index.html", webapp)
 
@@ -264,6 +413,18 @@ func runMain() {
 
 	app, traceProvider, ctx := getApp(ctx)
 
+	// encrypt values of secret options that are still stored in plain
+	// text, e.g. because the option has been marked as secret after
+	// they have been saved.
+	if secrets != nil {
+		count, err := rotateSecrets(ctx)
+		if err != nil {
+			logger.Errorf(ctx, "config-provider: failed to encrypt secret options: %s", err)
+		} else if count > 0 {
+			logger.Infof(ctx, "encrypted secret options of %d configuration instances", count)
+		}
+	}
+
 	// Cleanly shutdown and flush telemetry when the application exits.
 	if traceProvider != nil {
 		defer func(ctx context.Context) {
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/mongoprovider"
+	"github.com/tierklinik-dobersberg/cis/runtime/schema"
+	"github.com/tierklinik-dobersberg/logger"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func getMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage database schema migrations",
+	}
+
+	cmd.AddCommand(
+		getMigrateStatusCommand(),
+		getMigrateUpCommand(),
+		getMigrateRestoreCommand(),
+	)
+
+	return cmd
+}
+
+func getMigrateStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the current and latest version of each migration key",
+		Run: func(_ *cobra.Command, _ []string) {
+			ctx := context.Background()
+
+			cli, db := getMigrationEnv(ctx)
+			defer disconnect(cli)
+
+			status, err := schema.Status(ctx, db)
+			if err != nil {
+				logger.Fatalf(ctx, err.Error())
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "KEY\tCURRENT\tLATEST\tPENDING")
+
+			for _, s := range status {
+				current := s.Current
+				if current == "" {
+					current = "-"
+				}
+
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", s.Key, current, s.Latest, len(s.Pending))
+			}
+
+			_ = w.Flush()
+		},
+	}
+}
+
+func getMigrateUpCommand() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		Run: func(_ *cobra.Command, _ []string) {
+			ctx := context.Background()
+
+			cli, db := getMigrationEnv(ctx)
+			defer disconnect(cli)
+
+			if dryRun {
+				status, err := schema.Status(ctx, db)
+				if err != nil {
+					logger.Fatalf(ctx, err.Error())
+				}
+
+				for _, s := range status {
+					for _, m := range s.Pending {
+						fmt.Printf("%s %s: %s\n", s.Key, m.Version, m.Description)
+					}
+				}
+
+				return
+			}
+
+			if err := applyMigrations(ctx, cli.Database(os.Getenv("MONGO_DATABASE"))); err != nil {
+				logger.Fatalf(ctx, err.Error())
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only print pending migrations")
+
+	return cmd
+}
+
+func getMigrateRestoreCommand() *cobra.Command {
+	var collection string
+
+	cmd := &cobra.Command{
+		Use:   "restore <dump>",
+		Short: "Replace a collection with the content of a migration dump",
+		Long: "Replace all documents of a collection with the documents of a dump created before running a migration.\n" +
+			"If dump is a plain file name it's looked up in the dumps directory of the state directory.",
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			ctx := context.Background()
+
+			path := args[0]
+			if filepath.Base(path) == path {
+				path = filepath.Join(schema.DumpDirectory(), path)
+			}
+
+			cli, _ := getMigrationEnv(ctx)
+			defer disconnect(cli)
+
+			count, err := schema.RestoreDump(ctx, cli.Database(os.Getenv("MONGO_DATABASE")), collection, path)
+			if err != nil {
+				logger.Fatalf(ctx, err.Error())
+			}
+
+			fmt.Printf("restored %d documents of %s from %s\n", count, collection, path)
+		},
+	}
+
+	cmd.Flags().StringVarP(&collection, "collection", "c", "", "The collection to restore")
+	_ = cmd.MarkFlagRequired("collection")
+
+	return cmd
+}
+
+// addConfigMigrations adds the option migrations of all registered
+// configuration schemas to the default schema registry.
+func addConfigMigrations() {
+	schema.Add(mongoprovider.Migrations(configCollection, runtime.GlobalSchema.Schemas()...)...)
+}
+
+// applyMigrations applies all pending migrations of the default schema
+// registry.
+func applyMigrations(ctx context.Context, db *mongo.Database) error {
+	schemaDB, err := schema.NewDatabaseFromClient(ctx, db.Name(), db.Client())
+	if err != nil {
+		return err
+	}
+
+	if _, err := schema.ApplyMigrations(ctx, schemaDB, db); err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	return nil
+}
+
+// getMigrationEnv connects to the mongodb configured using MONGO_URL and
+// MONGO_DATABASE and adds all known migrations to the default schema
+// registry.
+func getMigrationEnv(ctx context.Context) (*mongo.Client, schema.Database) {
+	mongoURL := os.Getenv("MONGO_URL")
+	if mongoURL == "" {
+		logger.Fatalf(ctx, "MONGO_URL must be set to manage schema migrations")
+	}
+
+	cli := getMongoClient(ctx, mongoURL)
+
+	db, err := schema.NewDatabaseFromClient(ctx, os.Getenv("MONGO_DATABASE"), cli)
+	if err != nil {
+		logger.Fatalf(ctx, err.Error())
+	}
+
+	addConfigMigrations()
+
+	return cli, db
+}
+
+func disconnect(cli *mongo.Client) {
+	_ = cli.Disconnect(context.Background())
+}
@@ -0,0 +1,195 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/cis/v1/door.proto
+
+package cisv1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/cis/gen/go/tkd/cis/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// DoorServiceName is the fully-qualified name of the DoorService service.
+	DoorServiceName = "tkd.cis.v1.DoorService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// DoorServiceGetDoorStateProcedure is the fully-qualified name of the DoorService's GetDoorState
+	// RPC.
+	DoorServiceGetDoorStateProcedure = "/tkd.cis.v1.DoorService/GetDoorState"
+	// DoorServiceOverwriteProcedure is the fully-qualified name of the DoorService's Overwrite RPC.
+	DoorServiceOverwriteProcedure = "/tkd.cis.v1.DoorService/Overwrite"
+	// DoorServiceResetProcedure is the fully-qualified name of the DoorService's Reset RPC.
+	DoorServiceResetProcedure = "/tkd.cis.v1.DoorService/Reset"
+	// DoorServiceWatchStateProcedure is the fully-qualified name of the DoorService's WatchState RPC.
+	DoorServiceWatchStateProcedure = "/tkd.cis.v1.DoorService/WatchState"
+)
+
+// DoorServiceClient is a client for the tkd.cis.v1.DoorService service.
+type DoorServiceClient interface {
+	// GetDoorState returns the current or desired state of the entry door.
+	GetDoorState(context.Context, *connect_go.Request[v1.GetDoorStateRequest]) (*connect_go.Response[v1.GetDoorStateResponse], error)
+	// Overwrite forces the entry door into a given state for the
+	// specified duration.
+	Overwrite(context.Context, *connect_go.Request[v1.OverwriteRequest]) (*connect_go.Response[v1.OverwriteResponse], error)
+	// Reset resets the entry door and removes any manual overwrite.
+	Reset(context.Context, *connect_go.Request[v1.ResetRequest]) (*connect_go.Response[v1.ResetResponse], error)
+	// WatchState streams the state of the entry door. The current
+	// state is sent immediately followed by any state changes.
+	WatchState(context.Context, *connect_go.Request[v1.WatchStateRequest]) (*connect_go.ServerStreamForClient[v1.WatchStateResponse], error)
+}
+
+// NewDoorServiceClient constructs a client for the tkd.cis.v1.DoorService service. By default, it
+// uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewDoorServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) DoorServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &doorServiceClient{
+		getDoorState: connect_go.NewClient[v1.GetDoorStateRequest, v1.GetDoorStateResponse](
+			httpClient,
+			baseURL+DoorServiceGetDoorStateProcedure,
+			opts...,
+		),
+		overwrite: connect_go.NewClient[v1.OverwriteRequest, v1.OverwriteResponse](
+			httpClient,
+			baseURL+DoorServiceOverwriteProcedure,
+			opts...,
+		),
+		reset: connect_go.NewClient[v1.ResetRequest, v1.ResetResponse](
+			httpClient,
+			baseURL+DoorServiceResetProcedure,
+			opts...,
+		),
+		watchState: connect_go.NewClient[v1.WatchStateRequest, v1.WatchStateResponse](
+			httpClient,
+			baseURL+DoorServiceWatchStateProcedure,
+			opts...,
+		),
+	}
+}
+
+// doorServiceClient implements DoorServiceClient.
+type doorServiceClient struct {
+	getDoorState *connect_go.Client[v1.GetDoorStateRequest, v1.GetDoorStateResponse]
+	overwrite    *connect_go.Client[v1.OverwriteRequest, v1.OverwriteResponse]
+	reset        *connect_go.Client[v1.ResetRequest, v1.ResetResponse]
+	watchState   *connect_go.Client[v1.WatchStateRequest, v1.WatchStateResponse]
+}
+
+// GetDoorState calls tkd.cis.v1.DoorService.GetDoorState.
+func (c *doorServiceClient) GetDoorState(ctx context.Context, req *connect_go.Request[v1.GetDoorStateRequest]) (*connect_go.Response[v1.GetDoorStateResponse], error) {
+	return c.getDoorState.CallUnary(ctx, req)
+}
+
+// Overwrite calls tkd.cis.v1.DoorService.Overwrite.
+func (c *doorServiceClient) Overwrite(ctx context.Context, req *connect_go.Request[v1.OverwriteRequest]) (*connect_go.Response[v1.OverwriteResponse], error) {
+	return c.overwrite.CallUnary(ctx, req)
+}
+
+// Reset calls tkd.cis.v1.DoorService.Reset.
+func (c *doorServiceClient) Reset(ctx context.Context, req *connect_go.Request[v1.ResetRequest]) (*connect_go.Response[v1.ResetResponse], error) {
+	return c.reset.CallUnary(ctx, req)
+}
+
+// WatchState calls tkd.cis.v1.DoorService.WatchState.
+func (c *doorServiceClient) WatchState(ctx context.Context, req *connect_go.Request[v1.WatchStateRequest]) (*connect_go.ServerStreamForClient[v1.WatchStateResponse], error) {
+	return c.watchState.CallServerStream(ctx, req)
+}
+
+// DoorServiceHandler is an implementation of the tkd.cis.v1.DoorService service.
+type DoorServiceHandler interface {
+	// GetDoorState returns the current or desired state of the entry door.
+	GetDoorState(context.Context, *connect_go.Request[v1.GetDoorStateRequest]) (*connect_go.Response[v1.GetDoorStateResponse], error)
+	// Overwrite forces the entry door into a given state for the
+	// specified duration.
+	Overwrite(context.Context, *connect_go.Request[v1.OverwriteRequest]) (*connect_go.Response[v1.OverwriteResponse], error)
+	// Reset resets the entry door and removes any manual overwrite.
+	Reset(context.Context, *connect_go.Request[v1.ResetRequest]) (*connect_go.Response[v1.ResetResponse], error)
+	// WatchState streams the state of the entry door. The current
+	// state is sent immediately followed by any state changes.
+	WatchState(context.Context, *connect_go.Request[v1.WatchStateRequest], *connect_go.ServerStream[v1.WatchStateResponse]) error
+}
+
+// NewDoorServiceHandler builds an HTTP handler from the service implementation. It returns the path
+// on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewDoorServiceHandler(svc DoorServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	doorServiceGetDoorStateHandler := connect_go.NewUnaryHandler(
+		DoorServiceGetDoorStateProcedure,
+		svc.GetDoorState,
+		opts...,
+	)
+	doorServiceOverwriteHandler := connect_go.NewUnaryHandler(
+		DoorServiceOverwriteProcedure,
+		svc.Overwrite,
+		opts...,
+	)
+	doorServiceResetHandler := connect_go.NewUnaryHandler(
+		DoorServiceResetProcedure,
+		svc.Reset,
+		opts...,
+	)
+	doorServiceWatchStateHandler := connect_go.NewServerStreamHandler(
+		DoorServiceWatchStateProcedure,
+		svc.WatchState,
+		opts...,
+	)
+	return "/tkd.cis.v1.DoorService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case DoorServiceGetDoorStateProcedure:
+			doorServiceGetDoorStateHandler.ServeHTTP(w, r)
+		case DoorServiceOverwriteProcedure:
+			doorServiceOverwriteHandler.ServeHTTP(w, r)
+		case DoorServiceResetProcedure:
+			doorServiceResetHandler.ServeHTTP(w, r)
+		case DoorServiceWatchStateProcedure:
+			doorServiceWatchStateHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedDoorServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedDoorServiceHandler struct{}
+
+func (UnimplementedDoorServiceHandler) GetDoorState(context.Context, *connect_go.Request[v1.GetDoorStateRequest]) (*connect_go.Response[v1.GetDoorStateResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.cis.v1.DoorService.GetDoorState is not implemented"))
+}
+
+func (UnimplementedDoorServiceHandler) Overwrite(context.Context, *connect_go.Request[v1.OverwriteRequest]) (*connect_go.Response[v1.OverwriteResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.cis.v1.DoorService.Overwrite is not implemented"))
+}
+
+func (UnimplementedDoorServiceHandler) Reset(context.Context, *connect_go.Request[v1.ResetRequest]) (*connect_go.Response[v1.ResetResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.cis.v1.DoorService.Reset is not implemented"))
+}
+
+func (UnimplementedDoorServiceHandler) WatchState(context.Context, *connect_go.Request[v1.WatchStateRequest], *connect_go.ServerStream[v1.WatchStateResponse]) error {
+	return connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.cis.v1.DoorService.WatchState is not implemented"))
+}
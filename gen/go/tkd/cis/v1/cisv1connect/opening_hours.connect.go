@@ -0,0 +1,138 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/cis/v1/opening_hours.proto
+
+package cisv1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/cis/gen/go/tkd/cis/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// OpeningHoursServiceName is the fully-qualified name of the OpeningHoursService service.
+	OpeningHoursServiceName = "tkd.cis.v1.OpeningHoursService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// OpeningHoursServiceForDateProcedure is the fully-qualified name of the OpeningHoursService's
+	// ForDate RPC.
+	OpeningHoursServiceForDateProcedure = "/tkd.cis.v1.OpeningHoursService/ForDate"
+	// OpeningHoursServiceUpcomingFramesProcedure is the fully-qualified name of the
+	// OpeningHoursService's UpcomingFrames RPC.
+	OpeningHoursServiceUpcomingFramesProcedure = "/tkd.cis.v1.OpeningHoursService/UpcomingFrames"
+)
+
+// OpeningHoursServiceClient is a client for the tkd.cis.v1.OpeningHoursService service.
+type OpeningHoursServiceClient interface {
+	// ForDate returns the opening hours that apply at a given date.
+	ForDate(context.Context, *connect_go.Request[v1.ForDateRequest]) (*connect_go.Response[v1.ForDateResponse], error)
+	// UpcomingFrames returns the next time frames of a given kind,
+	// including a frame that is currently active.
+	UpcomingFrames(context.Context, *connect_go.Request[v1.UpcomingFramesRequest]) (*connect_go.Response[v1.UpcomingFramesResponse], error)
+}
+
+// NewOpeningHoursServiceClient constructs a client for the tkd.cis.v1.OpeningHoursService service.
+// By default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped
+// responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewOpeningHoursServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) OpeningHoursServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &openingHoursServiceClient{
+		forDate: connect_go.NewClient[v1.ForDateRequest, v1.ForDateResponse](
+			httpClient,
+			baseURL+OpeningHoursServiceForDateProcedure,
+			opts...,
+		),
+		upcomingFrames: connect_go.NewClient[v1.UpcomingFramesRequest, v1.UpcomingFramesResponse](
+			httpClient,
+			baseURL+OpeningHoursServiceUpcomingFramesProcedure,
+			opts...,
+		),
+	}
+}
+
+// openingHoursServiceClient implements OpeningHoursServiceClient.
+type openingHoursServiceClient struct {
+	forDate        *connect_go.Client[v1.ForDateRequest, v1.ForDateResponse]
+	upcomingFrames *connect_go.Client[v1.UpcomingFramesRequest, v1.UpcomingFramesResponse]
+}
+
+// ForDate calls tkd.cis.v1.OpeningHoursService.ForDate.
+func (c *openingHoursServiceClient) ForDate(ctx context.Context, req *connect_go.Request[v1.ForDateRequest]) (*connect_go.Response[v1.ForDateResponse], error) {
+	return c.forDate.CallUnary(ctx, req)
+}
+
+// UpcomingFrames calls tkd.cis.v1.OpeningHoursService.UpcomingFrames.
+func (c *openingHoursServiceClient) UpcomingFrames(ctx context.Context, req *connect_go.Request[v1.UpcomingFramesRequest]) (*connect_go.Response[v1.UpcomingFramesResponse], error) {
+	return c.upcomingFrames.CallUnary(ctx, req)
+}
+
+// OpeningHoursServiceHandler is an implementation of the tkd.cis.v1.OpeningHoursService service.
+type OpeningHoursServiceHandler interface {
+	// ForDate returns the opening hours that apply at a given date.
+	ForDate(context.Context, *connect_go.Request[v1.ForDateRequest]) (*connect_go.Response[v1.ForDateResponse], error)
+	// UpcomingFrames returns the next time frames of a given kind,
+	// including a frame that is currently active.
+	UpcomingFrames(context.Context, *connect_go.Request[v1.UpcomingFramesRequest]) (*connect_go.Response[v1.UpcomingFramesResponse], error)
+}
+
+// NewOpeningHoursServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewOpeningHoursServiceHandler(svc OpeningHoursServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	openingHoursServiceForDateHandler := connect_go.NewUnaryHandler(
+		OpeningHoursServiceForDateProcedure,
+		svc.ForDate,
+		opts...,
+	)
+	openingHoursServiceUpcomingFramesHandler := connect_go.NewUnaryHandler(
+		OpeningHoursServiceUpcomingFramesProcedure,
+		svc.UpcomingFrames,
+		opts...,
+	)
+	return "/tkd.cis.v1.OpeningHoursService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case OpeningHoursServiceForDateProcedure:
+			openingHoursServiceForDateHandler.ServeHTTP(w, r)
+		case OpeningHoursServiceUpcomingFramesProcedure:
+			openingHoursServiceUpcomingFramesHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedOpeningHoursServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedOpeningHoursServiceHandler struct{}
+
+func (UnimplementedOpeningHoursServiceHandler) ForDate(context.Context, *connect_go.Request[v1.ForDateRequest]) (*connect_go.Response[v1.ForDateResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.cis.v1.OpeningHoursService.ForDate is not implemented"))
+}
+
+func (UnimplementedOpeningHoursServiceHandler) UpcomingFrames(context.Context, *connect_go.Request[v1.UpcomingFramesRequest]) (*connect_go.Response[v1.UpcomingFramesResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.cis.v1.OpeningHoursService.UpcomingFrames is not implemented"))
+}
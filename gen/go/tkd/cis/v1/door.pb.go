@@ -0,0 +1,646 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.1
+// 	protoc        (unknown)
+// source: tkd/cis/v1/door.proto
+
+package cisv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// DoorState describes the state of the entry door.
+type DoorState int32
+
+const (
+	DoorState_DOOR_STATE_UNSPECIFIED DoorState = 0
+	DoorState_DOOR_STATE_LOCKED      DoorState = 1
+	DoorState_DOOR_STATE_UNLOCKED    DoorState = 2
+)
+
+// Enum value maps for DoorState.
+var (
+	DoorState_name = map[int32]string{
+		0: "DOOR_STATE_UNSPECIFIED",
+		1: "DOOR_STATE_LOCKED",
+		2: "DOOR_STATE_UNLOCKED",
+	}
+	DoorState_value = map[string]int32{
+		"DOOR_STATE_UNSPECIFIED": 0,
+		"DOOR_STATE_LOCKED":      1,
+		"DOOR_STATE_UNLOCKED":    2,
+	}
+)
+
+func (x DoorState) Enum() *DoorState {
+	p := new(DoorState)
+	*p = x
+	return p
+}
+
+func (x DoorState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DoorState) Descriptor() protoreflect.EnumDescriptor {
+	return file_tkd_cis_v1_door_proto_enumTypes[0].Descriptor()
+}
+
+func (DoorState) Type() protoreflect.EnumType {
+	return &file_tkd_cis_v1_door_proto_enumTypes[0]
+}
+
+func (x DoorState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DoorState.Descriptor instead.
+func (DoorState) EnumDescriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_door_proto_rawDescGZIP(), []int{0}
+}
+
+// DoorStatus describes the state of the entry door and until when
+// it is expected to stay in that state.
+type DoorStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	State DoorState              `protobuf:"varint,1,opt,name=state,proto3,enum=tkd.cis.v1.DoorState" json:"state,omitempty"`
+	// Until is unset if there is no upcoming state change.
+	Until           *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=until,proto3" json:"until,omitempty"`
+	ResetInProgress bool                   `protobuf:"varint,3,opt,name=reset_in_progress,json=resetInProgress,proto3" json:"reset_in_progress,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DoorStatus) Reset() {
+	*x = DoorStatus{}
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DoorStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DoorStatus) ProtoMessage() {}
+
+func (x *DoorStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DoorStatus.ProtoReflect.Descriptor instead.
+func (*DoorStatus) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_door_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DoorStatus) GetState() DoorState {
+	if x != nil {
+		return x.State
+	}
+	return DoorState_DOOR_STATE_UNSPECIFIED
+}
+
+func (x *DoorStatus) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *DoorStatus) GetResetInProgress() bool {
+	if x != nil {
+		return x.ResetInProgress
+	}
+	return false
+}
+
+type GetDoorStateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// At may be set to query the desired door state at a specific
+	// point in time. If unset, the current state is returned.
+	At            *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDoorStateRequest) Reset() {
+	*x = GetDoorStateRequest{}
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDoorStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDoorStateRequest) ProtoMessage() {}
+
+func (x *GetDoorStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDoorStateRequest.ProtoReflect.Descriptor instead.
+func (*GetDoorStateRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_door_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetDoorStateRequest) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+type GetDoorStateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *DoorStatus            `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDoorStateResponse) Reset() {
+	*x = GetDoorStateResponse{}
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDoorStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDoorStateResponse) ProtoMessage() {}
+
+func (x *GetDoorStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDoorStateResponse.ProtoReflect.Descriptor instead.
+func (*GetDoorStateResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_door_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetDoorStateResponse) GetStatus() *DoorStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type OverwriteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// State is the state the door should be forced into.
+	State DoorState `protobuf:"varint,1,opt,name=state,proto3,enum=tkd.cis.v1.DoorState" json:"state,omitempty"`
+	// Duration is the duration of the overwrite.
+	Duration      *durationpb.Duration `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OverwriteRequest) Reset() {
+	*x = OverwriteRequest{}
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OverwriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OverwriteRequest) ProtoMessage() {}
+
+func (x *OverwriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OverwriteRequest.ProtoReflect.Descriptor instead.
+func (*OverwriteRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_door_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OverwriteRequest) GetState() DoorState {
+	if x != nil {
+		return x.State
+	}
+	return DoorState_DOOR_STATE_UNSPECIFIED
+}
+
+func (x *OverwriteRequest) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+type OverwriteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *DoorStatus            `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OverwriteResponse) Reset() {
+	*x = OverwriteResponse{}
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OverwriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OverwriteResponse) ProtoMessage() {}
+
+func (x *OverwriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OverwriteResponse.ProtoReflect.Descriptor instead.
+func (*OverwriteResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_door_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OverwriteResponse) GetStatus() *DoorStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type ResetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetRequest) Reset() {
+	*x = ResetRequest{}
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetRequest) ProtoMessage() {}
+
+func (x *ResetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetRequest.ProtoReflect.Descriptor instead.
+func (*ResetRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_door_proto_rawDescGZIP(), []int{5}
+}
+
+type ResetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *DoorStatus            `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetResponse) Reset() {
+	*x = ResetResponse{}
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetResponse) ProtoMessage() {}
+
+func (x *ResetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetResponse.ProtoReflect.Descriptor instead.
+func (*ResetResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_door_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ResetResponse) GetStatus() *DoorStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type WatchStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchStateRequest) Reset() {
+	*x = WatchStateRequest{}
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchStateRequest) ProtoMessage() {}
+
+func (x *WatchStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchStateRequest.ProtoReflect.Descriptor instead.
+func (*WatchStateRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_door_proto_rawDescGZIP(), []int{7}
+}
+
+type WatchStateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *DoorStatus            `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchStateResponse) Reset() {
+	*x = WatchStateResponse{}
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchStateResponse) ProtoMessage() {}
+
+func (x *WatchStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_door_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchStateResponse.ProtoReflect.Descriptor instead.
+func (*WatchStateResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_door_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchStateResponse) GetStatus() *DoorStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+var File_tkd_cis_v1_door_proto protoreflect.FileDescriptor
+
+var file_tkd_cis_v1_door_proto_rawDesc = []byte{
+	0x0a, 0x15, 0x74, 0x6b, 0x64, 0x2f, 0x63, 0x69, 0x73, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x6f, 0x6f,
+	0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73,
+	0x2e, 0x76, 0x31, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0x97, 0x01, 0x0a, 0x0a, 0x44, 0x6f, 0x6f, 0x72, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x2b, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x15, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x44, 0x6f, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x12, 0x30, 0x0a, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x75, 0x6e, 0x74,
+	0x69, 0x6c, 0x12, 0x2a, 0x0a, 0x11, 0x72, 0x65, 0x73, 0x65, 0x74, 0x5f, 0x69, 0x6e, 0x5f, 0x70,
+	0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x72,
+	0x65, 0x73, 0x65, 0x74, 0x49, 0x6e, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x22, 0x41,
+	0x0a, 0x13, 0x47, 0x65, 0x74, 0x44, 0x6f, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x02, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x61,
+	0x74, 0x22, 0x46, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x44, 0x6f, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x74, 0x6b, 0x64, 0x2e,
+	0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x6f, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x76, 0x0a, 0x10, 0x4f, 0x76, 0x65,
+	0x72, 0x77, 0x72, 0x69, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2b, 0x0a,
+	0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x74,
+	0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x6f, 0x6f, 0x72, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x35, 0x0a, 0x08, 0x64, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x22, 0x43, 0x0a, 0x11, 0x4f, 0x76, 0x65, 0x72, 0x77, 0x72, 0x69, 0x74, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x44, 0x6f, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x0e, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x65, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x3f, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x65, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x6f, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x13, 0x0a, 0x11, 0x57, 0x61, 0x74, 0x63, 0x68,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x44, 0x0a, 0x12,
+	0x57, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x2e, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x44, 0x6f, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x2a, 0x57, 0x0a, 0x09, 0x44, 0x6f, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x1a, 0x0a, 0x16, 0x44, 0x4f, 0x4f, 0x52, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x55, 0x4e,
+	0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x44,
+	0x4f, 0x4f, 0x52, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x4c, 0x4f, 0x43, 0x4b, 0x45, 0x44,
+	0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x44, 0x4f, 0x4f, 0x52, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45,
+	0x5f, 0x55, 0x4e, 0x4c, 0x4f, 0x43, 0x4b, 0x45, 0x44, 0x10, 0x02, 0x32, 0xbf, 0x02, 0x0a, 0x0b,
+	0x44, 0x6f, 0x6f, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x53, 0x0a, 0x0c, 0x47,
+	0x65, 0x74, 0x44, 0x6f, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1f, 0x2e, 0x74, 0x6b,
+	0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x6f, 0x6f, 0x72,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x74,
+	0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x6f, 0x6f,
+	0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
+	0x12, 0x4a, 0x0a, 0x09, 0x4f, 0x76, 0x65, 0x72, 0x77, 0x72, 0x69, 0x74, 0x65, 0x12, 0x1c, 0x2e,
+	0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x76, 0x65, 0x72, 0x77,
+	0x72, 0x69, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x74, 0x6b,
+	0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x76, 0x65, 0x72, 0x77, 0x72, 0x69,
+	0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x3e, 0x0a, 0x05,
+	0x52, 0x65, 0x73, 0x65, 0x74, 0x12, 0x18, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x19, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73,
+	0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x4f, 0x0a, 0x0a,
+	0x57, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1d, 0x2e, 0x74, 0x6b, 0x64,
+	0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x74, 0x6b, 0x64, 0x2e,
+	0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x42, 0x3e, 0x5a,
+	0x3c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x69, 0x65, 0x72,
+	0x6b, 0x6c, 0x69, 0x6e, 0x69, 0x6b, 0x2d, 0x64, 0x6f, 0x62, 0x65, 0x72, 0x73, 0x62, 0x65, 0x72,
+	0x67, 0x2f, 0x63, 0x69, 0x73, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x67, 0x6f, 0x2f, 0x74, 0x6b, 0x64,
+	0x2f, 0x63, 0x69, 0x73, 0x2f, 0x76, 0x31, 0x3b, 0x63, 0x69, 0x73, 0x76, 0x31, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_tkd_cis_v1_door_proto_rawDescOnce sync.Once
+	file_tkd_cis_v1_door_proto_rawDescData = file_tkd_cis_v1_door_proto_rawDesc
+)
+
+func file_tkd_cis_v1_door_proto_rawDescGZIP() []byte {
+	file_tkd_cis_v1_door_proto_rawDescOnce.Do(func() {
+		file_tkd_cis_v1_door_proto_rawDescData = protoimpl.X.CompressGZIP(file_tkd_cis_v1_door_proto_rawDescData)
+	})
+	return file_tkd_cis_v1_door_proto_rawDescData
+}
+
+var file_tkd_cis_v1_door_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_tkd_cis_v1_door_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_tkd_cis_v1_door_proto_goTypes = []any{
+	(DoorState)(0),                // 0: tkd.cis.v1.DoorState
+	(*DoorStatus)(nil),            // 1: tkd.cis.v1.DoorStatus
+	(*GetDoorStateRequest)(nil),   // 2: tkd.cis.v1.GetDoorStateRequest
+	(*GetDoorStateResponse)(nil),  // 3: tkd.cis.v1.GetDoorStateResponse
+	(*OverwriteRequest)(nil),      // 4: tkd.cis.v1.OverwriteRequest
+	(*OverwriteResponse)(nil),     // 5: tkd.cis.v1.OverwriteResponse
+	(*ResetRequest)(nil),          // 6: tkd.cis.v1.ResetRequest
+	(*ResetResponse)(nil),         // 7: tkd.cis.v1.ResetResponse
+	(*WatchStateRequest)(nil),     // 8: tkd.cis.v1.WatchStateRequest
+	(*WatchStateResponse)(nil),    // 9: tkd.cis.v1.WatchStateResponse
+	(*timestamppb.Timestamp)(nil), // 10: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 11: google.protobuf.Duration
+}
+var file_tkd_cis_v1_door_proto_depIdxs = []int32{
+	0,  // 0: tkd.cis.v1.DoorStatus.state:type_name -> tkd.cis.v1.DoorState
+	10, // 1: tkd.cis.v1.DoorStatus.until:type_name -> google.protobuf.Timestamp
+	10, // 2: tkd.cis.v1.GetDoorStateRequest.at:type_name -> google.protobuf.Timestamp
+	1,  // 3: tkd.cis.v1.GetDoorStateResponse.status:type_name -> tkd.cis.v1.DoorStatus
+	0,  // 4: tkd.cis.v1.OverwriteRequest.state:type_name -> tkd.cis.v1.DoorState
+	11, // 5: tkd.cis.v1.OverwriteRequest.duration:type_name -> google.protobuf.Duration
+	1,  // 6: tkd.cis.v1.OverwriteResponse.status:type_name -> tkd.cis.v1.DoorStatus
+	1,  // 7: tkd.cis.v1.ResetResponse.status:type_name -> tkd.cis.v1.DoorStatus
+	1,  // 8: tkd.cis.v1.WatchStateResponse.status:type_name -> tkd.cis.v1.DoorStatus
+	2,  // 9: tkd.cis.v1.DoorService.GetDoorState:input_type -> tkd.cis.v1.GetDoorStateRequest
+	4,  // 10: tkd.cis.v1.DoorService.Overwrite:input_type -> tkd.cis.v1.OverwriteRequest
+	6,  // 11: tkd.cis.v1.DoorService.Reset:input_type -> tkd.cis.v1.ResetRequest
+	8,  // 12: tkd.cis.v1.DoorService.WatchState:input_type -> tkd.cis.v1.WatchStateRequest
+	3,  // 13: tkd.cis.v1.DoorService.GetDoorState:output_type -> tkd.cis.v1.GetDoorStateResponse
+	5,  // 14: tkd.cis.v1.DoorService.Overwrite:output_type -> tkd.cis.v1.OverwriteResponse
+	7,  // 15: tkd.cis.v1.DoorService.Reset:output_type -> tkd.cis.v1.ResetResponse
+	9,  // 16: tkd.cis.v1.DoorService.WatchState:output_type -> tkd.cis.v1.WatchStateResponse
+	13, // [13:17] is the sub-list for method output_type
+	9,  // [9:13] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_tkd_cis_v1_door_proto_init() }
+func file_tkd_cis_v1_door_proto_init() {
+	if File_tkd_cis_v1_door_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_tkd_cis_v1_door_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tkd_cis_v1_door_proto_goTypes,
+		DependencyIndexes: file_tkd_cis_v1_door_proto_depIdxs,
+		EnumInfos:         file_tkd_cis_v1_door_proto_enumTypes,
+		MessageInfos:      file_tkd_cis_v1_door_proto_msgTypes,
+	}.Build()
+	File_tkd_cis_v1_door_proto = out.File
+	file_tkd_cis_v1_door_proto_rawDesc = nil
+	file_tkd_cis_v1_door_proto_goTypes = nil
+	file_tkd_cis_v1_door_proto_depIdxs = nil
+}
@@ -0,0 +1,910 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.1
+// 	protoc        (unknown)
+// source: tkd/cis/v1/opening_hours.proto
+
+package cisv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// FrameKind describes what a time frame is used for.
+type FrameKind int32
+
+const (
+	// FRAME_KIND_UNSPECIFIED is treated as FRAME_KIND_BUSINESS.
+	FrameKind_FRAME_KIND_UNSPECIFIED FrameKind = 0
+	// FRAME_KIND_BUSINESS marks the time frames during which we are
+	// open for business.
+	FrameKind_FRAME_KIND_BUSINESS FrameKind = 1
+	// FRAME_KIND_DOOR marks the time frames during which the entry
+	// door is unlocked. This includes padded business hours.
+	FrameKind_FRAME_KIND_DOOR FrameKind = 2
+	// FRAME_KIND_PHONE marks the time frames during which the
+	// phone/reception is staffed.
+	FrameKind_FRAME_KIND_PHONE FrameKind = 3
+)
+
+// Enum value maps for FrameKind.
+var (
+	FrameKind_name = map[int32]string{
+		0: "FRAME_KIND_UNSPECIFIED",
+		1: "FRAME_KIND_BUSINESS",
+		2: "FRAME_KIND_DOOR",
+		3: "FRAME_KIND_PHONE",
+	}
+	FrameKind_value = map[string]int32{
+		"FRAME_KIND_UNSPECIFIED": 0,
+		"FRAME_KIND_BUSINESS":    1,
+		"FRAME_KIND_DOOR":        2,
+		"FRAME_KIND_PHONE":       3,
+	}
+)
+
+func (x FrameKind) Enum() *FrameKind {
+	p := new(FrameKind)
+	*p = x
+	return p
+}
+
+func (x FrameKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FrameKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_tkd_cis_v1_opening_hours_proto_enumTypes[0].Descriptor()
+}
+
+func (FrameKind) Type() protoreflect.EnumType {
+	return &file_tkd_cis_v1_opening_hours_proto_enumTypes[0]
+}
+
+func (x FrameKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FrameKind.Descriptor instead.
+func (FrameKind) EnumDescriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_opening_hours_proto_rawDescGZIP(), []int{0}
+}
+
+// DayTime is a time within an unspecified day.
+type DayTime struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hour          int32                  `protobuf:"varint,1,opt,name=hour,proto3" json:"hour,omitempty"`
+	Minute        int32                  `protobuf:"varint,2,opt,name=minute,proto3" json:"minute,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DayTime) Reset() {
+	*x = DayTime{}
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DayTime) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DayTime) ProtoMessage() {}
+
+func (x *DayTime) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DayTime.ProtoReflect.Descriptor instead.
+func (*DayTime) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_opening_hours_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DayTime) GetHour() int32 {
+	if x != nil {
+		return x.Hour
+	}
+	return 0
+}
+
+func (x *DayTime) GetMinute() int32 {
+	if x != nil {
+		return x.Minute
+	}
+	return 0
+}
+
+// DayTimeRange describes a time range within an unspecified day.
+type DayTimeRange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Start is the inclusive start of the range.
+	Start *DayTime `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	// End is the exclusive end of the range.
+	End           *DayTime `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DayTimeRange) Reset() {
+	*x = DayTimeRange{}
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DayTimeRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DayTimeRange) ProtoMessage() {}
+
+func (x *DayTimeRange) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DayTimeRange.ProtoReflect.Descriptor instead.
+func (*DayTimeRange) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_opening_hours_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DayTimeRange) GetStart() *DayTime {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *DayTimeRange) GetEnd() *DayTime {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+// TimeRange describes a time range at a specific date.
+type TimeRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimeRange) Reset() {
+	*x = TimeRange{}
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimeRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeRange) ProtoMessage() {}
+
+func (x *TimeRange) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeRange.ProtoReflect.Descriptor instead.
+func (*TimeRange) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_opening_hours_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TimeRange) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *TimeRange) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+// OpeningHour is a single configured opening hour of a given kind.
+type OpeningHour struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID is the ID of the configuration instance that defined the
+	// opening hour.
+	Id    string        `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind  FrameKind     `protobuf:"varint,2,opt,name=kind,proto3,enum=tkd.cis.v1.FrameKind" json:"kind,omitempty"`
+	Range *DayTimeRange `protobuf:"bytes,3,opt,name=range,proto3" json:"range,omitempty"`
+	// Holiday is set to true if the opening hour is only valid on
+	// public holidays.
+	Holiday bool `protobuf:"varint,4,opt,name=holiday,proto3" json:"holiday,omitempty"`
+	// OpenBefore and CloseAfter define the padding of the entry door
+	// and are only set for business hours.
+	OpenBefore    *durationpb.Duration `protobuf:"bytes,5,opt,name=open_before,json=openBefore,proto3" json:"open_before,omitempty"`
+	CloseAfter    *durationpb.Duration `protobuf:"bytes,6,opt,name=close_after,json=closeAfter,proto3" json:"close_after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OpeningHour) Reset() {
+	*x = OpeningHour{}
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpeningHour) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpeningHour) ProtoMessage() {}
+
+func (x *OpeningHour) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpeningHour.ProtoReflect.Descriptor instead.
+func (*OpeningHour) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_opening_hours_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OpeningHour) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *OpeningHour) GetKind() FrameKind {
+	if x != nil {
+		return x.Kind
+	}
+	return FrameKind_FRAME_KIND_UNSPECIFIED
+}
+
+func (x *OpeningHour) GetRange() *DayTimeRange {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+func (x *OpeningHour) GetHoliday() bool {
+	if x != nil {
+		return x.Holiday
+	}
+	return false
+}
+
+func (x *OpeningHour) GetOpenBefore() *durationpb.Duration {
+	if x != nil {
+		return x.OpenBefore
+	}
+	return nil
+}
+
+func (x *OpeningHour) GetCloseAfter() *durationpb.Duration {
+	if x != nil {
+		return x.CloseAfter
+	}
+	return nil
+}
+
+// Closure describes a time range during which we are closed even if
+// there are opening hours configured.
+type Closure struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Reason string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	// AllDay is set if the closure spans the whole day. Range is
+	// unset in this case.
+	AllDay        bool          `protobuf:"varint,3,opt,name=all_day,json=allDay,proto3" json:"all_day,omitempty"`
+	Range         *DayTimeRange `protobuf:"bytes,4,opt,name=range,proto3" json:"range,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Closure) Reset() {
+	*x = Closure{}
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Closure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Closure) ProtoMessage() {}
+
+func (x *Closure) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Closure.ProtoReflect.Descriptor instead.
+func (*Closure) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_opening_hours_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Closure) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Closure) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Closure) GetAllDay() bool {
+	if x != nil {
+		return x.AllDay
+	}
+	return false
+}
+
+func (x *Closure) GetRange() *DayTimeRange {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+// Holiday describes a holiday of a holiday calendar.
+type Holiday struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Calendar is the name of the holiday calendar. Public holidays
+	// use the calendar "public".
+	Calendar string `protobuf:"bytes,1,opt,name=calendar,proto3" json:"calendar,omitempty"`
+	// Name is the name of the holiday, if any.
+	Name          string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Holiday) Reset() {
+	*x = Holiday{}
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Holiday) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Holiday) ProtoMessage() {}
+
+func (x *Holiday) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Holiday.ProtoReflect.Descriptor instead.
+func (*Holiday) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_opening_hours_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Holiday) GetCalendar() string {
+	if x != nil {
+		return x.Calendar
+	}
+	return ""
+}
+
+func (x *Holiday) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ForDateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Date is any point in time within the requested day. If
+	// unset, the current day is used.
+	Date          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Kind          FrameKind              `protobuf:"varint,2,opt,name=kind,proto3,enum=tkd.cis.v1.FrameKind" json:"kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForDateRequest) Reset() {
+	*x = ForDateRequest{}
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForDateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForDateRequest) ProtoMessage() {}
+
+func (x *ForDateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForDateRequest.ProtoReflect.Descriptor instead.
+func (*ForDateRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_opening_hours_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ForDateRequest) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *ForDateRequest) GetKind() FrameKind {
+	if x != nil {
+		return x.Kind
+	}
+	return FrameKind_FRAME_KIND_UNSPECIFIED
+}
+
+type ForDateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// OpeningHours holds the opening hours of the requested kind that
+	// apply at the requested date. Closures have already been applied.
+	OpeningHours []*OpeningHour `protobuf:"bytes,1,rep,name=opening_hours,json=openingHours,proto3" json:"opening_hours,omitempty"`
+	// Frames holds the resulting time frames at the requested date.
+	Frames []*TimeRange `protobuf:"bytes,2,rep,name=frames,proto3" json:"frames,omitempty"`
+	// Closures holds all closures that apply at the requested date.
+	Closures []*Closure `protobuf:"bytes,3,rep,name=closures,proto3" json:"closures,omitempty"`
+	// Holidays holds the holidays of all holiday calendars at the
+	// requested date.
+	Holidays []*Holiday `protobuf:"bytes,4,rep,name=holidays,proto3" json:"holidays,omitempty"`
+	// Calendar is the name of the holiday calendar that caused the
+	// opening hours to be selected. It is empty if regular or date
+	// specific opening hours are used.
+	Calendar      string `protobuf:"bytes,5,opt,name=calendar,proto3" json:"calendar,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForDateResponse) Reset() {
+	*x = ForDateResponse{}
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForDateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForDateResponse) ProtoMessage() {}
+
+func (x *ForDateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForDateResponse.ProtoReflect.Descriptor instead.
+func (*ForDateResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_opening_hours_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ForDateResponse) GetOpeningHours() []*OpeningHour {
+	if x != nil {
+		return x.OpeningHours
+	}
+	return nil
+}
+
+func (x *ForDateResponse) GetFrames() []*TimeRange {
+	if x != nil {
+		return x.Frames
+	}
+	return nil
+}
+
+func (x *ForDateResponse) GetClosures() []*Closure {
+	if x != nil {
+		return x.Closures
+	}
+	return nil
+}
+
+func (x *ForDateResponse) GetHolidays() []*Holiday {
+	if x != nil {
+		return x.Holidays
+	}
+	return nil
+}
+
+func (x *ForDateResponse) GetCalendar() string {
+	if x != nil {
+		return x.Calendar
+	}
+	return ""
+}
+
+type UpcomingFramesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// From is the time from which upcoming frames should be searched.
+	// If unset, the current time is used.
+	From *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	Kind FrameKind              `protobuf:"varint,2,opt,name=kind,proto3,enum=tkd.cis.v1.FrameKind" json:"kind,omitempty"`
+	// Limit is the maximum number of frames to return. Defaults to 1.
+	Limit         int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpcomingFramesRequest) Reset() {
+	*x = UpcomingFramesRequest{}
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpcomingFramesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpcomingFramesRequest) ProtoMessage() {}
+
+func (x *UpcomingFramesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpcomingFramesRequest.ProtoReflect.Descriptor instead.
+func (*UpcomingFramesRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_opening_hours_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpcomingFramesRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *UpcomingFramesRequest) GetKind() FrameKind {
+	if x != nil {
+		return x.Kind
+	}
+	return FrameKind_FRAME_KIND_UNSPECIFIED
+}
+
+func (x *UpcomingFramesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type UpcomingFramesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Frames        []*TimeRange           `protobuf:"bytes,1,rep,name=frames,proto3" json:"frames,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpcomingFramesResponse) Reset() {
+	*x = UpcomingFramesResponse{}
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpcomingFramesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpcomingFramesResponse) ProtoMessage() {}
+
+func (x *UpcomingFramesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_cis_v1_opening_hours_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpcomingFramesResponse.ProtoReflect.Descriptor instead.
+func (*UpcomingFramesResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_cis_v1_opening_hours_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpcomingFramesResponse) GetFrames() []*TimeRange {
+	if x != nil {
+		return x.Frames
+	}
+	return nil
+}
+
+var File_tkd_cis_v1_opening_hours_proto protoreflect.FileDescriptor
+
+var file_tkd_cis_v1_opening_hours_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x74, 0x6b, 0x64, 0x2f, 0x63, 0x69, 0x73, 0x2f, 0x76, 0x31, 0x2f, 0x6f, 0x70, 0x65,
+	0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x0a, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x1a, 0x1e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x35, 0x0a,
+	0x07, 0x44, 0x61, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x75, 0x72,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x68, 0x6f, 0x75, 0x72, 0x12, 0x16, 0x0a, 0x06,
+	0x6d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6d, 0x69,
+	0x6e, 0x75, 0x74, 0x65, 0x22, 0x60, 0x0a, 0x0c, 0x44, 0x61, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x52,
+	0x61, 0x6e, 0x67, 0x65, 0x12, 0x29, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x61, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12,
+	0x25, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x74,
+	0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x61, 0x79, 0x54, 0x69, 0x6d,
+	0x65, 0x52, 0x03, 0x65, 0x6e, 0x64, 0x22, 0x67, 0x0a, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x61,
+	0x6e, 0x67, 0x65, 0x12, 0x2e, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x66,
+	0x72, 0x6f, 0x6d, 0x12, 0x2a, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x6f, 0x22,
+	0x8a, 0x02, 0x0a, 0x0b, 0x4f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x48, 0x6f, 0x75, 0x72, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12,
+	0x29, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e,
+	0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x72, 0x61, 0x6d, 0x65,
+	0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x2e, 0x0a, 0x05, 0x72, 0x61,
+	0x6e, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x74, 0x6b, 0x64, 0x2e,
+	0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x61, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x61,
+	0x6e, 0x67, 0x65, 0x52, 0x05, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x68, 0x6f,
+	0x6c, 0x69, 0x64, 0x61, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x68, 0x6f, 0x6c,
+	0x69, 0x64, 0x61, 0x79, 0x12, 0x3a, 0x0a, 0x0b, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x62, 0x65, 0x66,
+	0x6f, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x6f, 0x70, 0x65, 0x6e, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65,
+	0x12, 0x3a, 0x0a, 0x0b, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x0a, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x41, 0x66, 0x74, 0x65, 0x72, 0x22, 0x7a, 0x0a, 0x07,
+	0x43, 0x6c, 0x6f, 0x73, 0x75, 0x72, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12,
+	0x17, 0x0a, 0x07, 0x61, 0x6c, 0x6c, 0x5f, 0x64, 0x61, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x61, 0x6c, 0x6c, 0x44, 0x61, 0x79, 0x12, 0x2e, 0x0a, 0x05, 0x72, 0x61, 0x6e, 0x67,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x61, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x61, 0x6e, 0x67,
+	0x65, 0x52, 0x05, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x22, 0x39, 0x0a, 0x07, 0x48, 0x6f, 0x6c, 0x69,
+	0x64, 0x61, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x22, 0x6b, 0x0a, 0x0e, 0x46, 0x6f, 0x72, 0x44, 0x61, 0x74, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x29, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64,
+	0x22, 0xfc, 0x01, 0x0a, 0x0f, 0x46, 0x6f, 0x72, 0x44, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3c, 0x0a, 0x0d, 0x6f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x5f,
+	0x68, 0x6f, 0x75, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x74, 0x6b,
+	0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67,
+	0x48, 0x6f, 0x75, 0x72, 0x52, 0x0c, 0x6f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x48, 0x6f, 0x75,
+	0x72, 0x73, 0x12, 0x2d, 0x0a, 0x06, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x06, 0x66, 0x72, 0x61, 0x6d, 0x65,
+	0x73, 0x12, 0x2f, 0x0a, 0x08, 0x63, 0x6c, 0x6f, 0x73, 0x75, 0x72, 0x65, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x6c, 0x6f, 0x73, 0x75, 0x72, 0x65, 0x52, 0x08, 0x63, 0x6c, 0x6f, 0x73, 0x75, 0x72,
+	0x65, 0x73, 0x12, 0x2f, 0x0a, 0x08, 0x68, 0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x48, 0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79, 0x52, 0x08, 0x68, 0x6f, 0x6c, 0x69, 0x64,
+	0x61, 0x79, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x22,
+	0x88, 0x01, 0x0a, 0x15, 0x55, 0x70, 0x63, 0x6f, 0x6d, 0x69, 0x6e, 0x67, 0x46, 0x72, 0x61, 0x6d,
+	0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x04, 0x66, 0x72, 0x6f,
+	0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x29, 0x0a, 0x04, 0x6b, 0x69, 0x6e,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04,
+	0x6b, 0x69, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x47, 0x0a, 0x16, 0x55, 0x70,
+	0x63, 0x6f, 0x6d, 0x69, 0x6e, 0x67, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x06, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x06, 0x66, 0x72, 0x61,
+	0x6d, 0x65, 0x73, 0x2a, 0x6b, 0x0a, 0x09, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x4b, 0x69, 0x6e, 0x64,
+	0x12, 0x1a, 0x0a, 0x16, 0x46, 0x52, 0x41, 0x4d, 0x45, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x55,
+	0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x17, 0x0a, 0x13,
+	0x46, 0x52, 0x41, 0x4d, 0x45, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x42, 0x55, 0x53, 0x49, 0x4e,
+	0x45, 0x53, 0x53, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x46, 0x52, 0x41, 0x4d, 0x45, 0x5f, 0x4b,
+	0x49, 0x4e, 0x44, 0x5f, 0x44, 0x4f, 0x4f, 0x52, 0x10, 0x02, 0x12, 0x14, 0x0a, 0x10, 0x46, 0x52,
+	0x41, 0x4d, 0x45, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x50, 0x48, 0x4f, 0x4e, 0x45, 0x10, 0x03,
+	0x32, 0xb6, 0x01, 0x0a, 0x13, 0x4f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x48, 0x6f, 0x75, 0x72,
+	0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x44, 0x0a, 0x07, 0x46, 0x6f, 0x72, 0x44,
+	0x61, 0x74, 0x65, 0x12, 0x1a, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x46, 0x6f, 0x72, 0x44, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1b, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x72,
+	0x44, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x59,
+	0x0a, 0x0e, 0x55, 0x70, 0x63, 0x6f, 0x6d, 0x69, 0x6e, 0x67, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x73,
+	0x12, 0x21, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70,
+	0x63, 0x6f, 0x6d, 0x69, 0x6e, 0x67, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x63, 0x69, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x55, 0x70, 0x63, 0x6f, 0x6d, 0x69, 0x6e, 0x67, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x3e, 0x5a, 0x3c, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x69, 0x65, 0x72, 0x6b, 0x6c, 0x69, 0x6e,
+	0x69, 0x6b, 0x2d, 0x64, 0x6f, 0x62, 0x65, 0x72, 0x73, 0x62, 0x65, 0x72, 0x67, 0x2f, 0x63, 0x69,
+	0x73, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x67, 0x6f, 0x2f, 0x74, 0x6b, 0x64, 0x2f, 0x63, 0x69, 0x73,
+	0x2f, 0x76, 0x31, 0x3b, 0x63, 0x69, 0x73, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_tkd_cis_v1_opening_hours_proto_rawDescOnce sync.Once
+	file_tkd_cis_v1_opening_hours_proto_rawDescData = file_tkd_cis_v1_opening_hours_proto_rawDesc
+)
+
+func file_tkd_cis_v1_opening_hours_proto_rawDescGZIP() []byte {
+	file_tkd_cis_v1_opening_hours_proto_rawDescOnce.Do(func() {
+		file_tkd_cis_v1_opening_hours_proto_rawDescData = protoimpl.X.CompressGZIP(file_tkd_cis_v1_opening_hours_proto_rawDescData)
+	})
+	return file_tkd_cis_v1_opening_hours_proto_rawDescData
+}
+
+var file_tkd_cis_v1_opening_hours_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_tkd_cis_v1_opening_hours_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_tkd_cis_v1_opening_hours_proto_goTypes = []any{
+	(FrameKind)(0),                 // 0: tkd.cis.v1.FrameKind
+	(*DayTime)(nil),                // 1: tkd.cis.v1.DayTime
+	(*DayTimeRange)(nil),           // 2: tkd.cis.v1.DayTimeRange
+	(*TimeRange)(nil),              // 3: tkd.cis.v1.TimeRange
+	(*OpeningHour)(nil),            // 4: tkd.cis.v1.OpeningHour
+	(*Closure)(nil),                // 5: tkd.cis.v1.Closure
+	(*Holiday)(nil),                // 6: tkd.cis.v1.Holiday
+	(*ForDateRequest)(nil),         // 7: tkd.cis.v1.ForDateRequest
+	(*ForDateResponse)(nil),        // 8: tkd.cis.v1.ForDateResponse
+	(*UpcomingFramesRequest)(nil),  // 9: tkd.cis.v1.UpcomingFramesRequest
+	(*UpcomingFramesResponse)(nil), // 10: tkd.cis.v1.UpcomingFramesResponse
+	(*timestamppb.Timestamp)(nil),  // 11: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),    // 12: google.protobuf.Duration
+}
+var file_tkd_cis_v1_opening_hours_proto_depIdxs = []int32{
+	1,  // 0: tkd.cis.v1.DayTimeRange.start:type_name -> tkd.cis.v1.DayTime
+	1,  // 1: tkd.cis.v1.DayTimeRange.end:type_name -> tkd.cis.v1.DayTime
+	11, // 2: tkd.cis.v1.TimeRange.from:type_name -> google.protobuf.Timestamp
+	11, // 3: tkd.cis.v1.TimeRange.to:type_name -> google.protobuf.Timestamp
+	0,  // 4: tkd.cis.v1.OpeningHour.kind:type_name -> tkd.cis.v1.FrameKind
+	2,  // 5: tkd.cis.v1.OpeningHour.range:type_name -> tkd.cis.v1.DayTimeRange
+	12, // 6: tkd.cis.v1.OpeningHour.open_before:type_name -> google.protobuf.Duration
+	12, // 7: tkd.cis.v1.OpeningHour.close_after:type_name -> google.protobuf.Duration
+	2,  // 8: tkd.cis.v1.Closure.range:type_name -> tkd.cis.v1.DayTimeRange
+	11, // 9: tkd.cis.v1.ForDateRequest.date:type_name -> google.protobuf.Timestamp
+	0,  // 10: tkd.cis.v1.ForDateRequest.kind:type_name -> tkd.cis.v1.FrameKind
+	4,  // 11: tkd.cis.v1.ForDateResponse.opening_hours:type_name -> tkd.cis.v1.OpeningHour
+	3,  // 12: tkd.cis.v1.ForDateResponse.frames:type_name -> tkd.cis.v1.TimeRange
+	5,  // 13: tkd.cis.v1.ForDateResponse.closures:type_name -> tkd.cis.v1.Closure
+	6,  // 14: tkd.cis.v1.ForDateResponse.holidays:type_name -> tkd.cis.v1.Holiday
+	11, // 15: tkd.cis.v1.UpcomingFramesRequest.from:type_name -> google.protobuf.Timestamp
+	0,  // 16: tkd.cis.v1.UpcomingFramesRequest.kind:type_name -> tkd.cis.v1.FrameKind
+	3,  // 17: tkd.cis.v1.UpcomingFramesResponse.frames:type_name -> tkd.cis.v1.TimeRange
+	7,  // 18: tkd.cis.v1.OpeningHoursService.ForDate:input_type -> tkd.cis.v1.ForDateRequest
+	9,  // 19: tkd.cis.v1.OpeningHoursService.UpcomingFrames:input_type -> tkd.cis.v1.UpcomingFramesRequest
+	8,  // 20: tkd.cis.v1.OpeningHoursService.ForDate:output_type -> tkd.cis.v1.ForDateResponse
+	10, // 21: tkd.cis.v1.OpeningHoursService.UpcomingFrames:output_type -> tkd.cis.v1.UpcomingFramesResponse
+	20, // [20:22] is the sub-list for method output_type
+	18, // [18:20] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_tkd_cis_v1_opening_hours_proto_init() }
+func file_tkd_cis_v1_opening_hours_proto_init() {
+	if File_tkd_cis_v1_opening_hours_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_tkd_cis_v1_opening_hours_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tkd_cis_v1_opening_hours_proto_goTypes,
+		DependencyIndexes: file_tkd_cis_v1_opening_hours_proto_depIdxs,
+		EnumInfos:         file_tkd_cis_v1_opening_hours_proto_enumTypes,
+		MessageInfos:      file_tkd_cis_v1_opening_hours_proto_msgTypes,
+	}.Build()
+	File_tkd_cis_v1_opening_hours_proto = out.File
+	file_tkd_cis_v1_opening_hours_proto_rawDesc = nil
+	file_tkd_cis_v1_opening_hours_proto_goTypes = nil
+	file_tkd_cis_v1_opening_hours_proto_depIdxs = nil
+}
@@ -0,0 +1,126 @@
+package configapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	idmv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/idm/v1"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/dirprovider"
+	"github.com/tierklinik-dobersberg/cis/runtime/session"
+)
+
+func TestSchemaAccess(t *testing.T) {
+	ctx := context.Background()
+
+	provider, err := dirprovider.New(t.TempDir())
+	require.NoError(t, err)
+
+	runtime.GlobalSchema.SetProvider(provider)
+	require.NoError(t, runtime.GlobalSchema.Register(runtime.Schema{
+		Name:  "Restricted",
+		Multi: true,
+		Spec: conf.SectionSpec{
+			{Name: "Name", Type: conf.StringType},
+		},
+	}))
+	require.NoError(t, runtime.GlobalSchema.SetRoles("Restricted", []string{"reader"}, []string{"writer"}))
+
+	users := map[string]*idmv1.Profile{
+		"other": {User: &idmv1.User{Id: "other"}},
+		"reader": {
+			User:  &idmv1.User{Id: "reader"},
+			Roles: []*idmv1.Role{{Id: "role-1", Name: "reader"}},
+		},
+		"writer": {
+			User:  &idmv1.User{Id: "writer"},
+			Roles: []*idmv1.Role{{Id: "role-2", Name: "writer"}},
+		},
+	}
+
+	e := echo.New()
+	Setup(&app.App{}, e.Group("/api/config/",
+		session.Middleware(session.UserProviderFunc(func(_ context.Context, id string) (*idmv1.Profile, error) {
+			return users[id], nil
+		})),
+		session.Require(),
+	))
+
+	do := func(user, method, path, body string) int {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if user != "" {
+			req.Header.Set("X-Remote-User-ID", user)
+		}
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	id, err := runtime.GlobalSchema.Create(ctx, "Restricted", []conf.Option{{Name: "Name", Value: "existing"}})
+	require.NoError(t, err)
+
+	create := func(user string) int {
+		return do(user, http.MethodPost, "/api/config/v1/schema/Restricted", `{"config":{"Name":"new"}}`)
+	}
+	batch := func(user string) int {
+		return do(user, http.MethodPost, "/api/config/v1/batch", `{"changes":[{"type":"update","schema":"Restricted","id":"`+id+`","config":{"Name":"batch"}}]}`)
+	}
+	importBundle := func(user string) int {
+		req := `{"sections":[{"schema":"Restricted","id":"` + id + `","options":[{"Name":"Name","Value":"imported"}]}]}`
+
+		return do(user, http.MethodPost, "/api/config/v1/import", req)
+	}
+	remove := func(user string) int {
+		return do(user, http.MethodDelete, "/api/config/v1/schema/Restricted/"+id, "")
+	}
+
+	// requests without a user are rejected.
+	assert.Equal(t, http.StatusUnauthorized, create(""))
+	assert.Equal(t, http.StatusUnauthorized, remove(""))
+
+	// schemas the user may not read do not exist.
+	for name, fn := range map[string]func(string) int{
+		"create": create,
+		"batch":  batch,
+		"import": importBundle,
+		"delete": remove,
+		"get": func(user string) int {
+			return do(user, http.MethodGet, "/api/config/v1/schema/Restricted", "")
+		},
+	} {
+		assert.Equal(t, http.StatusNotFound, fn("other"), name)
+	}
+
+	// readers are not allowed to change instances.
+	assert.Equal(t, http.StatusOK, do("reader", http.MethodGet, "/api/config/v1/schema/Restricted", ""))
+	for name, fn := range map[string]func(string) int{
+		"create": create,
+		"batch":  batch,
+		"import": importBundle,
+		"delete": remove,
+	} {
+		assert.Equal(t, http.StatusForbidden, fn("reader"), name)
+	}
+
+	// but writers are.
+	assert.Equal(t, http.StatusOK, create("writer"))
+	assert.Equal(t, http.StatusOK, batch("writer"))
+	assert.Equal(t, http.StatusOK, importBundle("writer"))
+	assert.Equal(t, http.StatusOK, remove("writer"))
+
+	_, err = runtime.GlobalSchema.GetID(ctx, id)
+	assert.ErrorIs(t, err, runtime.ErrCfgSectionNotFound)
+}
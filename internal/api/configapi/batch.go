@@ -0,0 +1,142 @@
+package configapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/pkg/confutil"
+	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+type BatchChange struct {
+	// Type is one of "create", "update" or "delete".
+	Type string `json:"type"`
+	// Schema is required for creates and updates.
+	Schema string `json:"schema,omitempty"`
+	// ID is required for updates and deletes.
+	ID     string                 `json:"id,omitempty"`
+	Config map[string]interface{} `json:"config,omitempty"`
+}
+
+type BatchRequest struct {
+	Changes []BatchChange `json:"changes"`
+}
+
+type BatchResponse struct {
+	// IDs holds the ID of each changed section in the order of the
+	// request.
+	IDs     []string `json:"ids"`
+	Warning string   `json:"warning,omitempty"`
+}
+
+// BatchEndpoint applies multiple configuration changes at once. The
+// changes are validated together, persisted atomically and change
+// listeners are notified after all changes have been applied.
+func BatchEndpoint(r *app.Router) {
+	r.POST(
+		"v1/batch",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			var req BatchRequest
+			if err := c.Bind(&req); err != nil {
+				return err
+			}
+
+			changes := make([]runtime.ConfigChange, len(req.Changes))
+			for idx, change := range req.Changes {
+				var err error
+				changes[idx], err = prepareBatchChange(ctx, change)
+				if err != nil {
+					return err
+				}
+			}
+
+			var warning string
+			applied, err := runtime.GlobalSchema.Batch(ctx, changes)
+			if err != nil {
+				if errors.Is(err, runtime.ErrBatchNotSupported) {
+					return echo.NewHTTPError(http.StatusNotImplemented, "configuration provider does not support batch changes")
+				}
+
+				warning, err = handleRuntimeError(ctx, err)
+				if err != nil {
+					return err
+				}
+			}
+
+			ids := make([]string, len(applied))
+			for idx, change := range applied {
+				ids[idx] = change.Section.ID
+			}
+
+			return c.JSON(http.StatusOK, BatchResponse{
+				IDs:     ids,
+				Warning: warning,
+			})
+		},
+	)
+}
+
+func prepareBatchChange(ctx context.Context, change BatchChange) (runtime.ConfigChange, error) {
+	schema := change.Schema
+
+	// deletes may omit the schema so we look it up to ensure
+	// the section is not part of an internal schema.
+	if change.Type == runtime.ChangeTypeDelete {
+		existing, err := runtime.GlobalSchema.GetID(ctx, change.ID)
+		if err != nil {
+			if errors.Is(err, runtime.ErrCfgSectionNotFound) {
+				return runtime.ConfigChange{}, httperr.NotFound("config", change.ID)
+			}
+
+			return runtime.ConfigChange{}, err
+		}
+
+		schema = existing.Name
+	}
+
+	if err := schemaAccessAllowed(ctx, schema, writeAccess); err != nil {
+		return runtime.ConfigChange{}, err
+	}
+
+	result := runtime.ConfigChange{
+		Type: change.Type,
+		Section: runtime.Section{
+			ID: change.ID,
+			Section: conf.Section{
+				Name: schema,
+			},
+		},
+	}
+
+	if change.Type == runtime.ChangeTypeDelete {
+		return result, nil
+	}
+
+	options, err := confutil.MapToOptions(change.Config)
+	if err != nil {
+		return runtime.ConfigChange{}, err
+	}
+	result.Section.Options = options
+
+	// apply defaults for new sections like CreateConfigEndpoint does.
+	if change.Type == runtime.ChangeTypeCreate {
+		spec, ok := runtime.GlobalSchema.OptionsForSection(schema)
+		if !ok {
+			return runtime.ConfigChange{}, httperr.NotFound("schema-type", schema)
+		}
+
+		sec, err := conf.Prepare(result.Section.Section, spec)
+		if err != nil {
+			return runtime.ConfigChange{}, httperr.BadRequest(err.Error()).SetInternal(err)
+		}
+
+		result.Section.Section = sec
+	}
+
+	return result, nil
+}
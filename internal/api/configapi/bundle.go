@@ -0,0 +1,125 @@
+package configapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+type ImportResponse struct {
+	DryRun  bool                   `json:"dryRun"`
+	Results []runtime.ImportResult `json:"results"`
+}
+
+// ExportEndpoint returns all configuration instances of non-internal
+// schemas as .conf file or JSON bundle. The exported schemas may be
+// limited using one or more schema query parameters.
+func ExportEndpoint(r *app.Router) {
+	r.GET(
+		"v1/export",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			format := c.QueryParam("format")
+			if format == "" {
+				format = runtime.BundleFormatConf
+			}
+
+			if format != runtime.BundleFormatConf && format != runtime.BundleFormatJSON {
+				return httperr.InvalidParameter("format", format)
+			}
+
+			schemas := c.QueryParams()["schema"]
+			for _, key := range schemas {
+				if err := schemaAccessAllowed(ctx, key, readAccess); err != nil {
+					return err
+				}
+			}
+
+			// Export would include all non-internal schemas so we must
+			// explicitly name the schemas the user is allowed to read.
+			if len(schemas) == 0 {
+				for _, s := range readableSchemas(ctx) {
+					schemas = append(schemas, s.Name)
+				}
+			}
+
+			bundle := &runtime.Bundle{
+				Sections: []runtime.BundleSection{},
+			}
+			if len(schemas) > 0 {
+				var err error
+				bundle, err = runtime.GlobalSchema.Export(ctx, schemas...)
+				if err != nil {
+					return err
+				}
+			}
+
+			var buf bytes.Buffer
+			if err := runtime.EncodeBundle(&buf, bundle, format); err != nil {
+				return httperr.BadRequest(err.Error()).SetInternal(err)
+			}
+
+			contentType := echo.MIMETextPlainCharsetUTF8
+			if format == runtime.BundleFormatJSON {
+				contentType = echo.MIMEApplicationJSONCharsetUTF8
+			}
+
+			c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "config."+format))
+
+			return c.Blob(http.StatusOK, contentType, buf.Bytes())
+		},
+	)
+}
+
+// ImportEndpoint creates or updates all configuration instances of the
+// bundle in the request body and reports the result of each section.
+// Use dryRun=true to only validate the bundle.
+func ImportEndpoint(r *app.Router) {
+	r.POST(
+		"v1/import",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			var dryRun bool
+			if value := c.QueryParam("dryRun"); value != "" {
+				var err error
+				dryRun, err = strconv.ParseBool(value)
+				if err != nil {
+					return httperr.InvalidParameter("dryRun", value)
+				}
+			}
+
+			format := c.QueryParam("format")
+			if format != "" && format != runtime.BundleFormatConf && format != runtime.BundleFormatJSON {
+				return httperr.InvalidParameter("format", format)
+			}
+
+			bundle, err := runtime.DecodeBundle(c.Request().Body, format)
+			if err != nil {
+				return httperr.BadRequest(err.Error()).SetInternal(err)
+			}
+
+			// unknown schemas are reported per section but internal
+			// schemas and schemas the user is not allowed to change
+			// reject the whole import.
+			for _, sec := range bundle.Sections {
+				if _, err := runtime.GlobalSchema.SchemaByName(sec.Schema); err != nil {
+					continue
+				}
+
+				if err := schemaAccessAllowed(ctx, sec.Schema, writeAccess); err != nil {
+					return err
+				}
+			}
+
+			return c.JSON(http.StatusOK, ImportResponse{
+				DryRun:  dryRun,
+				Results: runtime.GlobalSchema.Import(ctx, bundle, dryRun),
+			})
+		},
+	)
+}
@@ -27,7 +27,7 @@ func CreateConfigEndpoint(r *app.Router) {
 		func(ctx context.Context, app *app.App, c echo.Context) error {
 			key := c.Param("key")
 
-			if err := schemaAccessAllowed(key); err != nil {
+			if err := schemaAccessAllowed(ctx, key, writeAccess); err != nil {
 				return err
 			}
 
@@ -2,10 +2,13 @@ package configapi
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
 	"github.com/tierklinik-dobersberg/cis/runtime"
 )
 
@@ -18,8 +21,26 @@ func DeleteConfigEndpoint(r *app.Router) {
 	r.DELETE(
 		"v1/schema/:key/:id",
 		func(ctx context.Context, app *app.App, c echo.Context) error {
+			key := c.Param("key")
 			id := c.Param("id")
 
+			if err := schemaAccessAllowed(ctx, key, writeAccess); err != nil {
+				return err
+			}
+
+			existing, err := runtime.GlobalSchema.GetID(ctx, id)
+			if err != nil {
+				if errors.Is(err, runtime.ErrCfgSectionNotFound) {
+					return httperr.NotFound("config", id)
+				}
+
+				return err
+			}
+
+			if !strings.EqualFold(key, existing.Name) {
+				return httperr.NotFound("config", id)
+			}
+
 			var warning string
 			if err := runtime.GlobalSchema.Delete(ctx, id); err != nil {
 				warning, err = handleRuntimeError(ctx, err)
@@ -10,6 +10,7 @@ import (
 	"github.com/tierklinik-dobersberg/cis/internal/app"
 	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
 	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/session"
 )
 
 type GetConfigByIDResponse struct {
@@ -23,7 +24,7 @@ func GetConfigByIDEndpoint(r *app.Router) {
 			key := c.Param("key")
 			id := c.Param("id")
 
-			if err := schemaAccessAllowed(key); err != nil {
+			if err := schemaAccessAllowed(ctx, key, readAccess); err != nil {
 				return err
 			}
 
@@ -53,7 +54,19 @@ func GetConfigByIDEndpoint(r *app.Router) {
 	)
 }
 
-func schemaAccessAllowed(key string) error {
+// schemaAccess defines the access to a schema that is required by an
+// endpoint.
+type schemaAccess int
+
+const (
+	readAccess schemaAccess = iota
+	writeAccess
+)
+
+// schemaAccessAllowed ensures the schema key is not internal and the user
+// associated with ctx has the required access. Schemas the user is not
+// allowed to read are reported as not found.
+func schemaAccessAllowed(ctx context.Context, key string, access schemaAccess) error {
 	schema, err := runtime.GlobalSchema.SchemaByName(key)
 	if err != nil {
 		return httperr.NotFound("schema-type", key)
@@ -62,5 +75,28 @@ func schemaAccessAllowed(key string) error {
 		return httperr.PreconditionFailed("access to schema not allowed")
 	}
 
+	roles := session.RolesFromCtx(ctx)
+	if !schema.CanRead(roles) {
+		return httperr.NotFound("schema-type", key)
+	}
+	if access == writeAccess && !schema.CanWrite(roles) {
+		return httperr.Forbidden("write access to schema " + schema.Name + " not allowed")
+	}
+
 	return nil
 }
+
+// readableSchemas returns all non-internal schemas the user associated
+// with ctx is allowed to read.
+func readableSchemas(ctx context.Context) []runtime.Schema {
+	roles := session.RolesFromCtx(ctx)
+
+	var result []runtime.Schema
+	for _, s := range runtime.GlobalSchema.Schemas() {
+		if !s.Internal && s.CanRead(roles) {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
@@ -22,7 +22,7 @@ func GetConfigsEndpoint(r *app.Router) {
 		func(ctx context.Context, app *app.App, c echo.Context) error {
 			key := c.Param("key")
 
-			if err := schemaAccessAllowed(key); err != nil {
+			if err := schemaAccessAllowed(ctx, key, readAccess); err != nil {
 				return err
 			}
 
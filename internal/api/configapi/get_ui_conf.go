@@ -16,16 +16,11 @@ func GetFlatConfigEndpoint(grp *app.Router) {
 	grp.GET(
 		"v1/flat",
 		func(ctx context.Context, app *app.App, c echo.Context) error {
-			// NORELEASE(ppacher): check for permissions when reading configuration data!
 			keys := c.QueryParams()["keys"]
 
-			schemas := runtime.GlobalSchema.Schemas()
+			// only expose schemas the user is allowed to read.
 			lm := make(map[string]bool)
-			for _, s := range schemas {
-				// skip internal schemas
-				if s.Internal {
-					continue
-				}
+			for _, s := range readableSchemas(ctx) {
 				lm[s.Name] = s.Multi
 			}
 
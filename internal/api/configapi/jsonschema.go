@@ -0,0 +1,185 @@
+package configapi
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+// invalidComponentChars matches characters not allowed in OpenAPI
+// component names.
+var invalidComponentChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// GetJSONSchemaEndpoint returns the JSON Schema of a configuration
+// schema.
+func GetJSONSchemaEndpoint(r *app.Router) {
+	r.GET(
+		"v1/jsonschema/:key",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			key := c.Param("key")
+
+			if err := schemaAccessAllowed(ctx, key, readAccess); err != nil {
+				return err
+			}
+
+			schema, err := runtime.GlobalSchema.JSONSchema(key)
+			if err != nil {
+				return err
+			}
+
+			return c.JSON(http.StatusOK, schema)
+		},
+	)
+}
+
+// GetOpenAPIEndpoint returns an OpenAPI document describing the
+// configuration endpoints of all non-internal schemas the user is allowed
+// to read.
+func GetOpenAPIEndpoint(r *app.Router) {
+	r.GET(
+		"v1/openapi.json",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			return c.JSON(http.StatusOK, OpenAPI(readableSchemas(ctx)))
+		},
+	)
+}
+
+// OpenAPI returns an OpenAPI 3.1 document for the configuration API of
+// schemas. Each schema is added as component and referenced by the
+// endpoints to create, read, update and delete its instances.
+func OpenAPI(schemas []runtime.Schema) echo.Map {
+	components := echo.Map{
+		"ChangeResponse": echo.Map{
+			"type": "object",
+			"properties": echo.Map{
+				"id":      echo.Map{"type": "string"},
+				"warning": echo.Map{"type": "string"},
+			},
+		},
+	}
+	paths := echo.Map{}
+
+	changeResponse := jsonResponse("The change has been applied", ref("ChangeResponse"))
+	idParam := echo.Map{
+		"name":     "id",
+		"in":       "path",
+		"required": true,
+		"schema":   echo.Map{"type": "string"},
+	}
+
+	for _, s := range schemas {
+		name := invalidComponentChars.ReplaceAllString(s.Name, "_")
+
+		schema := runtime.SchemaToJSONSchema(s)
+		schema.Schema = ""
+		components[name] = schema
+
+		configBody := func(description string, config interface{}) echo.Map {
+			return echo.Map{
+				"description": description,
+				"required":    true,
+				"content": echo.Map{
+					echo.MIMEApplicationJSON: echo.Map{
+						"schema": echo.Map{
+							"type":       "object",
+							"properties": echo.Map{"config": config},
+							"required":   []string{"config"},
+						},
+					},
+				},
+			}
+		}
+
+		tags := []string{s.Name}
+
+		paths["/v1/schema/"+s.Name] = echo.Map{
+			"get": echo.Map{
+				"summary": "List all " + s.DisplayName + " instances",
+				"tags":    tags,
+				"responses": echo.Map{
+					"200": jsonResponse("All instances by ID", echo.Map{
+						"type": "object",
+						"properties": echo.Map{
+							"configs": echo.Map{
+								"type":                 "object",
+								"additionalProperties": ref(name),
+							},
+						},
+					}),
+				},
+			},
+			"post": echo.Map{
+				"summary":     "Create a new " + s.DisplayName + " instance",
+				"tags":        tags,
+				"requestBody": configBody("The new instance. Defaults are applied for missing options", ref(name)),
+				"responses":   echo.Map{"200": changeResponse},
+			},
+		}
+
+		paths["/v1/schema/"+s.Name+"/{id}"] = echo.Map{
+			"parameters": []echo.Map{idParam},
+			"get": echo.Map{
+				"summary": "Get a " + s.DisplayName + " instance",
+				"tags":    tags,
+				"responses": echo.Map{
+					"200": jsonResponse("The instance", echo.Map{
+						"type":       "object",
+						"properties": echo.Map{"config": ref(name)},
+					}),
+				},
+			},
+			"put": echo.Map{
+				"summary":     "Replace a " + s.DisplayName + " instance",
+				"tags":        tags,
+				"requestBody": configBody("The new options of the instance", ref(name)),
+				"responses":   echo.Map{"200": changeResponse},
+			},
+			"patch": echo.Map{
+				"summary":     "Update options of a " + s.DisplayName + " instance",
+				"tags":        tags,
+				"requestBody": configBody("The options to change", echo.Map{"type": "object"}),
+				"responses":   echo.Map{"200": changeResponse},
+			},
+			"delete": echo.Map{
+				"summary":   "Delete a " + s.DisplayName + " instance",
+				"tags":      tags,
+				"responses": echo.Map{"200": changeResponse},
+			},
+		}
+	}
+
+	return echo.Map{
+		"openapi":           "3.1.0",
+		"jsonSchemaDialect": runtime.JSONSchemaDialect,
+		"info": echo.Map{
+			"title":   "CIS configuration API",
+			"version": "v1",
+		},
+		"servers": []echo.Map{
+			{"url": "/api/config"},
+		},
+		"paths": paths,
+		"components": echo.Map{
+			"schemas": components,
+		},
+	}
+}
+
+func ref(component string) echo.Map {
+	return echo.Map{"$ref": "#/components/schemas/" + component}
+}
+
+func jsonResponse(description string, schema interface{}) echo.Map {
+	return echo.Map{
+		"description": description,
+		"content": echo.Map{
+			echo.MIMEApplicationJSON: echo.Map{
+				"schema": schema,
+			},
+		},
+	}
+}
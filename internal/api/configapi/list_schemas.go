@@ -23,15 +23,11 @@ func ListSchemasEndpoint(grp *app.Router) {
 	grp.GET(
 		"v1/schema",
 		func(ctx context.Context, app *app.App, c echo.Context) error {
-			schemas := runtime.GlobalSchema.Schemas()
 			var res ListSchemasResponse
 
-			for _, s := range schemas {
-				// skip schemas that are marked as internal.
-				if s.Internal {
-					continue
-				}
-
+			// internal schemas and schemas the user is not allowed to
+			// read are skipped.
+			for _, s := range readableSchemas(ctx) {
 				res.Schemas = append(res.Schemas, SchemaModel{
 					Schema:  s,
 					Options: s.Spec.All(),
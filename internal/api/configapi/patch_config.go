@@ -29,7 +29,7 @@ func PatchConfigEndpoint(r *app.Router) {
 			key := c.Param("key")
 			instanceID := c.Param("id")
 
-			if err := schemaAccessAllowed(key); err != nil {
+			if err := schemaAccessAllowed(ctx, key, writeAccess); err != nil {
 				return err
 			}
 
@@ -0,0 +1,177 @@
+package configapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+type ListRevisionsResponse struct {
+	Revisions []runtime.ConfigRevision `json:"revisions"`
+}
+
+type DiffRevisionsResponse struct {
+	From int                  `json:"from"`
+	To   int                  `json:"to,omitempty"`
+	Diff []runtime.OptionDiff `json:"diff"`
+}
+
+type RollbackResponse struct {
+	ID      string `json:"id"`
+	Warning string `json:"warning,omitempty"`
+}
+
+// ListRevisionsEndpoint returns all revisions of a configuration
+// instance, newest first.
+func ListRevisionsEndpoint(r *app.Router) {
+	r.GET(
+		"v1/schema/:key/:id/revisions",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			key := c.Param("key")
+			id := c.Param("id")
+
+			if err := schemaAccessAllowed(ctx, key, readAccess); err != nil {
+				return err
+			}
+
+			revisions, err := runtime.GlobalSchema.Revisions(ctx, id)
+			if err != nil {
+				return revisionError(err)
+			}
+
+			result := make([]runtime.ConfigRevision, 0, len(revisions))
+			for _, rev := range revisions {
+				if strings.EqualFold(rev.Schema, key) {
+					result = append(result, rev)
+				}
+			}
+
+			return c.JSON(http.StatusOK, ListRevisionsResponse{
+				Revisions: result,
+			})
+		},
+	)
+}
+
+// GetRevisionEndpoint returns a single revision of a configuration
+// instance.
+func GetRevisionEndpoint(r *app.Router) {
+	r.GET(
+		"v1/schema/:key/:id/revisions/:version",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			rev, err := getRevision(ctx, c, readAccess)
+			if err != nil {
+				return err
+			}
+
+			return c.JSON(http.StatusOK, rev)
+		},
+	)
+}
+
+// DiffRevisionsEndpoint returns the options that differ between two
+// revisions of a configuration instance. If to is omitted the revision
+// is compared with the current configuration.
+func DiffRevisionsEndpoint(r *app.Router) {
+	r.GET(
+		"v1/schema/:key/:id/revisions/:version/diff",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			rev, err := getRevision(ctx, c, readAccess)
+			if err != nil {
+				return err
+			}
+
+			var to int
+			if value := c.QueryParam("to"); value != "" {
+				to, err = strconv.Atoi(value)
+				if err != nil || to < 1 {
+					return httperr.InvalidParameter("to", value)
+				}
+			}
+
+			diff, err := runtime.GlobalSchema.DiffRevisions(ctx, rev.InstanceID, rev.Version, to)
+			if err != nil {
+				return revisionError(err)
+			}
+
+			return c.JSON(http.StatusOK, DiffRevisionsResponse{
+				From: rev.Version,
+				To:   to,
+				Diff: diff,
+			})
+		},
+	)
+}
+
+// RollbackEndpoint restores a configuration instance to the state of
+// a previous revision.
+func RollbackEndpoint(r *app.Router) {
+	r.POST(
+		"v1/schema/:key/:id/revisions/:version/rollback",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			rev, err := getRevision(ctx, c, writeAccess)
+			if err != nil {
+				return err
+			}
+
+			var warning string
+			id, err := runtime.GlobalSchema.Rollback(ctx, rev.InstanceID, rev.Version)
+			if err != nil {
+				warning, err = handleRuntimeError(ctx, err)
+				if err != nil {
+					return revisionError(err)
+				}
+			}
+
+			return c.JSON(http.StatusOK, RollbackResponse{
+				ID:      id,
+				Warning: warning,
+			})
+		},
+	)
+}
+
+// getRevision returns the revision referenced by the key, id and version
+// parameters if the user has access to the schema.
+func getRevision(ctx context.Context, c echo.Context, access schemaAccess) (*runtime.ConfigRevision, error) {
+	key := c.Param("key")
+	id := c.Param("id")
+
+	if err := schemaAccessAllowed(ctx, key, access); err != nil {
+		return nil, err
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		return nil, httperr.InvalidParameter("version", c.Param("version"))
+	}
+
+	rev, err := runtime.GlobalSchema.Revision(ctx, id, version)
+	if err != nil {
+		return nil, revisionError(err)
+	}
+
+	if !strings.EqualFold(rev.Schema, key) {
+		return nil, httperr.NotFound("revision", c.Param("version"))
+	}
+
+	return rev, nil
+}
+
+func revisionError(err error) error {
+	switch {
+	case errors.Is(err, runtime.ErrRevisionDisabled):
+		return httperr.PreconditionFailed(err.Error())
+	case errors.Is(err, runtime.ErrNoRevision):
+		return httperr.NotFound("revision", err.Error())
+	}
+
+	return err
+}
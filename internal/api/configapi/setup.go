@@ -15,6 +15,12 @@ func Setup(a *app.App, grp *echo.Group) {
 	// GET /api/config/v1/schemas
 	ListSchemasEndpoint(router)
 
+	// GET /api/config/v1/jsonschema/:key
+	GetJSONSchemaEndpoint(router)
+
+	// GET /api/config/v1/openapi.json
+	GetOpenAPIEndpoint(router)
+
 	// GET /api/config/v1/schema/:key
 	GetConfigsEndpoint(router)
 
@@ -33,6 +39,27 @@ func Setup(a *app.App, grp *echo.Group) {
 	// DELETE /api/config/v1/schema/:key/:id
 	DeleteConfigEndpoint(router)
 
+	// POST /api/config/v1/batch
+	BatchEndpoint(router)
+
+	// GET /api/config/v1/export
+	ExportEndpoint(router)
+
+	// POST /api/config/v1/import
+	ImportEndpoint(router)
+
+	// GET /api/config/v1/schema/:key/:id/revisions
+	ListRevisionsEndpoint(router)
+
+	// GET /api/config/v1/schema/:key/:id/revisions/:version
+	GetRevisionEndpoint(router)
+
+	// GET /api/config/v1/schema/:key/:id/revisions/:version/diff
+	DiffRevisionsEndpoint(router)
+
+	// POST /api/config/v1/schema/:key/:id/revisions/:version/rollback
+	RollbackEndpoint(router)
+
 	// POST /api/config/v1/test/:key/:testID
 	TestConfigEndpoint(router)
 }
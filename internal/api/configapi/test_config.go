@@ -13,6 +13,10 @@ import (
 )
 
 type TestConfigRequest struct {
+	// ID may be set to the configuration instance that is tested so
+	// redacted values of secret options are replaced with the stored
+	// values.
+	ID         string                 `json:"id,omitempty"`
 	Config     map[string]interface{} `json:"config"`
 	TestConfig map[string]interface{} `json:"testConfig"`
 }
@@ -24,6 +28,10 @@ func TestConfigEndpoint(r *app.Router) {
 			schemaType := c.Param("key")
 			testID := c.Param("testID")
 
+			if err := schemaAccessAllowed(ctx, schemaType, writeAccess); err != nil {
+				return err
+			}
+
 			var req TestConfigRequest
 			if err := c.Bind(&req); err != nil {
 				return err
@@ -39,9 +47,11 @@ func TestConfigEndpoint(r *app.Router) {
 				return err
 			}
 
-			testRes, err := runtime.GlobalSchema.Test(ctx, schemaType, testID, configOptions, testOptions)
+			testRes, err := runtime.GlobalSchema.Test(ctx, schemaType, testID, req.ID, configOptions, testOptions)
 			if err != nil {
 				switch {
+				case errors.Is(err, runtime.ErrCfgSectionNotFound) && req.ID != "":
+					return httperr.NotFound("config", req.ID)
 				case errors.Is(err, runtime.ErrCfgSectionNotFound):
 					return httperr.NotFound("schema-type", schemaType)
 				case errors.Is(err, runtime.ErrUnknownConfigTest):
@@ -31,7 +31,7 @@ func UpdateConfigEndpoint(r *app.Router) {
 			key := c.Param("key")
 			id := c.Param("id")
 
-			if err := schemaAccessAllowed(key); err != nil {
+			if err := schemaAccessAllowed(ctx, key, writeAccess); err != nil {
 				return err
 			}
 
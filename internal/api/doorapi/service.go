@@ -0,0 +1,143 @@
+package doorapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	cisv1 "github.com/tierklinik-dobersberg/cis/gen/go/tkd/cis/v1"
+	"github.com/tierklinik-dobersberg/cis/gen/go/tkd/cis/v1/cisv1connect"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/internal/door"
+	"github.com/tierklinik-dobersberg/logger"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Service implements cisv1connect.DoorServiceHandler.
+type Service struct {
+	cisv1connect.UnimplementedDoorServiceHandler
+
+	app *app.App
+}
+
+// NewService returns a new door connect service.
+func NewService(a *app.App) *Service {
+	return &Service{app: a}
+}
+
+// GetDoorState implements cisv1connect.DoorServiceHandler.
+func (svc *Service) GetDoorState(ctx context.Context, req *connect.Request[cisv1.GetDoorStateRequest]) (*connect.Response[cisv1.GetDoorStateResponse], error) {
+	var status door.Status
+
+	if req.Msg.At != nil {
+		status.State, status.Until = svc.app.Door.StateFor(ctx, req.Msg.At.AsTime().In(svc.app.Location()))
+	} else {
+		status = svc.current(ctx)
+	}
+
+	return connect.NewResponse(&cisv1.GetDoorStateResponse{
+		Status: statusToProto(status),
+	}), nil
+}
+
+// Overwrite implements cisv1connect.DoorServiceHandler.
+func (svc *Service) Overwrite(ctx context.Context, req *connect.Request[cisv1.OverwriteRequest]) (*connect.Response[cisv1.OverwriteResponse], error) {
+	var state door.State
+	switch req.Msg.State {
+	case cisv1.DoorState_DOOR_STATE_LOCKED:
+		state = door.Locked
+	case cisv1.DoorState_DOOR_STATE_UNLOCKED:
+		state = door.Unlocked
+	default:
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid door state: %s", req.Msg.State))
+	}
+
+	if err := req.Msg.Duration.CheckValid(); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid duration: %w", err))
+	}
+
+	setDuration := req.Msg.Duration.AsDuration()
+	if setDuration <= 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("duration must be positive"))
+	}
+
+	until := time.Now().Add(setDuration)
+
+	log.From(ctx).WithFields(logger.Fields{
+		"duration": setDuration.String(),
+		"state":    state,
+		"until":    until.String(),
+	}).V(6).Logf("received manual door overwrite request")
+
+	if err := svc.app.Door.Overwrite(ctx, state, until); err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&cisv1.OverwriteResponse{
+		Status: statusToProto(svc.current(ctx)),
+	}), nil
+}
+
+// Reset implements cisv1connect.DoorServiceHandler.
+func (svc *Service) Reset(ctx context.Context, req *connect.Request[cisv1.ResetRequest]) (*connect.Response[cisv1.ResetResponse], error) {
+	if err := svc.app.Door.Reset(ctx); err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&cisv1.ResetResponse{
+		Status: statusToProto(svc.current(ctx)),
+	}), nil
+}
+
+// WatchState implements cisv1connect.DoorServiceHandler.
+func (svc *Service) WatchState(ctx context.Context, req *connect.Request[cisv1.WatchStateRequest], stream *connect.ServerStream[cisv1.WatchStateResponse]) error {
+	// register the watcher before sending the current state so
+	// we don't miss any change in between.
+	changes := svc.app.Door.Watch(ctx)
+
+	if err := stream.Send(&cisv1.WatchStateResponse{
+		Status: statusToProto(svc.current(ctx)),
+	}); err != nil {
+		return err
+	}
+
+	for status := range changes {
+		if err := stream.Send(&cisv1.WatchStateResponse{
+			Status: statusToProto(status),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (svc *Service) current(ctx context.Context) door.Status {
+	state, until, resetInProgress := svc.app.Door.Current(ctx)
+
+	return door.Status{
+		State:           state,
+		Until:           until,
+		ResetInProgress: resetInProgress,
+	}
+}
+
+func statusToProto(status door.Status) *cisv1.DoorStatus {
+	res := &cisv1.DoorStatus{
+		ResetInProgress: status.ResetInProgress,
+	}
+
+	switch status.State {
+	case door.Locked:
+		res.State = cisv1.DoorState_DOOR_STATE_LOCKED
+	case door.Unlocked:
+		res.State = cisv1.DoorState_DOOR_STATE_UNLOCKED
+	}
+
+	if !status.Until.IsZero() {
+		res.Until = timestamppb.New(status.Until)
+	}
+
+	return res
+}
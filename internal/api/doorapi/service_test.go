@@ -0,0 +1,117 @@
+package doorapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	calendarv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1"
+	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1/calendarv1connect"
+	cisv1 "github.com/tierklinik-dobersberg/cis/gen/go/tkd/cis/v1"
+	"github.com/tierklinik-dobersberg/cis/gen/go/tkd/cis/v1/cisv1connect"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/internal/cfgspec"
+	"github.com/tierklinik-dobersberg/cis/internal/door"
+	"github.com/tierklinik-dobersberg/cis/internal/openinghours"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/fileprovider"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// noHolidays is a holiday service client without any public holidays.
+// Any other method of the holiday service panics.
+type noHolidays struct {
+	calendarv1connect.HolidayServiceClient
+}
+
+func (noHolidays) GetHoliday(context.Context, *connect.Request[calendarv1.GetHolidayRequest]) (*connect.Response[calendarv1.GetHolidayResponse], error) {
+	return connect.NewResponse(new(calendarv1.GetHolidayResponse)), nil
+}
+
+// newTestApp returns an application without any opening hours and a
+// running door scheduler.
+func newTestApp(t *testing.T) *app.App {
+	t.Helper()
+
+	ctx := context.Background()
+
+	schema := new(runtime.ConfigSchema)
+	require.NoError(t, openinghours.AddToSchema(schema))
+	require.NoError(t, door.AddToSchema(schema))
+	schema.SetProvider(fileprovider.New(new(conf.File)))
+
+	cfg := cfgspec.Config{
+		TimeZone: "Europe/Vienna",
+	}
+
+	ohCtrl, err := openinghours.NewWithHolidays(ctx, cfg, schema, noHolidays{})
+	require.NoError(t, err)
+
+	doorCtrl, err := door.NewDoorController(ctx, ohCtrl, schema)
+	require.NoError(t, err)
+
+	require.NoError(t, doorCtrl.Start())
+	t.Cleanup(func() {
+		assert.NoError(t, doorCtrl.Stop())
+	})
+
+	return &app.App{
+		Config: &app.Config{Config: cfg},
+		Door:   doorCtrl,
+	}
+}
+
+func TestWatchState(t *testing.T) {
+	a := newTestApp(t)
+
+	mux := http.NewServeMux()
+	mux.Handle(cisv1connect.NewDoorServiceHandler(NewService(a)))
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+
+	cli := cisv1connect.NewDoorServiceClient(srv.Client(), srv.URL)
+
+	stream, err := cli.WatchState(ctx, connect.NewRequest(&cisv1.WatchStateRequest{}))
+	require.NoError(t, err)
+
+	// the stream never ends on its own so it must be cancelled before
+	// closing it.
+	defer func() {
+		cancel()
+		stream.Close()
+	}()
+
+	// the current state is sent right away. Without opening hours the
+	// door is always locked.
+	require.True(t, stream.Receive(), "%s", stream.Err())
+	assert.Equal(t, cisv1.DoorState_DOOR_STATE_LOCKED, stream.Msg().Status.State)
+
+	res, err := cli.Overwrite(ctx, connect.NewRequest(&cisv1.OverwriteRequest{
+		State:    cisv1.DoorState_DOOR_STATE_UNLOCKED,
+		Duration: durationpb.New(time.Hour),
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, cisv1.DoorState_DOOR_STATE_UNLOCKED, res.Msg.Status.State)
+
+	// the overwrite reaches the stream once the scheduler applied it.
+	for stream.Receive() {
+		if stream.Msg().Status.State == cisv1.DoorState_DOOR_STATE_UNLOCKED {
+			require.NotNil(t, stream.Msg().Status.Until)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), stream.Msg().Status.Until.AsTime(), time.Minute)
+
+			return
+		}
+	}
+
+	t.Fatalf("door state change not received: %s", stream.Err())
+}
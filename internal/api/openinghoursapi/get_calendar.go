@@ -0,0 +1,216 @@
+package openinghoursapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/internal/openinghours"
+	"github.com/tierklinik-dobersberg/cis/pkg/daytime"
+	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
+	"github.com/tierklinik-dobersberg/cis/pkg/ical"
+)
+
+const (
+	defaultCalendarWeeks = 4
+	maxCalendarWeeks     = 52
+)
+
+// GetCalendarEndpoint publishes the opening hours of the upcoming weeks
+// as an iCalendar feed so they can be subscribed to by calendar clients.
+//
+// Supported query parameters:
+//
+//	weeks: the number of weeks to include, starting today (default 4, max 52)
+//	door:  if set to true, the door unlock padding before and after each
+//	       opening hour as well as additional door ranges are included
+//	       as separate events.
+func GetCalendarEndpoint(router *app.Router) {
+	router.GET(
+		"v1/calendar.ics",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			weeks := defaultCalendarWeeks
+			if w := c.QueryParam("weeks"); w != "" {
+				parsed, err := strconv.ParseInt(w, 10, 0)
+				if err != nil || parsed <= 0 || parsed > maxCalendarWeeks {
+					return httperr.InvalidParameter("weeks", fmt.Sprintf("must be between 1 and %d", maxCalendarWeeks))
+				}
+
+				weeks = int(parsed)
+			}
+
+			var includeDoor bool
+			if d := c.QueryParam("door"); d != "" {
+				var err error
+				includeDoor, err = strconv.ParseBool(d)
+				if err != nil {
+					return httperr.InvalidParameter("door", err.Error())
+				}
+			}
+
+			cal, err := getOpeningHoursCalendar(ctx, app, time.Now(), weeks, includeDoor)
+			if err != nil {
+				return err
+			}
+
+			c.Response().Header().Set(echo.HeaderContentType, "text/calendar; charset=utf-8")
+			c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="opening-hours.ics"`)
+			c.Response().WriteHeader(http.StatusOK)
+
+			return cal.Encode(c.Response())
+		},
+	)
+}
+
+func getOpeningHoursCalendar(ctx context.Context, app *app.App, start time.Time, weeks int, includeDoor bool) (*ical.Calendar, error) {
+	holidays, err := newHolidayClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &ical.Calendar{
+		ProductID:       "-//tierklinik-dobersberg//cis//EN",
+		Name:            "Opening Hours",
+		RefreshInterval: 12 * time.Hour,
+	}
+
+	current := daytime.Midnight(start.In(app.Location()))
+	end := current.AddDate(0, 0, weeks*7)
+
+	publicHolidays, err := getHolidays(ctx, holidays, current, end)
+	if err != nil {
+		return nil, err
+	}
+
+	for current.Before(end) {
+		holiday, isHoliday := publicHolidays[current.Format("2006-01-02")]
+
+		frames := app.Door.ForDate(ctx, current)
+
+		var description string
+		if isHoliday && holiday != nil {
+			description = holiday.LocalName
+		}
+
+		// a public holiday without any opening hours is published as a
+		// whole-day closure.
+		if isHoliday && len(frames) == 0 {
+			summary := "Closed"
+			if description != "" {
+				summary = fmt.Sprintf("Closed: %s", description)
+			}
+
+			cal.Events = append(cal.Events, ical.Event{
+				UID:         eventUID("closed", current, daytime.Range{}),
+				Summary:     summary,
+				Categories:  []string{"holiday"},
+				Start:       current,
+				End:         current.AddDate(0, 0, 1),
+				AllDay:      true,
+				Transparent: true,
+			})
+		}
+
+		for idx, closure := range app.Door.ClosuresForDate(ctx, current) {
+			summary := "Closed"
+			if closure.Reason != "" {
+				summary = fmt.Sprintf("Closed: %s", closure.Reason)
+			}
+
+			evt := ical.Event{
+				UID:         eventUID(fmt.Sprintf("closure%d", idx), current, closure.Range),
+				Summary:     summary,
+				Categories:  []string{"closure"},
+				Transparent: true,
+			}
+
+			if closure.AllDay {
+				evt.AllDay = true
+				evt.Start = current
+				evt.End = current.AddDate(0, 0, 1)
+			} else {
+				tr := closure.Range.At(current, app.Location())
+				evt.Start = tr.From
+				evt.End = tr.To
+			}
+
+			cal.Events = append(cal.Events, evt)
+		}
+
+		for _, frame := range frames {
+			tr := frame.At(current, app.Location())
+
+			cal.Events = append(cal.Events, ical.Event{
+				UID:         eventUID("open", current, frame.Range),
+				Summary:     "Open",
+				Description: description,
+				Categories:  []string{"opening-hours"},
+				Start:       tr.From,
+				End:         tr.To,
+			})
+
+			if !includeDoor {
+				continue
+			}
+
+			if frame.OpenBefore > 0 {
+				cal.Events = append(cal.Events, ical.Event{
+					UID:         eventUID("door-open-before", current, frame.Range),
+					Summary:     "Door unlocked",
+					Categories:  []string{"door"},
+					Start:       tr.From.Add(-frame.OpenBefore),
+					End:         tr.From,
+					Transparent: true,
+				})
+			}
+
+			if frame.CloseAfter > 0 {
+				cal.Events = append(cal.Events, ical.Event{
+					UID:         eventUID("door-close-after", current, frame.Range),
+					Summary:     "Door unlocked",
+					Categories:  []string{"door"},
+					Start:       tr.To,
+					End:         tr.To.Add(frame.CloseAfter),
+					Transparent: true,
+				})
+			}
+		}
+
+		// explicitly configured door ranges are not bound to any business
+		// hours and are published on their own.
+		if includeDoor {
+			for _, frame := range app.Door.ForDateOfKind(ctx, current, openinghours.KindDoor) {
+				tr := frame.At(current, app.Location())
+
+				cal.Events = append(cal.Events, ical.Event{
+					UID:         eventUID("door", current, frame.Range),
+					Summary:     "Door unlocked",
+					Categories:  []string{"door"},
+					Start:       tr.From,
+					End:         tr.To,
+					Transparent: true,
+				})
+			}
+		}
+
+		current = current.AddDate(0, 0, 1)
+	}
+
+	return cal, nil
+}
+
+// eventUID returns a stable UID for an event of kind at date for frame
+// so calendar clients can track events across feed updates.
+func eventUID(kind string, date time.Time, frame daytime.Range) string {
+	return fmt.Sprintf(
+		"%s-%s-%02d%02d-%02d%02d@openinghours.cis",
+		kind,
+		date.Format("20060102"),
+		frame.From[0], frame.From[1],
+		frame.To[0], frame.To[1],
+	)
+}
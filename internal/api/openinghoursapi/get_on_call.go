@@ -0,0 +1,53 @@
+package openinghoursapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/internal/openinghours"
+	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
+)
+
+type GetOnCallResponse struct {
+	Shift openinghours.OnCallShift `json:"shift"`
+	Since time.Time                `json:"since"`
+	Until time.Time                `json:"until"`
+}
+
+// GetOnCallEndpoint returns the on-call shift (day or night) that is
+// active at the time specified in the at= query parameter (RFC3339,
+// defaults to now) and when the next shift starts.
+func GetOnCallEndpoint(router *app.Router) {
+	router.GET(
+		"v1/on-call",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			at := time.Now()
+			if value := c.QueryParam("at"); value != "" {
+				var err error
+				at, err = time.Parse(time.RFC3339, value)
+				if err != nil {
+					return httperr.InvalidParameter("at", err.Error())
+				}
+			}
+
+			shift, since, until, err := app.Door.OnCallAt(ctx, at)
+			if err != nil {
+				if errors.Is(err, openinghours.ErrNoOnCallConfig) {
+					return httperr.PreconditionFailed(err.Error())
+				}
+
+				return err
+			}
+
+			return c.JSON(http.StatusOK, GetOnCallResponse{
+				Shift: shift,
+				Since: since,
+				Until: until,
+			})
+		},
+	)
+}
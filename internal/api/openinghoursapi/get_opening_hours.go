@@ -9,10 +9,11 @@ import (
 	"github.com/bufbuild/connect-go"
 	"github.com/labstack/echo/v4"
 	calendarv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1"
-	commonv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/common/v1"
+	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1/calendarv1connect"
 	"github.com/tierklinik-dobersberg/apis/pkg/discovery/consuldiscover"
 	"github.com/tierklinik-dobersberg/apis/pkg/discovery/wellknown"
 	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/internal/openinghours"
 	"github.com/tierklinik-dobersberg/cis/pkg/daytime"
 	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
 	"github.com/tierklinik-dobersberg/logger"
@@ -26,22 +27,44 @@ type TimeRange struct {
 	daytime.TimeRange
 }
 
+// Closure describes a closure at a specific date. From and To are
+// unset for closures that span the whole day.
+type Closure struct {
+	Reason string     `json:"reason,omitempty"`
+	AllDay bool       `json:"allDay"`
+	From   *time.Time `json:"from,omitempty"`
+	To     *time.Time `json:"to,omitempty"`
+}
+
 type GetOpeningHoursResponse struct {
 	Frames    []TimeRange `json:"openingHours"`
 	IsHoliday bool        `json:"holiday"`
+	Closures  []Closure   `json:"closures,omitempty"`
+
+	// Holidays holds the holidays of all holiday calendars at the date.
+	Holidays []openinghours.Holiday `json:"holidays,omitempty"`
+
+	// Calendar is the name of the holiday calendar that caused the
+	// opening hours to be selected, if any.
+	Calendar string `json:"calendar,omitempty"`
 }
 
 func GetOpeningHoursEndpoint(router *app.Router) {
 	router.GET(
 		"v1/opening-hours",
 		func(ctx context.Context, app *app.App, c echo.Context) error {
+			kind, err := openinghours.ParseFrameKind(c.QueryParam("kind"))
+			if err != nil {
+				return httperr.InvalidParameter("kind", err.Error())
+			}
+
 			from := c.QueryParam("from")
 			to := c.QueryParam("to")
 			if from != "" || to != "" {
 				if from == "" || to == "" {
 					return httperr.BadRequest("from= and to= must both be set")
 				}
-				res, err := getOpeningHoursRangeResponse(ctx, app, from, to)
+				res, err := getOpeningHoursRangeResponse(ctx, app, from, to, kind)
 				if err != nil {
 					return err
 				}
@@ -50,7 +73,7 @@ func GetOpeningHoursEndpoint(router *app.Router) {
 			}
 
 			at := c.QueryParam("at")
-			res, err := getSingleDayOpeningHours(ctx, app, at, time.Now())
+			res, err := getSingleDayOpeningHours(ctx, app, at, time.Now(), kind)
 			if err != nil {
 				return err
 			}
@@ -60,7 +83,7 @@ func GetOpeningHoursEndpoint(router *app.Router) {
 	)
 }
 
-func getSingleDayOpeningHours(ctx context.Context, app *app.App, at string, date time.Time) (*GetOpeningHoursResponse, error) {
+func getSingleDayOpeningHours(ctx context.Context, app *app.App, at string, date time.Time, kind openinghours.FrameKind) (*GetOpeningHoursResponse, error) {
 	if at != "" {
 		var err error
 		date, err = app.ParseTime("2006-1-2", at)
@@ -69,51 +92,83 @@ func getSingleDayOpeningHours(ctx context.Context, app *app.App, at string, date
 		}
 	}
 
-	frames := app.Door.ForDate(ctx, date)
-	holiday, err := isHoliday(ctx, date)
-	if err != nil {
-		return nil, err
-	}
+	frames := app.Door.FramesForDate(ctx, date, kind)
+	match := app.Door.HolidaysForDate(ctx, date, kind)
 
 	timeRanges := make([]TimeRange, len(frames))
 	for idx, frame := range frames {
 		timeRanges[idx] = TimeRange{
-			TimeRange: *frame.At(date, app.Location()),
+			TimeRange: frame,
 		}
 	}
 
 	return &GetOpeningHoursResponse{
 		Frames:    timeRanges,
-		IsHoliday: holiday,
+		IsHoliday: match.IsPublicHoliday(),
+		Closures:  getClosures(ctx, app, date),
+		Holidays:  match.Holidays,
+		Calendar:  match.Calendar,
 	}, nil
 }
 
-func isHoliday(ctx context.Context, date time.Time) (bool, error) {
+func getClosures(ctx context.Context, app *app.App, date time.Time) []Closure {
+	closures := app.Door.ClosuresForDate(ctx, date)
+
+	result := make([]Closure, len(closures))
+	for idx, c := range closures {
+		result[idx] = Closure{
+			Reason: c.Reason,
+			AllDay: c.AllDay,
+		}
+
+		if !c.AllDay {
+			tr := c.Range.At(date, app.Location())
+			result[idx].From = &tr.From
+			result[idx].To = &tr.To
+		}
+	}
+
+	return result
+}
+
+func newHolidayClient(ctx context.Context) (calendarv1connect.HolidayServiceClient, error) {
 	disc, err := consuldiscover.NewFromEnv()
 	if err != nil {
-		return false, fmt.Errorf("failed to get consul service catalog: %w", err)
+		return nil, fmt.Errorf("failed to get consul service catalog: %w", err)
 	}
 
 	cli, err := wellknown.HolidayService.Create(ctx, disc)
 	if err != nil {
-		return false, fmt.Errorf("failed to get holiday service client: %w", err)
+		return nil, fmt.Errorf("failed to get holiday service client: %w", err)
 	}
 
-	res, err := cli.IsHoliday(ctx, connect.NewRequest(&calendarv1.IsHolidayRequest{
-		Date: &commonv1.Date{
-			Year:  int64(date.Year()),
-			Month: commonv1.Month(date.Month()),
-			Day:   int32(date.Day()),
-		},
-	}))
-	if err != nil {
-		return false, fmt.Errorf("failed to query holiday service: %w", err)
+	return cli, nil
+}
+
+// getHolidays returns all public holidays between from and to indexed
+// by their date in the format YYYY-MM-DD. Holidays are loaded once per
+// year so callers iterating over many days do not need to query the
+// holiday service for each day.
+func getHolidays(ctx context.Context, cli calendarv1connect.HolidayServiceClient, from, to time.Time) (map[string]*calendarv1.PublicHoliday, error) {
+	result := make(map[string]*calendarv1.PublicHoliday)
+
+	for year := from.Year(); year <= to.Year(); year++ {
+		res, err := cli.GetHoliday(ctx, connect.NewRequest(&calendarv1.GetHolidayRequest{
+			Year: uint64(year),
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query holiday service: %w", err)
+		}
+
+		for _, holiday := range res.Msg.Holidays {
+			result[holiday.Date] = holiday
+		}
 	}
 
-	return res.Msg.IsHoliday, nil
+	return result, nil
 }
 
-func getOpeningHoursRangeResponse(ctx context.Context, app *app.App, from, to string) (*GetOpeningHoursRangeResponse, error) {
+func getOpeningHoursRangeResponse(ctx context.Context, app *app.App, from, to string, kind openinghours.FrameKind) (*GetOpeningHoursRangeResponse, error) {
 	fromTime, err := app.ParseTime("2006-1-2", from)
 	if err != nil {
 		return nil, httperr.InvalidParameter("from", err.Error())
@@ -134,7 +189,7 @@ func getOpeningHoursRangeResponse(ctx context.Context, app *app.App, from, to st
 
 	current := fromTime
 	for current.Before(toTime) {
-		day, err := getSingleDayOpeningHours(ctx, app, "", current)
+		day, err := getSingleDayOpeningHours(ctx, app, "", current, kind)
 		if err != nil {
 			return nil, err
 		}
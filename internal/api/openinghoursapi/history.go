@@ -0,0 +1,165 @@
+package openinghoursapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/internal/door"
+	"github.com/tierklinik-dobersberg/cis/internal/openinghours"
+	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
+)
+
+type ListHistoryResponse struct {
+	Revisions []openinghours.Revision `json:"revisions"`
+}
+
+type DoorStateAt struct {
+	State door.State `json:"state"`
+	Until time.Time  `json:"until,omitempty"`
+}
+
+type EvaluateHistoryResponse struct {
+	// Revision is the configuration revision that has been used. The
+	// configuration snapshot itself is omitted.
+	Revision openinghours.Revision `json:"revision"`
+
+	At           time.Time                  `json:"at"`
+	OpeningHours []openinghours.OpeningHour `json:"openingHours"`
+	Frames       []TimeRange                `json:"frames"`
+	Closures     []openinghours.Closure     `json:"closures,omitempty"`
+	Holidays     openinghours.HolidayMatch  `json:"holidays"`
+	Door         DoorStateAt                `json:"door"`
+}
+
+// ListHistoryEndpoint returns all opening hour revisions created between
+// from= and to= (RFC3339). to= defaults to now and from= to 30 days
+// before to=.
+func ListHistoryEndpoint(router *app.Router) {
+	router.GET(
+		"v1/history",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			to, err := parseTimeParam(c, "to", time.Now())
+			if err != nil {
+				return err
+			}
+
+			from, err := parseTimeParam(c, "from", to.AddDate(0, 0, -30))
+			if err != nil {
+				return err
+			}
+
+			revisions, err := app.Door.Revisions(ctx, from, to)
+			if err != nil {
+				return historyError(err)
+			}
+
+			return c.JSON(http.StatusOK, ListHistoryResponse{
+				Revisions: revisions,
+			})
+		},
+	)
+}
+
+// GetRevisionEndpoint returns a single opening hour revision including
+// the complete configuration snapshot.
+func GetRevisionEndpoint(router *app.Router) {
+	router.GET(
+		"v1/history/:version",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			version, err := strconv.Atoi(c.Param("version"))
+			if err != nil {
+				return httperr.InvalidParameter("version", err.Error())
+			}
+
+			rev, err := app.Door.Revision(ctx, version)
+			if err != nil {
+				return historyError(err)
+			}
+
+			return c.JSON(http.StatusOK, rev)
+		},
+	)
+}
+
+// EvaluateHistoryEndpoint evaluates the opening hours and the desired
+// door state at at= (RFC3339) using the configuration that was active
+// at config= (RFC3339, defaults to at=). Manual door overwrites are
+// not recorded and thus not taken into account.
+func EvaluateHistoryEndpoint(router *app.Router) {
+	router.GET(
+		"v1/history/evaluate",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			kind, err := openinghours.ParseFrameKind(c.QueryParam("kind"))
+			if err != nil {
+				return httperr.InvalidParameter("kind", err.Error())
+			}
+
+			if c.QueryParam("at") == "" {
+				return httperr.MissingParameter("at")
+			}
+
+			at, err := parseTimeParam(c, "at", time.Time{})
+			if err != nil {
+				return err
+			}
+			at = at.In(app.Location())
+
+			configTime, err := parseTimeParam(c, "config", at)
+			if err != nil {
+				return err
+			}
+
+			historic, rev, err := app.Door.AsOf(ctx, configTime)
+			if err != nil {
+				return historyError(err)
+			}
+
+			res := EvaluateHistoryResponse{
+				Revision:     *rev,
+				At:           at,
+				OpeningHours: historic.ForDateOfKind(ctx, at, kind),
+				Closures:     historic.ClosuresForDate(ctx, at),
+				Holidays:     historic.HolidaysForDate(ctx, at, kind),
+			}
+			res.Revision.Sections = nil
+
+			for _, frame := range historic.FramesForDate(ctx, at, kind) {
+				res.Frames = append(res.Frames, TimeRange{TimeRange: frame})
+			}
+
+			res.Door.State, res.Door.Until = door.DesiredState(ctx, historic, at)
+
+			return c.JSON(http.StatusOK, res)
+		},
+	)
+}
+
+func parseTimeParam(c echo.Context, name string, def time.Time) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return def, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return t, httperr.InvalidParameter(name, err.Error())
+	}
+
+	return t, nil
+}
+
+func historyError(err error) error {
+	switch {
+	case errors.Is(err, openinghours.ErrHistoryDisabled):
+		return httperr.PreconditionFailed(err.Error())
+	case errors.Is(err, openinghours.ErrNoRevision):
+		return httperr.NotFound("revision", err.Error())
+	}
+
+	return err
+}
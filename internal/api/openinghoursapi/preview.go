@@ -0,0 +1,116 @@
+package openinghoursapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/internal/openinghours"
+	"github.com/tierklinik-dobersberg/cis/pkg/confutil"
+	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+const (
+	defaultPreviewWeeks = 4
+	maxPreviewWeeks     = 52
+)
+
+type PreviewRequest struct {
+	// ID is the ID of the OpeningHour instance that should be updated
+	// or deleted. It must be empty when previewing the creation of a
+	// new instance.
+	ID string `json:"id"`
+	// Delete can be set to true to preview the deletion of ID.
+	Delete bool `json:"delete"`
+	// Config holds the proposed configuration of the instance.
+	Config map[string]interface{} `json:"config"`
+	// Weeks is the number of weeks to evaluate, starting today.
+	Weeks int `json:"weeks"`
+}
+
+type PreviewResponse struct {
+	From time.Time              `json:"from"`
+	To   time.Time              `json:"to"`
+	Days []openinghours.DayDiff `json:"days"`
+}
+
+// PreviewEndpoint evaluates a proposed change to an OpeningHour instance
+// without storing it and returns all days in the upcoming weeks whose
+// opening hours or door transitions would change.
+func PreviewEndpoint(router *app.Router) {
+	router.POST(
+		"v1/preview",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			var req PreviewRequest
+			if err := c.Bind(&req); err != nil {
+				return err
+			}
+
+			if req.Weeks == 0 {
+				req.Weeks = defaultPreviewWeeks
+			}
+			if req.Weeks < 0 || req.Weeks > maxPreviewWeeks {
+				return httperr.BadRequest(fmt.Sprintf("weeks must be between 1 and %d", maxPreviewWeeks))
+			}
+
+			changeType := runtime.ChangeTypeCreate
+			switch {
+			case req.Delete && req.ID == "":
+				return httperr.MissingField("id")
+			case req.Delete:
+				changeType = runtime.ChangeTypeDelete
+			case req.ID != "":
+				changeType = runtime.ChangeTypeUpdate
+			}
+
+			var sec *conf.Section
+			if req.ID != "" {
+				current, err := runtime.GlobalSchema.GetID(ctx, req.ID)
+				if err != nil {
+					return err
+				}
+
+				if !strings.EqualFold(current.Name, "OpeningHour") {
+					return httperr.NotFound("OpeningHour", req.ID)
+				}
+
+				sec = &current.Section
+			}
+
+			if !req.Delete {
+				options, err := confutil.MapToOptions(req.Config)
+				if err != nil {
+					return err
+				}
+
+				prepared, err := conf.Prepare(conf.Section{
+					Name:    "OpeningHour",
+					Options: options,
+				}, openinghours.Spec)
+				if err != nil {
+					return httperr.BadRequest(err.Error()).SetInternal(err)
+				}
+
+				sec = &prepared
+			}
+
+			from := time.Now().In(app.Location())
+			days, err := app.Door.Preview(ctx, changeType, req.ID, sec, from, req.Weeks*7)
+			if err != nil {
+				return httperr.BadRequest(err.Error()).SetInternal(err)
+			}
+
+			return c.JSON(http.StatusOK, PreviewResponse{
+				From: from,
+				To:   from.AddDate(0, 0, req.Weeks*7),
+				Days: days,
+			})
+		},
+	)
+}
@@ -0,0 +1,232 @@
+package openinghoursapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/internal/openinghours"
+	"github.com/tierklinik-dobersberg/cis/pkg/daytime"
+)
+
+// maxPublicCacheAge is the maximum time clients and proxies may cache
+// responses of the public endpoints.
+const maxPublicCacheAge = 5 * time.Minute
+
+type PublicDay struct {
+	Date      string      `json:"date"`
+	Weekday   string      `json:"weekday"`
+	IsHoliday bool        `json:"holiday"`
+	Holiday   string      `json:"holidayName,omitempty"`
+	Frames    []TimeRange `json:"openingHours"`
+	Closures  []Closure   `json:"closures,omitempty"`
+}
+
+type PublicStatusResponse struct {
+	Open        bool        `json:"open"`
+	OpensAt     *time.Time  `json:"opensAt,omitempty"`
+	ClosesAt    *time.Time  `json:"closesAt,omitempty"`
+	NextOpening *TimeRange  `json:"nextOpening,omitempty"`
+	Week        []PublicDay `json:"week"`
+}
+
+// PublicStatusEndpoint returns whether or not we are open right now, when
+// the current frame ends or the next one starts as well as the opening
+// hours for the upcoming week.
+// If the format=jsonld query parameter is set or the client accepts
+// application/ld+json, the opening hours are returned as schema.org
+// OpeningHoursSpecifications instead.
+func PublicStatusEndpoint(router *app.Router) {
+	router.GET(
+		"v1/status",
+		func(ctx context.Context, app *app.App, c echo.Context) error {
+			now := time.Now().In(app.Location())
+
+			res, validUntil, err := getPublicStatus(ctx, app, now)
+			if err != nil {
+				return err
+			}
+
+			var (
+				result      any = res
+				contentType     = echo.MIMEApplicationJSONCharsetUTF8
+			)
+
+			if c.QueryParam("format") == "jsonld" || strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "application/ld+json") {
+				result = getSchemaOrgDocument(app, res)
+				contentType = "application/ld+json; charset=utf-8"
+			}
+
+			return writeCacheable(c, contentType, result, time.Until(validUntil))
+		},
+	)
+}
+
+func getPublicStatus(ctx context.Context, app *app.App, now time.Time) (*PublicStatusResponse, time.Time, error) {
+	res := &PublicStatusResponse{}
+
+	// the response is valid until the end of the day unless there's
+	// an earlier state change.
+	validUntil := daytime.Midnight(now).AddDate(0, 0, 1)
+
+	frames := app.Door.UpcomingFrames(ctx, now, openinghours.KindBusiness, 2)
+	if len(frames) > 0 {
+		current := frames[0]
+
+		if current.Covers(now) {
+			res.Open = true
+			res.ClosesAt = &current.To
+
+			if len(frames) > 1 {
+				res.NextOpening = &TimeRange{TimeRange: frames[1]}
+			}
+
+			if current.To.Before(validUntil) {
+				validUntil = current.To
+			}
+		} else {
+			res.OpensAt = &current.From
+			res.NextOpening = &TimeRange{TimeRange: current}
+
+			if current.From.Before(validUntil) {
+				validUntil = current.From
+			}
+		}
+	}
+
+	date := daytime.Midnight(now)
+	for i := 0; i < 7; i++ {
+		// a day is reported as a holiday if the holiday calendars
+		// selected its opening hours.
+		match := app.Door.HolidaysForDate(ctx, date, openinghours.KindBusiness)
+
+		day := PublicDay{
+			Date:      date.Format("2006-01-02"),
+			Weekday:   date.Weekday().String(),
+			IsHoliday: match.Calendar != "",
+			Frames:    []TimeRange{},
+			Closures:  getClosures(ctx, app, date),
+		}
+
+		for _, holiday := range match.Holidays {
+			if holiday.Calendar == match.Calendar {
+				day.Holiday = holiday.Name
+
+				break
+			}
+		}
+
+		for _, frame := range app.Door.FramesForDate(ctx, date, openinghours.KindBusiness) {
+			day.Frames = append(day.Frames, TimeRange{
+				TimeRange: frame,
+			})
+		}
+
+		res.Week = append(res.Week, day)
+
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return res, validUntil, nil
+}
+
+type schemaOrgOpeningHours struct {
+	Type         string `json:"@type"`
+	DayOfWeek    string `json:"dayOfWeek"`
+	Opens        string `json:"opens"`
+	Closes       string `json:"closes"`
+	ValidFrom    string `json:"validFrom,omitempty"`
+	ValidThrough string `json:"validThrough,omitempty"`
+}
+
+type schemaOrgDocument struct {
+	Context                   string                  `json:"@context"`
+	Type                      string                  `json:"@type"`
+	Name                      string                  `json:"name,omitempty"`
+	URL                       string                  `json:"url,omitempty"`
+	OpeningHoursSpecification []schemaOrgOpeningHours `json:"openingHoursSpecification"`
+}
+
+func getSchemaOrgDocument(app *app.App, status *PublicStatusResponse) *schemaOrgDocument {
+	doc := &schemaOrgDocument{
+		Context:                   "https://schema.org",
+		Type:                      "VeterinaryCare",
+		Name:                      app.Config.PublicBusinessName,
+		URL:                       app.Config.BaseURL,
+		OpeningHoursSpecification: []schemaOrgOpeningHours{},
+	}
+
+	for _, day := range status.Week {
+		// holidays are exceptions to the regular opening hours and are
+		// only valid on that specific date.
+		var validFrom, validThrough string
+		if day.IsHoliday {
+			validFrom = day.Date
+			validThrough = day.Date
+		}
+
+		// schema.org uses 00:00-00:00 to mark a day as closed.
+		if len(day.Frames) == 0 {
+			if day.IsHoliday {
+				doc.OpeningHoursSpecification = append(doc.OpeningHoursSpecification, schemaOrgOpeningHours{
+					Type:         "OpeningHoursSpecification",
+					DayOfWeek:    "https://schema.org/" + day.Weekday,
+					Opens:        "00:00",
+					Closes:       "00:00",
+					ValidFrom:    validFrom,
+					ValidThrough: validThrough,
+				})
+			}
+
+			continue
+		}
+
+		for _, frame := range day.Frames {
+			doc.OpeningHoursSpecification = append(doc.OpeningHoursSpecification, schemaOrgOpeningHours{
+				Type:         "OpeningHoursSpecification",
+				DayOfWeek:    "https://schema.org/" + day.Weekday,
+				Opens:        frame.From.Format("15:04"),
+				Closes:       frame.To.Format("15:04"),
+				ValidFrom:    validFrom,
+				ValidThrough: validThrough,
+			})
+		}
+	}
+
+	return doc
+}
+
+// writeCacheable writes result as JSON and adds caching headers that
+// allow clients and proxies to cache the response for at most maxAge.
+func writeCacheable(c echo.Context, contentType string, result any, maxAge time.Duration) error {
+	blob, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(blob)
+	etag := fmt.Sprintf(`"%s"`, hex.EncodeToString(hash[:16]))
+
+	maxAge = min(maxAge, maxPublicCacheAge)
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	header := c.Response().Header()
+	header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(math.Ceil(maxAge.Seconds()))))
+	header.Set("ETag", etag)
+
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.Blob(http.StatusOK, contentType, blob)
+}
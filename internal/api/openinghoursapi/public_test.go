@@ -0,0 +1,191 @@
+package openinghoursapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/labstack/echo/v4"
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	calendarv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1"
+	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1/calendarv1connect"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/internal/cfgspec"
+	"github.com/tierklinik-dobersberg/cis/internal/door"
+	"github.com/tierklinik-dobersberg/cis/internal/openinghours"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/fileprovider"
+)
+
+// fakeHolidays is a holiday service client that reports a fixed list of
+// public holidays and counts the number of queries. Any other method of
+// the holiday service panics.
+type fakeHolidays struct {
+	calendarv1connect.HolidayServiceClient
+
+	holidays []*calendarv1.PublicHoliday
+	calls    atomic.Int32
+}
+
+func (f *fakeHolidays) GetHoliday(_ context.Context, req *connect.Request[calendarv1.GetHolidayRequest]) (*connect.Response[calendarv1.GetHolidayResponse], error) {
+	f.calls.Add(1)
+
+	res := new(calendarv1.GetHolidayResponse)
+	for _, h := range f.holidays {
+		if strings.HasPrefix(h.Date, fmt.Sprintf("%04d-", req.Msg.Year)) {
+			res.Holidays = append(res.Holidays, h)
+		}
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+// newTestApp returns an application with opening hours on workdays from
+// 08:00 to 12:00 and a public holiday on 2024-01-01.
+func newTestApp(t *testing.T) (*app.App, *fakeHolidays) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	schema := new(runtime.ConfigSchema)
+	require.NoError(t, openinghours.AddToSchema(schema))
+	require.NoError(t, door.AddToSchema(schema))
+	schema.SetProvider(fileprovider.New(&conf.File{
+		Sections: []conf.Section{
+			{
+				Name: "OpeningHour",
+				Options: conf.Options{
+					{Name: "OnWeekday", Value: "Mon"},
+					{Name: "OnWeekday", Value: "Tue"},
+					{Name: "OnWeekday", Value: "Wed"},
+					{Name: "OnWeekday", Value: "Thu"},
+					{Name: "OnWeekday", Value: "Fri"},
+					{Name: "TimeRanges", Value: "08:00 - 12:00"},
+				},
+			},
+		},
+	}))
+
+	holidays := &fakeHolidays{
+		holidays: []*calendarv1.PublicHoliday{
+			{Date: "2024-01-01", LocalName: "Neujahr"},
+		},
+	}
+
+	cfg := cfgspec.Config{
+		TimeZone:           "Europe/Vienna",
+		PublicOpeningHours: true,
+	}
+
+	ohCtrl, err := openinghours.NewWithHolidays(ctx, cfg, schema, holidays)
+	require.NoError(t, err)
+
+	doorCtrl, err := door.NewDoorController(ctx, ohCtrl, schema)
+	require.NoError(t, err)
+
+	return &app.App{
+		Config: &app.Config{Config: cfg},
+		Door:   doorCtrl,
+	}, holidays
+}
+
+func TestPublicStatus(t *testing.T) {
+	ctx := context.Background()
+	a, holidays := newTestApp(t)
+
+	// Friday before new year, before the clinic opens.
+	now := time.Date(2023, 12, 29, 7, 0, 0, 0, a.Location())
+
+	res, validUntil, err := getPublicStatus(ctx, a, now)
+	require.NoError(t, err)
+
+	opens := time.Date(2023, 12, 29, 8, 0, 0, 0, a.Location())
+	assert.False(t, res.Open)
+	require.NotNil(t, res.OpensAt)
+	assert.True(t, opens.Equal(*res.OpensAt))
+	assert.True(t, opens.Equal(validUntil))
+
+	require.Len(t, res.Week, 7)
+	assert.Equal(t, "2023-12-29", res.Week[0].Date)
+	assert.Len(t, res.Week[0].Frames, 1)
+
+	// the public holiday is closed and reported by name.
+	assert.Equal(t, "2024-01-01", res.Week[3].Date)
+	assert.True(t, res.Week[3].IsHoliday)
+	assert.Equal(t, "Neujahr", res.Week[3].Holiday)
+	assert.Empty(t, res.Week[3].Frames)
+
+	assert.False(t, res.Week[4].IsHoliday)
+	assert.Len(t, res.Week[4].Frames, 1)
+
+	// while open, the response is valid until the frame ends.
+	now = time.Date(2024, 1, 2, 10, 0, 0, 0, a.Location())
+	res, validUntil, err = getPublicStatus(ctx, a, now)
+	require.NoError(t, err)
+
+	closes := time.Date(2024, 1, 2, 12, 0, 0, 0, a.Location())
+	assert.True(t, res.Open)
+	require.NotNil(t, res.ClosesAt)
+	assert.True(t, closes.Equal(*res.ClosesAt))
+	assert.True(t, closes.Equal(validUntil))
+	require.NotNil(t, res.NextOpening)
+	assert.True(t, time.Date(2024, 1, 3, 8, 0, 0, 0, a.Location()).Equal(res.NextOpening.From))
+
+	// public holidays are loaded once per year.
+	_, _, err = getPublicStatus(ctx, a, now)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, holidays.calls.Load(), int32(2))
+}
+
+func TestPublicStatusCaching(t *testing.T) {
+	a, _ := newTestApp(t)
+
+	e := echo.New()
+	SetupPublic(a, e.Group("/api/public/openinghours/"))
+
+	get := func(header map[string]string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/public/openinghours/v1/status", nil)
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := get(nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	var maxAge int
+	_, err := fmt.Sscanf(rec.Header().Get("Cache-Control"), "public, max-age=%d", &maxAge)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, maxAge, int(maxPublicCacheAge.Seconds()))
+
+	// unchanged responses are not sent again.
+	rec = get(map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// JSON-LD uses a different representation and ETag.
+	rec = get(map[string]string{echo.HeaderAccept: "application/ld+json"})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/ld+json; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	assert.Contains(t, rec.Body.String(), `"@context":"https://schema.org"`)
+}
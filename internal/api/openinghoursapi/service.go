@@ -0,0 +1,156 @@
+package openinghoursapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	cisv1 "github.com/tierklinik-dobersberg/cis/gen/go/tkd/cis/v1"
+	"github.com/tierklinik-dobersberg/cis/gen/go/tkd/cis/v1/cisv1connect"
+	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"github.com/tierklinik-dobersberg/cis/internal/openinghours"
+	"github.com/tierklinik-dobersberg/cis/pkg/daytime"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxUpcomingFrames is the maximum number of frames that may be requested
+// using UpcomingFrames.
+const maxUpcomingFrames = 100
+
+// Service implements cisv1connect.OpeningHoursServiceHandler.
+type Service struct {
+	cisv1connect.UnimplementedOpeningHoursServiceHandler
+
+	app *app.App
+}
+
+// NewService returns a new opening hours connect service.
+func NewService(a *app.App) *Service {
+	return &Service{app: a}
+}
+
+// ForDate implements cisv1connect.OpeningHoursServiceHandler.
+func (svc *Service) ForDate(ctx context.Context, req *connect.Request[cisv1.ForDateRequest]) (*connect.Response[cisv1.ForDateResponse], error) {
+	kind, err := frameKindFromProto(req.Msg.Kind)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	date := time.Now()
+	if req.Msg.Date != nil {
+		date = req.Msg.Date.AsTime()
+	}
+	date = date.In(svc.app.Location())
+
+	res := &cisv1.ForDateResponse{}
+
+	for _, oh := range svc.app.Door.ForDateOfKind(ctx, date, kind) {
+		res.OpeningHours = append(res.OpeningHours, &cisv1.OpeningHour{
+			Id:         oh.ID,
+			Kind:       frameKindToProto(oh.Kind),
+			Range:      dayTimeRangeToProto(oh.Range),
+			Holiday:    oh.Holiday,
+			OpenBefore: durationpb.New(oh.OpenBefore),
+			CloseAfter: durationpb.New(oh.CloseAfter),
+		})
+	}
+
+	for _, frame := range svc.app.Door.FramesForDate(ctx, date, kind) {
+		res.Frames = append(res.Frames, timeRangeToProto(frame))
+	}
+
+	for _, c := range svc.app.Door.ClosuresForDate(ctx, date) {
+		closure := &cisv1.Closure{
+			Id:     c.ID,
+			Reason: c.Reason,
+			AllDay: c.AllDay,
+		}
+		if !c.AllDay {
+			closure.Range = dayTimeRangeToProto(c.Range)
+		}
+
+		res.Closures = append(res.Closures, closure)
+	}
+
+	match := svc.app.Door.HolidaysForDate(ctx, date, kind)
+	res.Calendar = match.Calendar
+	for _, h := range match.Holidays {
+		res.Holidays = append(res.Holidays, &cisv1.Holiday{
+			Calendar: h.Calendar,
+			Name:     h.Name,
+		})
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+// UpcomingFrames implements cisv1connect.OpeningHoursServiceHandler.
+func (svc *Service) UpcomingFrames(ctx context.Context, req *connect.Request[cisv1.UpcomingFramesRequest]) (*connect.Response[cisv1.UpcomingFramesResponse], error) {
+	kind, err := frameKindFromProto(req.Msg.Kind)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	limit := int(req.Msg.Limit)
+	switch {
+	case limit == 0:
+		limit = 1
+	case limit < 0 || limit > maxUpcomingFrames:
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("limit must be between 1 and %d", maxUpcomingFrames))
+	}
+
+	from := time.Now()
+	if req.Msg.From != nil {
+		from = req.Msg.From.AsTime()
+	}
+	from = from.In(svc.app.Location())
+
+	res := &cisv1.UpcomingFramesResponse{}
+	for _, frame := range svc.app.Door.UpcomingFrames(ctx, from, kind, limit) {
+		res.Frames = append(res.Frames, timeRangeToProto(frame))
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func frameKindFromProto(kind cisv1.FrameKind) (openinghours.FrameKind, error) {
+	switch kind {
+	case cisv1.FrameKind_FRAME_KIND_UNSPECIFIED, cisv1.FrameKind_FRAME_KIND_BUSINESS:
+		return openinghours.KindBusiness, nil
+	case cisv1.FrameKind_FRAME_KIND_DOOR:
+		return openinghours.KindDoor, nil
+	case cisv1.FrameKind_FRAME_KIND_PHONE:
+		return openinghours.KindPhone, nil
+	}
+
+	return "", fmt.Errorf("invalid frame kind: %s", kind)
+}
+
+func frameKindToProto(kind openinghours.FrameKind) cisv1.FrameKind {
+	switch kind {
+	case openinghours.KindBusiness:
+		return cisv1.FrameKind_FRAME_KIND_BUSINESS
+	case openinghours.KindDoor:
+		return cisv1.FrameKind_FRAME_KIND_DOOR
+	case openinghours.KindPhone:
+		return cisv1.FrameKind_FRAME_KIND_PHONE
+	}
+
+	return cisv1.FrameKind_FRAME_KIND_UNSPECIFIED
+}
+
+func dayTimeRangeToProto(r daytime.Range) *cisv1.DayTimeRange {
+	return &cisv1.DayTimeRange{
+		Start: &cisv1.DayTime{Hour: int32(r.From[0]), Minute: int32(r.From[1])},
+		End:   &cisv1.DayTime{Hour: int32(r.To[0]), Minute: int32(r.To[1])},
+	}
+}
+
+func timeRangeToProto(tr daytime.TimeRange) *cisv1.TimeRange {
+	return &cisv1.TimeRange{
+		From: timestamppb.New(tr.From),
+		To:   timestamppb.New(tr.To),
+	}
+}
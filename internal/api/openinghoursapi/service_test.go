@@ -0,0 +1,72 @@
+package openinghoursapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cisv1 "github.com/tierklinik-dobersberg/cis/gen/go/tkd/cis/v1"
+	"github.com/tierklinik-dobersberg/cis/gen/go/tkd/cis/v1/cisv1connect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newTestApp(t)
+
+	mux := http.NewServeMux()
+	mux.Handle(cisv1connect.NewOpeningHoursServiceHandler(NewService(a)))
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cli := cisv1connect.NewOpeningHoursServiceClient(srv.Client(), srv.URL)
+
+	at := func(year int, month time.Month, day, hour int) *timestamppb.Timestamp {
+		return timestamppb.New(time.Date(year, month, day, hour, 0, 0, 0, a.Location()))
+	}
+
+	// a regular workday.
+	res, err := cli.ForDate(ctx, connect.NewRequest(&cisv1.ForDateRequest{
+		Date: at(2024, time.January, 2, 0),
+	}))
+	require.NoError(t, err)
+	require.Len(t, res.Msg.OpeningHours, 1)
+	assert.Equal(t, cisv1.FrameKind_FRAME_KIND_BUSINESS, res.Msg.OpeningHours[0].Kind)
+	assert.EqualValues(t, 8, res.Msg.OpeningHours[0].Range.Start.Hour)
+	require.Len(t, res.Msg.Frames, 1)
+	assert.True(t, at(2024, time.January, 2, 8).AsTime().Equal(res.Msg.Frames[0].From.AsTime()))
+	assert.True(t, at(2024, time.January, 2, 12).AsTime().Equal(res.Msg.Frames[0].To.AsTime()))
+	assert.Empty(t, res.Msg.Holidays)
+
+	// the public holiday is closed.
+	res, err = cli.ForDate(ctx, connect.NewRequest(&cisv1.ForDateRequest{
+		Date: at(2024, time.January, 1, 0),
+		Kind: cisv1.FrameKind_FRAME_KIND_DOOR,
+	}))
+	require.NoError(t, err)
+	assert.Empty(t, res.Msg.Frames)
+	require.Len(t, res.Msg.Holidays, 1)
+	assert.Equal(t, "Neujahr", res.Msg.Holidays[0].Name)
+
+	// upcoming frames skip the weekend and the public holiday.
+	upcoming, err := cli.UpcomingFrames(ctx, connect.NewRequest(&cisv1.UpcomingFramesRequest{
+		From:  at(2023, time.December, 29, 13),
+		Limit: 3,
+	}))
+	require.NoError(t, err)
+	require.Len(t, upcoming.Msg.Frames, 3)
+	for idx, day := range []int{2, 3, 4} {
+		assert.True(t, at(2024, time.January, day, 8).AsTime().Equal(upcoming.Msg.Frames[idx].From.AsTime()), "frame %d", idx)
+	}
+
+	_, err = cli.UpcomingFrames(ctx, connect.NewRequest(&cisv1.UpcomingFramesRequest{
+		Limit: maxUpcomingFrames + 1,
+	}))
+	assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+}
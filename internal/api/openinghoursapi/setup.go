@@ -1,12 +1,61 @@
 package openinghoursapi
 
 import (
+	"time"
+
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	"github.com/tierklinik-dobersberg/cis/internal/app"
+	"golang.org/x/time/rate"
 )
 
 func Setup(a *app.App, grp *echo.Group) {
 	router := app.NewRouter(grp, a)
 
+	// GET /api/openinghours/v1/opening-hours
 	GetOpeningHoursEndpoint(router)
+
+	// POST /api/openinghours/v1/preview
+	PreviewEndpoint(router)
+
+	// GET /api/openinghours/v1/on-call
+	GetOnCallEndpoint(router)
+
+	// GET /api/openinghours/v1/history
+	ListHistoryEndpoint(router)
+
+	// GET /api/openinghours/v1/history/evaluate
+	EvaluateHistoryEndpoint(router)
+
+	// GET /api/openinghours/v1/history/:version
+	GetRevisionEndpoint(router)
+}
+
+// SetupPublic registers the unauthenticated opening hours endpoints.
+// Requests are rate limited per client IP as configured by
+// PublicRateLimit.
+func SetupPublic(a *app.App, grp *echo.Group) {
+	perMinute := a.Config.PublicRateLimit
+	if perMinute <= 0 {
+		perMinute = 60
+	}
+
+	grp.Use(middleware.RateLimiter(
+		middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+			Rate:      rate.Limit(float64(perMinute) / 60),
+			Burst:     perMinute,
+			ExpiresIn: 3 * time.Minute,
+		}),
+	))
+
+	router := app.NewRouter(grp, a)
+
+	// GET /api/public/openinghours/v1/status
+	PublicStatusEndpoint(router)
+
+	// GET /api/public/openinghours/v1/calendar.ics
+	//
+	// calendar clients cannot authenticate so the feed is only
+	// available from the public API.
+	GetCalendarEndpoint(router)
 }
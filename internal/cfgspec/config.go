@@ -44,13 +44,41 @@ type Config struct {
 
 	DefaultOnCallDayStart   string
 	DefaultOnCallNightStart string
+
+	// PublicOpeningHours enables the unauthenticated opening hours
+	// endpoints that are meant to be consumed by the website, the
+	// phone system and calendar clients.
+	PublicOpeningHours bool
+	// PublicRateLimit is the number of requests per minute a single
+	// client may issue against the public endpoints.
+	PublicRateLimit int
+	// PublicBusinessName is used as the name of the business in the
+	// schema.org JSON-LD output of the public endpoints.
+	PublicBusinessName string
+
+	// OverrideCalendarID is the ID of a calendar in the calendar service
+	// whose tagged events override the opening hours.
+	OverrideCalendarID string
+	// OverrideCalDAVURL is the URL of a CalDAV calendar collection whose
+	// tagged events override the opening hours. It takes precedence over
+	// OverrideCalendarID.
+	OverrideCalDAVURL string
+	// OverridePollInterval defines how often override events are
+	// loaded.
+	OverridePollInterval time.Duration
+
+	// ConfigReadRoles and ConfigWriteRoles restrict access to the
+	// configuration schemas that control the entry door. See
+	// runtime.Schema.ReadRoles and runtime.Schema.WriteRoles.
+	ConfigReadRoles  []string
+	ConfigWriteRoles []string
 }
 
 // ConfigSpec defines the different configuration stanzas for the Config struct.
 var ConfigSpec = conf.SectionSpec{
 	{
 		Name:        "Secret",
-		Description: "Secret used to sign various data like session cookies and JWTs. If empty, a temporary secret is created.",
+		Description: "Secret used to sign various data like session cookies and JWTs and to encrypt secret configuration options. If empty, a temporary secret is created and secret options are stored unencrypted. Run \"cisd config rotate-secret --previous <old>\" after changing it.",
 		Type:        conf.StringType,
 	},
 	{
@@ -84,6 +112,49 @@ var ConfigSpec = conf.SectionSpec{
 		Type:        conf.StringType,
 		Description: "Default value for OnCallNightStart= in [OpeningHour]",
 	},
+	{
+		Name:        "PublicOpeningHours",
+		Type:        conf.BoolType,
+		Description: "Whether or not the unauthenticated opening hours endpoints at /api/public/, including the calendar.ics feed, should be enabled",
+		Default:     "no",
+	},
+	{
+		Name:        "PublicRateLimit",
+		Type:        conf.IntType,
+		Description: "The maximum number of requests per minute a single client may perform on the public endpoints",
+		Default:     "60",
+	},
+	{
+		Name:        "PublicBusinessName",
+		Type:        conf.StringType,
+		Description: "The name of the business used in the schema.org output of the public opening hours endpoints",
+	},
+	{
+		Name:        "OverrideCalendarID",
+		Type:        conf.StringType,
+		Description: "The ID of a calendar whose events tagged with #door-unlock or #closed override the opening hours",
+	},
+	{
+		Name:        "OverrideCalDAVURL",
+		Type:        conf.StringType,
+		Description: "The URL of a CalDAV calendar collection whose events tagged with #door-unlock or #closed override the opening hours. Credentials may be included in the URL. Takes precedence over OverrideCalendarID",
+	},
+	{
+		Name:        "OverridePollInterval",
+		Type:        conf.DurationType,
+		Description: "How often override events should be loaded from the calendar",
+		Default:     "5m",
+	},
+	{
+		Name:        "ConfigReadRoles",
+		Type:        conf.StringSliceType,
+		Description: "IDs or names of roles that may read the Door, OpeningHour and HolidayCalendar configuration. If empty, all authenticated users may read it",
+	},
+	{
+		Name:        "ConfigWriteRoles",
+		Type:        conf.StringSliceType,
+		Description: "IDs or names of roles that may change the Door, OpeningHour and HolidayCalendar configuration. If empty, all users that may read it may change it",
+	},
 	{
 		Name:        "TimeZone",
 		Type:        conf.StringType,
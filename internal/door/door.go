@@ -1,7 +1,5 @@
 package door
 
-// TODO(ppacher): move all the parsing work away from this package to schema or utils.
-
 import (
 	"context"
 	"errors"
@@ -9,7 +7,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/ppacher/system-conf/conf"
 	"github.com/tevino/abool"
 	"github.com/tierklinik-dobersberg/cis/internal/openinghours"
 	"github.com/tierklinik-dobersberg/cis/pkg/pkglog"
@@ -92,6 +89,15 @@ type Controller struct {
 
 	// door is the actual interface to control the door.
 	door Interfacer
+
+	// watchersLock protects access to watchers and lastStatus.
+	watchersLock sync.Mutex
+
+	// watchers holds all channels registered using Watch().
+	watchers map[chan Status]struct{}
+
+	// lastStatus is the status that has last been sent to watchers.
+	lastStatus Status
 }
 
 // NewDoorController returns a new door controller.
@@ -104,17 +110,22 @@ func NewDoorController(ctx context.Context, ohCtrl *openinghours.Controller, cs
 		door:            NoOp{},
 	}
 
-	cs.AddNotifier(dc, "Door")
-	cs.AddValidator(dc, "Door")
+	doors, err := runtime.Typed[DoorConfig](cs, "Door")
+	if err != nil {
+		return nil, err
+	}
+
+	doors.OnChange(dc.configure)
+	doors.AddValidator(dc.validate)
 
 	// initialize now
-	all, err := cs.All(ctx, "Door")
+	all, err := doors.All(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(all) > 0 {
-		if err := dc.NotifyChange(ctx, "create", all[0].ID, &all[0].Section); err != nil {
+		if err := dc.configure(ctx, runtime.ChangeTypeCreate, all[0].ID, &all[0].Value); err != nil {
 			return nil, err
 		}
 	}
@@ -130,28 +141,16 @@ func NewDoorController(ctx context.Context, ohCtrl *openinghours.Controller, cs
 	return dc, nil
 }
 
-func (dc *Controller) Validate(ctx context.Context, sec runtime.Section) error {
-	var cfg DoorConfig
-	if err := conf.DecodeSections([]conf.Section{sec.Section}, Spec, &cfg); err != nil {
-		return err
-	}
-
-	switch cfg.Type {
-	case "shelly-script":
-		if cfg.ShellyScriptURL == "" {
-			return fmt.Errorf("ShellScriptURL must be configured")
-		}
-
-		return nil
-
-	case "disabled":
-		return nil
-	}
+// validate ensures cfg describes a supported door interfacer.
+func (dc *Controller) validate(_ context.Context, _ string, cfg DoorConfig) error {
+	_, err := newInterfacer(cfg)
 
-	return fmt.Errorf("unsupport door interface type: %q", cfg.Type)
+	return err
 }
 
-func (dc *Controller) NotifyChange(ctx context.Context, changeType, id string, sec *conf.Section) error {
+// configure replaces the door interfacer whenever the door configuration
+// changes. cfg is nil if the configuration has been deleted.
+func (dc *Controller) configure(_ context.Context, _, _ string, cfg *DoorConfig) error {
 	dc.interfacerLock.Lock()
 	defer dc.interfacerLock.Unlock()
 
@@ -162,25 +161,17 @@ func (dc *Controller) NotifyChange(ctx context.Context, changeType, id string, s
 
 	dc.door = NoOp{}
 
-	switch changeType {
-	case "update", "create":
-		var cfg DoorConfig
-		if err := conf.DecodeSections([]conf.Section{*sec}, Spec, &cfg); err != nil {
-			return err
-		}
-
-		switch cfg.Type {
-		case "shelly-script":
-			dc.door = &ShellyScriptDoor{
-				url: cfg.ShellyScriptURL,
-			}
+	if cfg == nil {
+		return nil
+	}
 
-		case "disabled":
-			return nil
+	door, err := newInterfacer(*cfg)
+	if err != nil {
+		return err
+	}
 
-		default:
-			return fmt.Errorf("invalid door interface type: %q", cfg.Type)
-		}
+	if door != nil {
+		dc.door = door
 	}
 
 	return nil
@@ -198,7 +189,7 @@ func (dc *Controller) Overwrite(ctx context.Context, state State, untilTime time
 	{
 		dc.manualOverwrite = &stateOverwrite{
 			state:       state,
-			sessionUser: session.UserFromCtx(ctx).User.Id,
+			sessionUser: session.UserFromCtx(ctx).GetUser().GetId(),
 			until:       untilTime,
 		}
 	}
@@ -316,6 +307,9 @@ func (dc *Controller) resetDoor(ctx context.Context) {
 	dc.resetInProgress.Set()
 	defer dc.resetInProgress.UnSet()
 
+	state, until := dc.stateFor(ctx, time.Now().In(dc.Location()))
+	dc.publish(Status{State: state, Until: until, ResetInProgress: true})
+
 	// remove any manual overwrite when we do a reset.
 	dc.overwriteLock.Lock()
 	dc.manualOverwrite = nil
@@ -385,6 +379,8 @@ func (dc *Controller) scheduler() {
 			log.From(ctx).Errorf("BUG: a door reset is expected to be false")
 		}
 
+		dc.publish(Status{State: state, Until: until})
+
 		if until.IsZero() {
 			until = time.Now().Add(time.Minute * 5)
 		}
@@ -452,9 +448,15 @@ func (dc *Controller) stateFor(ctx context.Context, t time.Time) (State, time.Ti
 		return overwrite.state, overwrite.until
 	}
 
+	return DesiredState(ctx, dc.Controller, t)
+}
+
+// DesiredState returns the door state for the time t as defined by the
+// opening hours of ctrl. Manual overwrites are not taken into account.
+func DesiredState(ctx context.Context, ctrl *openinghours.Controller, t time.Time) (State, time.Time) {
 	// we need one frame because we might be in the middle
 	// of it or before it.
-	upcoming := dc.UpcomingFrames(ctx, t, 1)
+	upcoming := ctrl.UpcomingFrames(ctx, t, openinghours.KindDoor, 1)
 	if len(upcoming) == 0 {
 		return Locked, time.Time{} // forever locked as there are no frames ...
 	}
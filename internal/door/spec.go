@@ -41,8 +41,11 @@ var Spec = conf.SectionSpec{
 	{
 		Name:        "ShellyScriptURL",
 		Type:        conf.StringType,
-		Description: "The URL to start the provided shelly script. Used only if Type is set to Shelly Pro 2",
+		Description: "The URL to start the provided shelly script. Used only if Type is set to Shelly Pro 2. May include credentials",
 		Default:     "http://localhost/scripts/1/door",
+		Annotations: new(conf.Annotation).With(
+			runtime.Secret(),
+		),
 	},
 }
 
@@ -72,7 +75,7 @@ var testSpec = conf.SectionSpec{
 }
 
 func addDoorSchema(runtimeConfig *runtime.ConfigSchema) error {
-	return runtimeConfig.Register(runtime.Schema{
+	_, err := runtime.RegisterTyped[DoorConfig](runtimeConfig, runtime.Schema{
 		Name:        "Door",
 		DisplayName: "Door Controller",
 		Description: "Configure the door controller",
@@ -85,7 +88,7 @@ func addDoorSchema(runtimeConfig *runtime.ConfigSchema) error {
 				Name: "Test MQTT Door",
 				Spec: testSpec,
 				TestFunc: func(ctx context.Context, config, testConfig []conf.Option) (*runtime.TestResult, error) {
-					door, err := getTestDoor(ctx, runtimeConfig, config)
+					door, err := getTestDoor(config)
 					if err != nil {
 						return runtime.NewTestError(err), nil
 					}
@@ -114,39 +117,46 @@ func addDoorSchema(runtimeConfig *runtime.ConfigSchema) error {
 			},
 		},
 	})
+
+	return err
 }
 
-func getTestDoor(ctx context.Context, cs *runtime.ConfigSchema, config []conf.Option) (Interfacer, error) {
-	var cfg DoorConfig
-	if err := conf.DecodeSections(
-		conf.Sections{
-			{
-				Name:    "MQTT-Config",
-				Options: config,
-			},
-		},
-		Spec,
-		&cfg,
-	); err != nil {
+func getTestDoor(config []conf.Option) (Interfacer, error) {
+	cfg, err := runtime.DecodeOptions[DoorConfig](Spec, config)
+	if err != nil {
 		return nil, fmt.Errorf("failed to decode configuration: %w", err)
 	}
 
-	switch cfg.Type {
+	door, err := newInterfacer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if door == nil {
+		return nil, fmt.Errorf("door control is disabled")
+	}
+
+	return door, nil
+}
 
+// newInterfacer returns the door interfacer configured in cfg or nil if
+// door control is disabled.
+func newInterfacer(cfg DoorConfig) (Interfacer, error) {
+	switch cfg.Type {
 	case "shelly-script":
-		door := &ShellyScriptDoor{
-			url: cfg.ShellyScriptURL,
+		if cfg.ShellyScriptURL == "" {
+			return nil, fmt.Errorf("ShellyScriptURL must be configured")
 		}
 
-		return door, nil
+		return &ShellyScriptDoor{
+			url: cfg.ShellyScriptURL,
+		}, nil
 
 	case "disabled":
-		return nil, fmt.Errorf("door control is disabled")
-
-	default:
-		return nil, fmt.Errorf("invalid door interface type: %q", cfg.Type)
-
+		return nil, nil
 	}
+
+	return nil, fmt.Errorf("invalid door interface type: %q", cfg.Type)
 }
 
 func init() {
@@ -0,0 +1,63 @@
+package door
+
+import (
+	"context"
+	"time"
+)
+
+// Status describes the state of the entry door at a given time.
+type Status struct {
+	State           State
+	Until           time.Time
+	ResetInProgress bool
+}
+
+// Watch returns a channel that receives the door status whenever it
+// changes. Slow receivers only get the most recent status. The channel
+// is closed when ctx is cancelled.
+func (dc *Controller) Watch(ctx context.Context) <-chan Status {
+	ch := make(chan Status, 1)
+
+	dc.watchersLock.Lock()
+	if dc.watchers == nil {
+		dc.watchers = make(map[chan Status]struct{})
+	}
+	dc.watchers[ch] = struct{}{}
+	dc.watchersLock.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		dc.watchersLock.Lock()
+		defer dc.watchersLock.Unlock()
+
+		delete(dc.watchers, ch)
+		close(ch)
+	}()
+
+	return ch
+}
+
+// publish sends status to all watchers if it differs from the last
+// published status.
+func (dc *Controller) publish(status Status) {
+	dc.watchersLock.Lock()
+	defer dc.watchersLock.Unlock()
+
+	last := dc.lastStatus
+	if status.State == last.State && status.Until.Equal(last.Until) && status.ResetInProgress == last.ResetInProgress {
+		return
+	}
+	dc.lastStatus = status
+
+	for ch := range dc.watchers {
+		// drop a status that has not yet been received so
+		// the watcher gets the most recent one.
+		select {
+		case <-ch:
+		default:
+		}
+
+		ch <- status
+	}
+}
@@ -1,13 +1,17 @@
 package idm
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/bufbuild/connect-go"
 	idmv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/idm/v1"
 	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/idm/v1/idmv1connect"
 	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
+	"github.com/tierklinik-dobersberg/cis/runtime"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -59,3 +63,58 @@ func errFrom(err error) error {
 
 	return httperr.InternalError().SetInternal(err)
 }
+
+// RoleResolver returns a runtime.ReferenceResolver for runtime.RolesRef.
+// Supported value fields are "id" and "name".
+func (p *Provider) RoleResolver() runtime.ReferenceResolver {
+	return runtime.ReferenceResolverFunc(func(ctx context.Context, valueField string) ([]string, error) {
+		res, err := p.ListRoles(ctx, connect.NewRequest(&idmv1.ListRolesRequest{}))
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]string, 0, len(res.Msg.GetRoles()))
+		for _, role := range res.Msg.GetRoles() {
+			switch strings.ToLower(valueField) {
+			case "id":
+				values = append(values, role.GetId())
+			case "name":
+				values = append(values, role.GetName())
+			default:
+				return nil, fmt.Errorf("unsupported role field %q", valueField)
+			}
+		}
+
+		return values, nil
+	})
+}
+
+// UserResolver returns a runtime.ReferenceResolver for runtime.UsersRef.
+// Supported value fields are "id", "name" (the username) and
+// "displayName".
+func (p *Provider) UserResolver() runtime.ReferenceResolver {
+	return runtime.ReferenceResolverFunc(func(ctx context.Context, valueField string) ([]string, error) {
+		res, err := p.ListUsers(ctx, connect.NewRequest(&idmv1.ListUsersRequest{}))
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]string, 0, len(res.Msg.GetUsers()))
+		for _, profile := range res.Msg.GetUsers() {
+			user := profile.GetUser()
+
+			switch strings.ToLower(valueField) {
+			case "id":
+				values = append(values, user.GetId())
+			case "name", "username":
+				values = append(values, user.GetUsername())
+			case "displayname":
+				values = append(values, user.GetDisplayName())
+			default:
+				return nil, fmt.Errorf("unsupported user field %q", valueField)
+			}
+		}
+
+		return values, nil
+	})
+}
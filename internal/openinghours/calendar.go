@@ -0,0 +1,434 @@
+package openinghours
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+// PublicCalendar is the name of the built-in holiday calendar that holds
+// the public holidays of the configured country as reported by the
+// holiday service. Holiday definitions that do not reference a calendar
+// use this calendar.
+const PublicCalendar = "public"
+
+// CalendarDefinition describes a named holiday calendar that may be
+// referenced by opening hour definitions.
+type CalendarDefinition struct {
+	id string `option:"-"`
+
+	// Name is the unique name of the calendar.
+	Name string
+
+	// Description is a human readable description of the calendar.
+	Description string
+
+	// PublicHolidays includes the public holidays of the configured
+	// country.
+	PublicHolidays bool
+
+	// Region includes the regional holidays of a federal state in the
+	// ISO 3166-2 format (like AT-3).
+	Region string
+
+	// Dates holds custom holidays in the format of MM/DD, YYYY-MM-DD or
+	// a range of both (like 2025-02-03 - 2025-02-08). Each entry may be
+	// followed by a colon and a name (like 12/24: Christmas Eve).
+	Dates []string
+}
+
+// CalendarSpec describes the different configuration stanzas for the
+// CalendarDefinition struct.
+var CalendarSpec = conf.SectionSpec{
+	{
+		Name:        "Name",
+		Type:        conf.StringType,
+		Required:    true,
+		Description: "The unique name of the holiday calendar. The name " + PublicCalendar + " is reserved for the public holidays of the configured country.",
+	},
+	{
+		Name:        "Description",
+		Type:        conf.StringType,
+		Description: "A human readable description of the holiday calendar.",
+	},
+	{
+		Name:        "PublicHolidays",
+		Type:        conf.BoolType,
+		Default:     "no",
+		Description: "Whether or not the public holidays of the configured country are part of the calendar.",
+	},
+	{
+		Name:        "Region",
+		Type:        conf.StringType,
+		Description: "Includes the regional holidays of a federal state.",
+		Annotations: new(conf.Annotation).With(
+			runtime.OneOf(regionValues()...),
+		),
+	},
+	{
+		Name:        "Dates",
+		Type:        conf.StringSliceType,
+		Description: "A list of custom holidays in the format MM/DD (every year), YYYY-MM-DD or a range of those (FROM - TO). Each entry may be followed by a colon and the name of the holiday.",
+	},
+}
+
+// Holiday describes a holiday of a holiday calendar.
+type Holiday struct {
+	// Calendar is the name of the holiday calendar.
+	Calendar string `json:"calendar"`
+
+	// Name is the name of the holiday, if any.
+	Name string `json:"name,omitempty"`
+}
+
+// HolidayMatch describes the holidays at a date and which calendar
+// has been used to select the time ranges of a given kind.
+type HolidayMatch struct {
+	// Holidays holds all holidays at the date. The public holiday,
+	// if any, is always the first one.
+	Holidays []Holiday `json:"holidays"`
+
+	// Calendar is the name of the calendar that caused the time ranges
+	// to be selected. It is empty if regular or date specific time
+	// ranges are used.
+	Calendar string `json:"calendar,omitempty"`
+}
+
+// IsPublicHoliday reports whether the date is a public holiday.
+func (m HolidayMatch) IsPublicHoliday() bool {
+	return isPublic(m.Holidays)
+}
+
+// isPublic reports whether holidays contains a public holiday.
+func isPublic(holidays []Holiday) bool {
+	return len(holidays) > 0 && holidays[0].Calendar == PublicCalendar
+}
+
+// civilDate is a calendar date. A zero year is used for dates that
+// recur every year.
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func (cd civilDate) ordinal() int {
+	return cd.year*10000 + int(cd.month)*100 + cd.day
+}
+
+// calendarEntry is a single holiday or a range of holidays.
+type calendarEntry struct {
+	name string
+	from civilDate
+	to   civilDate
+}
+
+func (e calendarEntry) matches(date time.Time) bool {
+	year, month, day := date.Date()
+
+	// recurring entries are compared without the year and may wrap
+	// around the end of the year.
+	if e.from.year == 0 {
+		d := civilDate{month: month, day: day}.ordinal()
+		from, to := e.from.ordinal(), e.to.ordinal()
+
+		if from <= to {
+			return d >= from && d <= to
+		}
+
+		return d >= from || d <= to
+	}
+
+	d := civilDate{year: year, month: month, day: day}.ordinal()
+
+	return d >= e.from.ordinal() && d <= e.to.ordinal()
+}
+
+// holidayCalendar is a parsed CalendarDefinition.
+type holidayCalendar struct {
+	id      string
+	name    string
+	public  bool
+	entries []calendarEntry
+}
+
+// match returns the holiday of c at date. publicHoliday holds the name
+// of the public holiday at date, if any.
+func (c holidayCalendar) match(date time.Time, publicHoliday *Holiday) (Holiday, bool) {
+	if c.public && publicHoliday != nil {
+		return Holiday{Calendar: c.name, Name: publicHoliday.Name}, true
+	}
+
+	for _, e := range c.entries {
+		if e.matches(date) {
+			return Holiday{Calendar: c.name, Name: e.name}, true
+		}
+	}
+
+	return Holiday{}, false
+}
+
+func (def *CalendarDefinition) parse() (holidayCalendar, error) {
+	cal := holidayCalendar{
+		id:     def.id,
+		name:   def.Name,
+		public: def.PublicHolidays,
+	}
+
+	if def.Name == "" {
+		return cal, fmt.Errorf("option Name= is required")
+	}
+	if strings.EqualFold(def.Name, PublicCalendar) {
+		return cal, fmt.Errorf("calendar name %q is reserved", PublicCalendar)
+	}
+
+	if def.Region != "" {
+		entries, ok := regionalHolidays[strings.ToUpper(def.Region)]
+		if !ok {
+			return cal, fmt.Errorf("unsupported region: %q", def.Region)
+		}
+		cal.entries = append(cal.entries, entries...)
+	}
+
+	for _, d := range def.Dates {
+		entry, err := parseCalendarEntry(d)
+		if err != nil {
+			return cal, fmt.Errorf("option Dates=: %w", err)
+		}
+		cal.entries = append(cal.entries, entry)
+	}
+
+	if !cal.public && len(cal.entries) == 0 {
+		return cal, fmt.Errorf("calendar %q does not contain any holidays", def.Name)
+	}
+
+	return cal, nil
+}
+
+// parseCalendarEntry parses a calendar entry in the format of
+// "FROM [- TO] [: NAME]".
+func parseCalendarEntry(s string) (calendarEntry, error) {
+	var entry calendarEntry
+
+	value, name, _ := strings.Cut(s, ":")
+	entry.name = strings.TrimSpace(name)
+
+	// YYYY-MM-DD contains dashes itself so ranges must be separated
+	// by a dash with surrounding spaces.
+	from, to, isRange := strings.Cut(value, " - ")
+
+	var err error
+	entry.from, err = parseCivilDate(strings.TrimSpace(from))
+	if err != nil {
+		return entry, err
+	}
+
+	entry.to = entry.from
+	if isRange {
+		entry.to, err = parseCivilDate(strings.TrimSpace(to))
+		if err != nil {
+			return entry, err
+		}
+
+		if (entry.from.year == 0) != (entry.to.year == 0) {
+			return entry, fmt.Errorf("%q: range must not mix recurring and fixed dates", s)
+		}
+
+		if entry.from.year != 0 && entry.from.ordinal() > entry.to.ordinal() {
+			return entry, fmt.Errorf("%q: start date after end date", s)
+		}
+	}
+
+	return entry, nil
+}
+
+func parseCivilDate(s string) (civilDate, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return civilDate{year: t.Year(), month: t.Month(), day: t.Day()}, nil
+	}
+
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return civilDate{}, fmt.Errorf("invalid date: %q", s)
+	}
+
+	month, err := strconv.ParseInt(parts[0], 10, 0)
+	if err != nil || month < 1 || month > 12 {
+		return civilDate{}, fmt.Errorf("invalid month in %q", s)
+	}
+
+	day, err := strconv.ParseInt(parts[1], 10, 0)
+	if err != nil || day < 1 || day > 31 {
+		return civilDate{}, fmt.Errorf("invalid day in %q", s)
+	}
+
+	return civilDate{month: time.Month(month), day: int(day)}, nil
+}
+
+// holidaysAt returns all holidays at date. The public holiday is
+// returned first followed by the holidays of all configured calendars
+// sorted by calendar name.
+func (ctrl *Controller) holidaysAt(ctx context.Context, s *state, date time.Time) []Holiday {
+	var (
+		result []Holiday
+		public *Holiday
+	)
+
+	if holiday, ok := ctrl.publicHoliday(ctx, date); ok {
+		public = &holiday
+		result = append(result, holiday)
+	}
+
+	for _, cal := range s.Calendars {
+		if holiday, ok := cal.match(date, public); ok {
+			result = append(result, holiday)
+		}
+	}
+
+	return result
+}
+
+// calendarsMatch reports whether calendars references any of the
+// calendars in holidays. An empty list references the public calendar.
+func calendarsMatch(calendars []string, holidays []Holiday) (string, bool) {
+	for _, h := range holidays {
+		for _, c := range calendarNames(calendars) {
+			if h.Calendar == c {
+				return c, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// calendarNames returns the names of the calendars in calendars. An
+// empty list references the public calendar.
+func calendarNames(calendars []string) []string {
+	if len(calendars) == 0 {
+		return []string{PublicCalendar}
+	}
+
+	return calendars
+}
+
+// holidayCalendarNames returns the sorted names of all calendars
+// referenced by entries.
+func holidayCalendarNames[T any](entries []T, calendars func(T) []string) []string {
+	seen := make(map[string]struct{})
+	for _, e := range entries {
+		for _, name := range calendarNames(calendars(e)) {
+			seen[name] = struct{}{}
+		}
+	}
+
+	result := make([]string, 0, len(seen))
+	for name := range seen {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// filterCalendar returns all entries that reference the calendar name.
+func filterCalendar[T any](entries []T, name string, calendars func(T) []string) []T {
+	var result []T
+	for _, e := range entries {
+		for _, c := range calendarNames(calendars(e)) {
+			if c == name {
+				result = append(result, e)
+
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// validateCalendar validates a HolidayCalendar before it is created or
+// updated.
+func (ctrl *Controller) validateCalendar(ctx context.Context, id string, def CalendarDefinition) error {
+	def.id = id
+
+	_, err := def.parse()
+
+	return err
+}
+
+// applyCalendars applies changes of the HolidayCalendar schema.
+func (ctrl *Controller) applyCalendars(ctx context.Context, changes []runtime.TypedChange[CalendarDefinition]) error {
+	return ctrl.applyConfigChanges(ctx, "HolidayCalendar", sectionChanges(changes), func(s *state) error {
+		for _, change := range changes {
+			s.deleteCalendar(change.ID)
+
+			if change.Value == nil {
+				continue
+			}
+
+			def := *change.Value
+			def.id = change.ID
+
+			cal, err := def.parse()
+			if err != nil {
+				return err
+			}
+
+			s.Calendars = append(s.Calendars, cal)
+		}
+
+		sort.Slice(s.Calendars, func(i, j int) bool {
+			return s.Calendars[i].name < s.Calendars[j].name
+		})
+
+		return nil
+	})
+}
+
+func (s *state) deleteCalendar(id string) {
+	res := make([]holidayCalendar, 0, len(s.Calendars))
+	for _, cal := range s.Calendars {
+		if cal.id != id {
+			res = append(res, cal)
+		}
+	}
+
+	s.Calendars = res
+}
+
+// hasCalendar reports whether a calendar with name is configured.
+func (s *state) hasCalendar(name string) bool {
+	if name == PublicCalendar {
+		return true
+	}
+
+	for _, cal := range s.Calendars {
+		if cal.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func addHolidayCalendars(s *runtime.ConfigSchema) error {
+	return s.Register(runtime.Schema{
+		Name:        "HolidayCalendar",
+		DisplayName: "Feiertagskalender",
+		Description: "Named holiday calendars that may be referenced by opening hours",
+		Spec:        CalendarSpec,
+		Multi:       true,
+		SVGData:     `<path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M8 7V3m8 4V3m-9 8h10M5 21h14a2 2 0 002-2V7a2 2 0 00-2-2H5a2 2 0 00-2 2v12a2 2 0 002 2z" />`,
+		Annotations: new(conf.Annotation).With(
+			runtime.OverviewFields("Name", "Description", "PublicHolidays", "Region"),
+			runtime.Unique("Name"),
+		),
+	})
+}
@@ -0,0 +1,79 @@
+package openinghours
+
+import (
+	"github.com/tierklinik-dobersberg/cis/pkg/daytime"
+)
+
+// Closure describes a time range during which we are closed even if
+// there are opening hours configured.
+type Closure struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason,omitempty"`
+
+	// AllDay is set to true if the closure spans the whole day.
+	// Range is unset in this case.
+	AllDay bool `json:"allDay"`
+
+	// Range is the time range of the closure.
+	Range daytime.Range `json:"range"`
+
+	// Calendars holds the holiday calendars of holiday closures.
+	Calendars []string `json:"calendars,omitempty"`
+}
+
+// subtractClosures removes all closures from hours. Time ranges
+// that are partially covered by a closure are split and loose the
+// door padding at the edges that touch the closure. Closures that only
+// overlap the door padding clip the padding.
+func subtractClosures(hours []OpeningHour, closures []Closure) []OpeningHour {
+	for _, c := range closures {
+		if c.AllDay {
+			return nil
+		}
+
+		result := make([]OpeningHour, 0, len(hours))
+
+		for _, oh := range hours {
+			closedFrom := c.Range.From.AsDuration()
+			closedTo := c.Range.To.AsDuration()
+
+			// the closure does not touch the opening hour including
+			// the door padding at all.
+			if closedTo <= oh.EffectiveOpen() || closedFrom >= oh.EffectiveClose() {
+				result = append(result, oh)
+
+				continue
+			}
+
+			// keep the part before the closure.
+			if closedFrom > oh.From.AsDuration() {
+				before := oh
+				if closedFrom < oh.To.AsDuration() {
+					before.To = c.Range.From
+					before.CloseAfter = 0
+				} else {
+					before.CloseAfter = closedFrom - oh.To.AsDuration()
+				}
+
+				result = append(result, before)
+			}
+
+			// keep the part after the closure.
+			if closedTo < oh.To.AsDuration() {
+				after := oh
+				if closedTo > oh.From.AsDuration() {
+					after.From = c.Range.To
+					after.OpenBefore = 0
+				} else {
+					after.OpenBefore = oh.From.AsDuration() - closedTo
+				}
+
+				result = append(result, after)
+			}
+		}
+
+		hours = result
+	}
+
+	return hours
+}
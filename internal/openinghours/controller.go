@@ -2,17 +2,13 @@ package openinghours
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"sort"
 	"sync"
 	"time"
 
-	"github.com/bufbuild/connect-go"
 	"github.com/ppacher/system-conf/conf"
-	calendarv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1"
 	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1/calendarv1connect"
-	commonv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/common/v1"
 	"github.com/tierklinik-dobersberg/apis/pkg/discovery/consuldiscover"
 	"github.com/tierklinik-dobersberg/apis/pkg/discovery/wellknown"
 	"github.com/tierklinik-dobersberg/cis/internal/cfgspec"
@@ -23,6 +19,11 @@ import (
 
 var log = pkglog.New("openinghours")
 
+// maxLookaheadDays is the maximum number of days UpcomingFrames
+// searches for frames. It prevents endless loops if no opening hours
+// are configured at all.
+const maxLookaheadDays = 366
+
 type (
 	// ChangeNotifyFunc can be registered at the Controller to get notified
 	// when new opening hours have been configured.
@@ -41,17 +42,32 @@ type (
 
 		holidays calendarv1connect.HolidayServiceClient
 
+		// publicHolidays caches the public holidays per year so
+		// looking up many days does not query the holiday service
+		// for each day.
+		publicHolidays holidayCache
+
 		state *state
+
+		// events holds the overrides created from calendar events.
+		events eventOverrides
+
+		// sections holds the raw configuration sections that
+		// make up state.
+		sections map[string]SnapshotSection
+
+		// historyLock protects access to history and serializes
+		// configuration changes so revisions are recorded in the
+		// order the changes have been applied. It must be acquired
+		// before rw.
+		historyLock sync.Mutex
+		history     HistoryDatabase
 	}
 )
 
-// New returns a new opening hour controller.
+// New returns a new opening hour controller that queries the holiday
+// service found in the consul service catalog.
 func New(ctx context.Context, cfg cfgspec.Config, globalSchema *runtime.ConfigSchema) (*Controller, error) {
-	loc, err := time.LoadLocation(cfg.TimeZone)
-	if err != nil {
-		return nil, fmt.Errorf("option Location: %w", err)
-	}
-
 	disc, err := consuldiscover.NewFromEnv()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get consul service catalog: %w", err)
@@ -62,110 +78,230 @@ func New(ctx context.Context, cfg cfgspec.Config, globalSchema *runtime.ConfigSc
 		return nil, fmt.Errorf("failed to get holiday service client: %w", err)
 	}
 
+	return NewWithHolidays(ctx, cfg, globalSchema, holidays)
+}
+
+// NewWithHolidays returns a new opening hour controller that uses
+// holidays to query public holidays.
+func NewWithHolidays(ctx context.Context, cfg cfgspec.Config, globalSchema *runtime.ConfigSchema, holidays calendarv1connect.HolidayServiceClient) (*Controller, error) {
+	loc, err := time.LoadLocation(cfg.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("option Location: %w", err)
+	}
+
+	defaultOnCall, err := (&Definition{
+		id:               "default",
+		OnCallDayStart:   cfg.DefaultOnCallDayStart,
+		OnCallNightStart: cfg.DefaultOnCallNightStart,
+	}).onCall()
+	if err != nil {
+		return nil, fmt.Errorf("invalid default on-call configuration: %w", err)
+	}
+
 	ctrl := &Controller{
 		location: loc,
 		country:  cfg.Country,
 		holidays: holidays,
+		sections: make(map[string]SnapshotSection),
 		state: &state{
-			Regular:           make(map[time.Weekday][]OpeningHour),
-			DateSpecific:      make(map[string][]OpeningHour),
-			defaultCloseAfter: cfg.DefaultCloseAfter,
-			defaultOpenBefore: cfg.DefaultOpenBefore,
+			Regular:            make(map[time.Weekday][]OpeningHour),
+			DateSpecific:       make(map[string][]OpeningHour),
+			ClosedRegular:      make(map[time.Weekday][]Closure),
+			ClosedDateSpecific: make(map[string][]Closure),
+			OnCallRegular:      make(map[time.Weekday][]onCallStart),
+			OnCallDateSpecific: make(map[string][]onCallStart),
+			defaultOnCall:      defaultOnCall,
+			defaultCloseAfter:  cfg.DefaultCloseAfter,
+			defaultOpenBefore:  cfg.DefaultOpenBefore,
 		},
 	}
 
-	globalSchema.AddValidator(ctrl, "OpeningHour")
-	globalSchema.AddNotifier(ctrl, "OpeningHour")
+	if err := ctrl.listen(ctx, globalSchema); err != nil {
+		return nil, err
+	}
+
+	return ctrl, nil
+}
 
-	sections, err := globalSchema.All(ctx, "OpeningHour")
+// listen registers the controller for changes of holiday calendars and
+// opening hours at schema and loads all existing instances.
+func (ctrl *Controller) listen(ctx context.Context, schema *runtime.ConfigSchema) error {
+	calendars, err := runtime.Typed[CalendarDefinition](schema, "HolidayCalendar")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get existing configuration: %w", err)
+		return err
 	}
-	for _, def := range sections {
-		if err := ctrl.NotifyChange(ctx, "create", def.ID, &def.Section); err != nil {
-			return nil, fmt.Errorf("failed to create opening hour %s: %w", def.ID, err)
-		}
+
+	hours, err := runtime.Typed[Definition](schema, "OpeningHour")
+	if err != nil {
+		return err
 	}
 
-	return ctrl, nil
-}
+	// holiday calendars must be loaded before any opening hours
+	// reference them.
+	calendars.AddValidator(ctrl.validateCalendar)
+	calendars.OnBatchChange(ctrl.applyCalendars)
 
-func decodeOpeningHour(sec *conf.Section) (Definition, error) {
-	var entry Definition
+	existingCalendars, err := calendars.All(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get existing holiday calendars: %w", err)
+	}
+	if err := ctrl.applyCalendars(ctx, createChanges(existingCalendars)); err != nil {
+		return fmt.Errorf("failed to create holiday calendars: %w", err)
+	}
 
-	if sec != nil {
-		if err := conf.DecodeSections(conf.Sections{*sec}, Spec, &entry); err != nil {
-			return entry, err
-		}
-		if err := entry.Validate(); err != nil {
-			return entry, err
+	hours.AddBatchValidator(ctrl.validateOpeningHours)
+	hours.OnBatchChange(ctrl.applyOpeningHours)
+
+	existingHours, err := hours.All(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get existing configuration: %w", err)
+	}
+	if err := ctrl.applyOpeningHours(ctx, createChanges(existingHours)); err != nil {
+		return fmt.Errorf("failed to create opening hours: %w", err)
+	}
+
+	return nil
+}
+
+// createChanges returns a create change for each of sections.
+func createChanges[T any](sections []runtime.TypedSection[T]) []runtime.TypedChange[T] {
+	changes := make([]runtime.TypedChange[T], len(sections))
+	for idx := range sections {
+		changes[idx] = runtime.TypedChange[T]{
+			Type:    runtime.ChangeTypeCreate,
+			ID:      sections[idx].ID,
+			Value:   &sections[idx].Value,
+			Options: sections[idx].Options,
 		}
 	}
 
-	return entry, nil
+	return changes
 }
 
-func (ctrl *Controller) Validate(ctx context.Context, sec runtime.Section) error {
-	openingHour, err := decodeOpeningHour(&sec.Section)
+// validateOpeningHours validates changes of the OpeningHour schema. All
+// changes of a batch are validated together so overlapping definitions
+// can be replaced at once.
+func (ctrl *Controller) validateOpeningHours(ctx context.Context, changes []runtime.TypedChange[Definition]) error {
+	deletes, defs, err := openingHourChanges(changes)
 	if err != nil {
 		return err
 	}
-	openingHour.id = sec.ID
 
 	ctrl.rw.RLock()
 	defer ctrl.rw.RUnlock()
 
-	testState := ctrl.state.clone()
-
-	if openingHour.id != "" {
-		if err := testState.deleteOpeningHour(ctx, openingHour.id); err != nil {
-			return err
+	for _, def := range defs {
+		for _, name := range def.HolidayCalendar {
+			if !ctrl.state.hasCalendar(name) {
+				return fmt.Errorf("unknown holiday calendar %q", name)
+			}
 		}
 	}
 
-	if err := testState.addOpeningHours(ctx, openingHour); err != nil {
-		return err
-	}
-
-	return nil
+	return ctrl.state.clone().applyBatch(ctx, deletes, defs)
 }
 
-func (ctrl *Controller) NotifyChange(ctx context.Context, changeType string, id string, sec *conf.Section) error {
-	openingHour, err := decodeOpeningHour(sec)
+// applyOpeningHours applies changes of the OpeningHour schema.
+// Subscribers are notified once after all changes have been applied.
+func (ctrl *Controller) applyOpeningHours(ctx context.Context, changes []runtime.TypedChange[Definition]) error {
+	deletes, defs, err := openingHourChanges(changes)
 	if err != nil {
 		return err
 	}
-	openingHour.id = id
 
-	ctrl.rw.Lock()
-	defer ctrl.rw.Unlock()
+	return ctrl.applyConfigChanges(ctx, "OpeningHour", sectionChanges(changes), func(s *state) error {
+		return s.applyBatch(ctx, deletes, defs)
+	})
+}
 
-	var errs []error
+// openingHourChanges returns the IDs of all opening hours that are
+// removed or replaced by changes and the validated new definitions.
+func openingHourChanges(changes []runtime.TypedChange[Definition]) ([]string, []Definition, error) {
+	var (
+		deletes []string
+		defs    []Definition
+	)
+
+	for _, change := range changes {
+		if change.Type != runtime.ChangeTypeCreate {
+			deletes = append(deletes, change.ID)
+		}
 
-	newState := ctrl.state.clone()
+		if change.Value == nil {
+			continue
+		}
+
+		def := *change.Value
+		def.id = change.ID
 
-	// we delete for "delete" and "update".
-	if changeType != "create" {
-		if err := newState.deleteOpeningHour(ctx, openingHour.id); err != nil {
-			errs = append(errs, fmt.Errorf("failed to delete: %w", err))
+		if err := def.Validate(); err != nil {
+			return nil, nil, err
 		}
+
+		defs = append(defs, def)
 	}
 
-	// we "create" for "create" and "update".
-	if changeType != "delete" {
-		if err := newState.addOpeningHours(ctx, openingHour); err != nil {
-			errs = append(errs, fmt.Errorf("failed to create: %w", err))
+	return deletes, defs, nil
+}
+
+// sectionChanges converts changes into sectionChanges for recording.
+func sectionChanges[T any](changes []runtime.TypedChange[T]) []sectionChange {
+	sections := make([]sectionChange, len(changes))
+	for idx, change := range changes {
+		sections[idx] = sectionChange{
+			changeType: change.Type,
+			id:         change.ID,
+		}
+
+		if change.Value != nil {
+			sections[idx].options = change.Options
 		}
 	}
 
-	if err := errors.Join(errs...); err != nil {
+	return sections
+}
+
+// sectionChange describes a change of a single configuration section.
+// options is nil for deletes.
+type sectionChange struct {
+	changeType string
+	id         string
+	options    []conf.Option
+}
+
+// applyConfigChanges applies changes of configuration sections to a copy
+// of the current state, notifies all subscribers and records a new
+// revision if history recording is enabled.
+func (ctrl *Controller) applyConfigChanges(ctx context.Context, schema string, sections []sectionChange, apply func(s *state) error) error {
+	ctrl.historyLock.Lock()
+	ctrl.rw.Lock()
+
+	newState := ctrl.state.clone()
+
+	if err := apply(newState); err != nil {
+		ctrl.rw.Unlock()
+		ctrl.historyLock.Unlock()
+
 		return err
 	}
 
 	ctrl.state = newState
 
+	changes := make([]Change, len(sections))
+	for idx, sc := range sections {
+		changes[idx] = ctrl.updateSection(schema, sc.changeType, sc.id, sc.options)
+	}
+
+	snapshot := ctrl.snapshot()
+	notifier := ctrl.notifier
+
+	ctrl.rw.Unlock()
+
+	ctrl.recordRevision(ctx, changes, snapshot)
+	ctrl.historyLock.Unlock()
+
 	// notify all subscribers that we got new opening hours
-	for _, fn := range ctrl.notifier {
+	for _, fn := range notifier {
 		fn()
 	}
 
@@ -187,26 +323,23 @@ func (ctrl *Controller) AddOpeningHours(ctx context.Context, timeRanges ...Defin
 	return nil
 }
 
-func (ctrl *Controller) UpcomingFrames(ctx context.Context, dateTime time.Time, limit int) []daytime.TimeRange {
+// UpcomingFrames returns up to limit frames of the given kind that either
+// cover dateTime or start after it.
+func (ctrl *Controller) UpcomingFrames(ctx context.Context, dateTime time.Time, kind FrameKind, limit int) []daytime.TimeRange {
 	ctrl.rw.RLock()
 	defer ctrl.rw.RUnlock()
 
 	var result []daytime.TimeRange
 
-	for len(result) < limit {
-		ranges := ctrl.forDate(ctx, dateTime)
-
-		if len(ranges) == 0 {
-			break
-		}
+	dateTime = dateTime.In(ctrl.location)
+	for days := 0; len(result) < limit && days < maxLookaheadDays; days++ {
+		ranges := ctrl.framesFromState(ctx, ctrl.state, dateTime, kind)
 
 		// Find the first frame that's after or covers t
 		var idx int
 		found := false
 		for idx = range ranges {
-			tr := ranges[idx].At(dateTime, ctrl.location)
-			tr.From = tr.From.Add(-ranges[idx].OpenBefore)
-			tr.To = tr.To.Add(ranges[idx].CloseAfter)
+			tr := ranges[idx]
 
 			if tr.From.After(dateTime) || tr.Covers(dateTime) {
 				found = true
@@ -217,17 +350,12 @@ func (ctrl *Controller) UpcomingFrames(ctx context.Context, dateTime time.Time,
 
 		if found {
 			// all frames following idx are up-coming.
-			for _, d := range ranges[idx:] {
-				tr := d.At(dateTime, ctrl.location)
-				tr.From = tr.From.Add(-d.OpenBefore)
-				tr.To = tr.To.Add(d.CloseAfter)
-				result = append(result, *tr)
-			}
+			result = append(result, ranges[idx:]...)
 		}
 
-		// proceed to the next week day
-		dateTime = dateTime.Add(24 * time.Hour)
-		dateTime = time.Date(dateTime.Year(), dateTime.Month(), dateTime.Day(), 0, 0, 0, 0, dateTime.Location())
+		// proceed to midnight of the next day. Days are not always
+		// 24 hours long so we must not add a fixed duration here.
+		dateTime = time.Date(dateTime.Year(), dateTime.Month(), dateTime.Day()+1, 0, 0, 0, 0, ctrl.location)
 	}
 
 	// truncate the result to the exact size requested
@@ -239,50 +367,203 @@ func (ctrl *Controller) UpcomingFrames(ctx context.Context, dateTime time.Time,
 	return result
 }
 
+// ForDate returns the business hours at date.
 func (ctrl *Controller) ForDate(ctx context.Context, date time.Time) []OpeningHour {
+	return ctrl.ForDateOfKind(ctx, date, KindBusiness)
+}
+
+// ForDateOfKind returns all configured time ranges of kind at date.
+// Note that the result for KindDoor only includes explicitly configured
+// door ranges, use FramesForDate to get all door frames including the
+// padding of business hours.
+func (ctrl *Controller) ForDateOfKind(ctx context.Context, date time.Time, kind FrameKind) []OpeningHour {
+	ctrl.rw.RLock()
+	defer ctrl.rw.RUnlock()
+
+	return ctrl.forDateFromState(ctx, ctrl.state, date).hours[kind]
+}
+
+// ClosuresForDate returns all closures that apply at date.
+func (ctrl *Controller) ClosuresForDate(ctx context.Context, date time.Time) []Closure {
 	ctrl.rw.RLock()
 	defer ctrl.rw.RUnlock()
 
-	return ctrl.forDate(ctx, date)
+	return ctrl.forDateFromState(ctx, ctrl.state, date).closures
+}
+
+// HolidaysForDate returns all holidays at date and the calendar that
+// has been used to select the time ranges of kind.
+func (ctrl *Controller) HolidaysForDate(ctx context.Context, date time.Time, kind FrameKind) HolidayMatch {
+	ctrl.rw.RLock()
+	defer ctrl.rw.RUnlock()
+
+	day := ctrl.forDateFromState(ctx, ctrl.state, date)
+
+	return HolidayMatch{
+		Holidays: day.holidays,
+		Calendar: day.calendars[kind],
+	}
+}
+
+// FramesForDate returns the effective time frames of kind at date.
+// Door frames include the padded business hours as well as the
+// explicitly configured door ranges.
+func (ctrl *Controller) FramesForDate(ctx context.Context, date time.Time, kind FrameKind) []daytime.TimeRange {
+	ctrl.rw.RLock()
+	defer ctrl.rw.RUnlock()
+
+	return ctrl.framesFromState(ctx, ctrl.state, date, kind)
+}
+
+func (ctrl *Controller) framesFromState(ctx context.Context, s *state, date time.Time, kind FrameKind) []daytime.TimeRange {
+	date = date.In(ctrl.location)
+	byKind := ctrl.forDateFromState(ctx, s, date).hours
+
+	if kind != KindDoor {
+		result := make([]daytime.TimeRange, len(byKind[kind]))
+		for idx, oh := range byKind[kind] {
+			result[idx] = *oh.At(date, ctrl.location)
+		}
+
+		return result
+	}
+
+	// the door is unlocked during the padded business hours and
+	// explicitly configured door ranges.
+	result := make([]daytime.TimeRange, 0, len(byKind[KindBusiness])+len(byKind[KindDoor]))
+	for _, oh := range byKind[KindBusiness] {
+		result = append(result, oh.DoorRange(date, ctrl.location))
+	}
+	for _, oh := range byKind[KindDoor] {
+		result = append(result, *oh.At(date, ctrl.location))
+	}
+
+	return mergeTimeRanges(result)
 }
 
-func (ctrl *Controller) forDate(ctx context.Context, date time.Time) []OpeningHour {
+// dayState holds the time ranges and closures of a single day.
+type dayState struct {
+	// hours holds the time ranges indexed by frame kind with all
+	// closures already subtracted.
+	hours map[FrameKind][]OpeningHour
+
+	// calendars holds the name of the holiday calendar that caused
+	// the time ranges of a kind to be selected.
+	calendars map[FrameKind]string
+
+	// holidays holds all holidays of the day.
+	holidays []Holiday
+
+	// closures holds all closures of the day.
+	closures []Closure
+}
+
+// forDateFromState returns the time ranges and closures configured in s
+// at date. Date specific ranges overwrite holiday ranges which overwrite
+// regular ranges on a per-kind basis. If date is a holiday in multiple
+// calendars the public holiday calendar wins over all other calendars
+// which are evaluated in alphabetical order. Regular ranges are never
+// used on public holidays. Closures and door unlocks from calendar events
+// are applied on top.
+func (ctrl *Controller) forDateFromState(ctx context.Context, s *state, date time.Time) dayState {
 	date = date.In(ctrl.location)
 
 	log := log.From(ctx)
 	key := fmt.Sprintf("%02d/%02d", date.Month(), date.Day())
 
-	// First we check for date specific overwrites ...
-	ranges, ok := ctrl.state.DateSpecific[key]
-	if ok {
-		return ranges
+	holidays := ctrl.holidaysAt(ctx, s, date)
+
+	// closures apply on top of each other
+	closures := append([]Closure(nil), s.ClosedDateSpecific[key]...)
+	for _, c := range s.ClosedHoliday {
+		if _, ok := calendarsMatch(c.Calendars, holidays); ok {
+			closures = append(closures, c)
+		}
+	}
+	if !isPublic(holidays) {
+		closures = append(closures, s.ClosedRegular[date.Weekday()]...)
 	}
 
-	// Check if we need to use holiday ranges ...
-	isHoliday, err := ctrl.holidays.IsHoliday(ctx, connect.NewRequest(&calendarv1.IsHolidayRequest{
-		Date: &commonv1.Date{
-			Year:  int64(date.Year()),
-			Month: commonv1.Month(date.Month()),
-			Day:   int32(date.Day()),
-		},
-	}))
+	// calendar events override the configuration for a specific date.
+	eventKey := date.Format("2006-01-02")
+	closures = append(closures, ctrl.events.closures[eventKey]...)
 
-	if err != nil {
-		log.Errorf("failed to load holidays: %s", err.Error())
-	} else if isHoliday.Msg.IsHoliday {
-		return ctrl.state.Holiday
+	result := dayState{
+		hours:     make(map[FrameKind][]OpeningHour, len(FrameKinds)),
+		calendars: make(map[FrameKind]string, len(FrameKinds)),
+		holidays:  holidays,
+		closures:  closures,
 	}
 
-	// Finally use the regular opening hours
-	ranges, ok = ctrl.state.Regular[date.Weekday()]
-	if ok {
-		return ranges
+	for _, kind := range FrameKinds {
+		var (
+			ranges   []OpeningHour
+			calendar string
+		)
+
+		// First we check for date specific overwrites ...
+		if dateSpecific := filterKind(s.DateSpecific[key], kind); len(dateSpecific) > 0 {
+			ranges = dateSpecific
+		} else {
+			ranges, calendar = holidayRanges(filterKind(s.Holiday, kind), holidays)
+
+			switch {
+			case calendar != "":
+
+			// there are no holiday ranges on a public holiday so we're closed.
+			case isPublic(holidays):
+				calendar = PublicCalendar
+
+			// Finally use the regular opening hours
+			default:
+				ranges = filterKind(s.Regular[date.Weekday()], kind)
+			}
+		}
+
+		result.hours[kind] = subtractClosures(ranges, closures)
+		result.calendars[kind] = calendar
+	}
+
+	// door unlocks from calendar events win over closures.
+	if doors := ctrl.events.doors[eventKey]; len(doors) > 0 {
+		result.hours[KindDoor] = append(result.hours[KindDoor], doors...)
+		sort.Sort(OpeningHourSlice(result.hours[KindDoor]))
 	}
 
-	// There are no ranges for that day!
-	log.V(4).Logf("No opening hour ranges found for %s", date)
+	if len(result.hours[KindBusiness]) == 0 {
+		// There are no ranges for that day!
+		log.V(4).Logf("No opening hour ranges found for %s", date)
+	}
 
-	return nil
+	return result
+}
+
+// holidayRanges returns the ranges of the first holiday in holidays that
+// has matching ranges together with the name of the holiday calendar.
+func holidayRanges(ranges []OpeningHour, holidays []Holiday) ([]OpeningHour, string) {
+	for _, h := range holidays {
+		result := filterCalendar(ranges, h.Calendar, func(oh OpeningHour) []string { return oh.Calendars })
+		if len(result) > 0 {
+			return result, h.Calendar
+		}
+	}
+
+	return nil, ""
+}
+
+// publicHoliday returns the public holiday at date, if any. Errors are
+// logged and reported as a regular day.
+func (ctrl *Controller) publicHoliday(ctx context.Context, date time.Time) (Holiday, bool) {
+	holidays, err := ctrl.publicHolidays.year(ctx, ctrl.holidays, date.Year())
+	if err != nil {
+		log.From(ctx).Errorf("failed to load holidays: %s", err.Error())
+
+		return Holiday{}, false
+	}
+
+	holiday, ok := holidays[date.Format("2006-01-02")]
+
+	return holiday, ok
 }
 
 // Location returns the location the controller is configured for.
@@ -308,18 +589,64 @@ func (ctrl *Controller) OnChange(fn ChangeNotifyFunc) {
 func sortAndValidate(slice []OpeningHour) error {
 	sort.Sort(OpeningHourSlice(slice))
 
-	// it's already guaranteed that each To is after the respective From
-	// value (see utils.ParseDayTime) and the slice is sorted by asc From
-	// time. Therefore, we only need to check if there's a To time that's
-	// after the From time of the next time range.
-	for i := 0; i < len(slice)-1; i++ {
-		current := slice[i]
-		next := slice[i+1]
+	// time ranges of different kinds may overlap.
+	for _, kind := range FrameKinds {
+		ranges := filterKind(slice, kind)
 
-		if current.EffectiveClose() >= next.EffectiveOpen() {
-			return fmt.Errorf("overlapping time frames %s and %s", current, next)
+		// it's already guaranteed that each To is after the respective From
+		// value (see utils.ParseDayTime) and the slice is sorted by asc From
+		// time. Therefore, we only need to check if there's a To time that's
+		// after the From time of the next time range.
+		for i := 0; i < len(ranges)-1; i++ {
+			current := ranges[i]
+			next := ranges[i+1]
+
+			if current.EffectiveClose() >= next.EffectiveOpen() {
+				return fmt.Errorf("overlapping time frames %s and %s", current, next)
+			}
 		}
 	}
 
 	return nil
 }
+
+// filterKind returns all opening hours from slice that are of kind.
+func filterKind(slice []OpeningHour, kind FrameKind) []OpeningHour {
+	var result []OpeningHour
+	for _, oh := range slice {
+		if oh.Kind == kind {
+			result = append(result, oh)
+		}
+	}
+
+	return result
+}
+
+// mergeTimeRanges sorts ranges and merges overlapping or adjacent
+// time ranges.
+func mergeTimeRanges(ranges []daytime.TimeRange) []daytime.TimeRange {
+	if len(ranges) == 0 {
+		return ranges
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].From.Before(ranges[j].From)
+	})
+
+	result := []daytime.TimeRange{ranges[0]}
+	for _, tr := range ranges[1:] {
+		last := &result[len(result)-1]
+
+		if tr.From.After(last.To) {
+			result = append(result, tr)
+
+			continue
+		}
+
+		if tr.To.After(last.To) {
+			last.To = tr.To
+		}
+	}
+
+	return result
+}
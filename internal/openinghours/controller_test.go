@@ -0,0 +1,1037 @@
+package openinghours
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	calendarv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1"
+	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1/calendarv1connect"
+	"github.com/tierklinik-dobersberg/cis/pkg/caldav"
+	"github.com/tierklinik-dobersberg/cis/pkg/daytime"
+	"github.com/tierklinik-dobersberg/cis/pkg/ical"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/dirprovider"
+)
+
+// fakeHolidays is a holiday service client that reports a fixed list of
+// public holidays and counts the number of queries.
+type fakeHolidays struct {
+	calendarv1connect.HolidayServiceClient
+
+	holidays []*calendarv1.PublicHoliday
+	calls    atomic.Int32
+}
+
+func (f *fakeHolidays) GetHoliday(_ context.Context, req *connect.Request[calendarv1.GetHolidayRequest]) (*connect.Response[calendarv1.GetHolidayResponse], error) {
+	f.calls.Add(1)
+
+	res := new(calendarv1.GetHolidayResponse)
+	for _, h := range f.holidays {
+		if strings.HasPrefix(h.Date, fmt.Sprintf("%04d-", req.Msg.Year)) {
+			res.Holidays = append(res.Holidays, h)
+		}
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func newTestController(t *testing.T, defs ...Definition) *Controller {
+	t.Helper()
+
+	loc, err := time.LoadLocation("Europe/Vienna")
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		location: loc,
+		holidays: &fakeHolidays{},
+		state: &state{
+			Regular:            make(map[time.Weekday][]OpeningHour),
+			DateSpecific:       make(map[string][]OpeningHour),
+			ClosedRegular:      make(map[time.Weekday][]Closure),
+			ClosedDateSpecific: make(map[string][]Closure),
+			OnCallRegular:      make(map[time.Weekday][]onCallStart),
+			OnCallDateSpecific: make(map[string][]onCallStart),
+		},
+	}
+
+	require.NoError(t, ctrl.AddOpeningHours(context.Background(), defs...))
+
+	return ctrl
+}
+
+// mondayHours returns a change of the opening hour id that is open on
+// mondays during ranges.
+func mondayHours(t *testing.T, changeType, id, ranges string) runtime.TypedChange[Definition] {
+	t.Helper()
+
+	opts := conf.Options{
+		{Name: "OnWeekday", Value: "Mon"},
+		{Name: "TimeRanges", Value: ranges},
+	}
+
+	value, err := runtime.DecodeOptions[Definition](Spec, opts)
+	require.NoError(t, err)
+
+	return runtime.TypedChange[Definition]{
+		Type:    changeType,
+		ID:      id,
+		Value:   &value,
+		Options: opts,
+	}
+}
+
+func formatFrames(frames []daytime.TimeRange) []string {
+	result := make([]string, len(frames))
+	for idx, f := range frames {
+		result[idx] = f.From.Format(time.RFC3339) + " - " + f.To.Format(time.RFC3339)
+	}
+
+	return result
+}
+
+func TestFramesForDateDST(t *testing.T) {
+	t.Parallel()
+
+	ctrl := newTestController(t,
+		Definition{
+			id:         "night",
+			OnWeekday:  []string{"Sun"},
+			TimeRanges: []string{"01:00 - 04:00", "08:00 - 12:00"},
+		},
+		Definition{
+			id:         "door",
+			OnWeekday:  []string{"Sun"},
+			Kind:       string(KindDoor),
+			TimeRanges: []string{"00:30 - 01:00"},
+		},
+	)
+
+	// In 2024 daylight saving time started on March 31 at 02:00 (skipping
+	// to 03:00) and ended on October 27 at 03:00 (repeating 02:00 - 03:00).
+	cases := []struct {
+		Name     string
+		Date     time.Time
+		Kind     FrameKind
+		Expected []string
+	}{
+		{
+			Name: "regular sunday",
+			Date: time.Date(2024, 3, 24, 12, 0, 0, 0, ctrl.location),
+			Kind: KindBusiness,
+			Expected: []string{
+				"2024-03-24T01:00:00+01:00 - 2024-03-24T04:00:00+01:00",
+				"2024-03-24T08:00:00+01:00 - 2024-03-24T12:00:00+01:00",
+			},
+		},
+		{
+			Name: "DST starts",
+			Date: time.Date(2024, 3, 31, 12, 0, 0, 0, ctrl.location),
+			Kind: KindBusiness,
+			Expected: []string{
+				"2024-03-31T01:00:00+01:00 - 2024-03-31T04:00:00+02:00",
+				"2024-03-31T08:00:00+02:00 - 2024-03-31T12:00:00+02:00",
+			},
+		},
+		{
+			Name: "DST ends",
+			Date: time.Date(2024, 10, 27, 12, 0, 0, 0, ctrl.location),
+			Kind: KindBusiness,
+			Expected: []string{
+				"2024-10-27T01:00:00+02:00 - 2024-10-27T04:00:00+01:00",
+				"2024-10-27T08:00:00+01:00 - 2024-10-27T12:00:00+01:00",
+			},
+		},
+		{
+			Name: "date given in UTC",
+			Date: time.Date(2024, 3, 30, 23, 30, 0, 0, time.UTC),
+			Kind: KindBusiness,
+			Expected: []string{
+				"2024-03-31T01:00:00+01:00 - 2024-03-31T04:00:00+02:00",
+				"2024-03-31T08:00:00+02:00 - 2024-03-31T12:00:00+02:00",
+			},
+		},
+		{
+			Name: "door frames are merged when DST starts",
+			Date: time.Date(2024, 3, 31, 12, 0, 0, 0, ctrl.location),
+			Kind: KindDoor,
+			Expected: []string{
+				"2024-03-31T00:30:00+01:00 - 2024-03-31T04:00:00+02:00",
+				"2024-03-31T08:00:00+02:00 - 2024-03-31T12:00:00+02:00",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		frames := ctrl.FramesForDate(context.Background(), c.Date, c.Kind)
+		assert.Equal(t, c.Expected, formatFrames(frames), c.Name)
+	}
+}
+
+func TestUpcomingFramesDST(t *testing.T) {
+	t.Parallel()
+
+	ctrl := newTestController(t,
+		Definition{
+			id:         "weekend",
+			OnWeekday:  []string{"Sat", "Sun", "Mon"},
+			TimeRanges: []string{"08:00 - 12:00"},
+		},
+		Definition{
+			id:         "night",
+			OnWeekday:  []string{"Sun"},
+			Kind:       string(KindPhone),
+			TimeRanges: []string{"02:00 - 03:30"},
+		},
+		Definition{
+			// wall times within the repeated hour are ambiguous so the
+			// frame on the day DST ends starts before it.
+			id:         "dst-end",
+			UseAtDate:  []string{"10/27"},
+			Kind:       string(KindPhone),
+			TimeRanges: []string{"01:30 - 03:30"},
+		},
+	)
+
+	cases := []struct {
+		Name     string
+		From     time.Time
+		Kind     FrameKind
+		Limit    int
+		Expected []string
+	}{
+		{
+			Name:  "across the start of DST",
+			From:  time.Date(2024, 3, 30, 20, 0, 0, 0, ctrl.location),
+			Kind:  KindBusiness,
+			Limit: 2,
+			Expected: []string{
+				"2024-03-31T08:00:00+02:00 - 2024-03-31T12:00:00+02:00",
+				"2024-04-01T08:00:00+02:00 - 2024-04-01T12:00:00+02:00",
+			},
+		},
+		{
+			Name:  "across the end of DST",
+			From:  time.Date(2024, 10, 26, 20, 0, 0, 0, ctrl.location),
+			Kind:  KindBusiness,
+			Limit: 2,
+			Expected: []string{
+				"2024-10-27T08:00:00+01:00 - 2024-10-27T12:00:00+01:00",
+				"2024-10-28T08:00:00+01:00 - 2024-10-28T12:00:00+01:00",
+			},
+		},
+		{
+			Name:  "within a frame on the day DST ends",
+			From:  time.Date(2024, 10, 27, 9, 0, 0, 0, ctrl.location),
+			Kind:  KindBusiness,
+			Limit: 1,
+			Expected: []string{
+				"2024-10-27T08:00:00+01:00 - 2024-10-27T12:00:00+01:00",
+			},
+		},
+		{
+			Name:  "frame starting in the skipped hour",
+			From:  time.Date(2024, 3, 30, 20, 0, 0, 0, ctrl.location),
+			Kind:  KindPhone,
+			Limit: 1,
+			Expected: []string{
+				"2024-03-31T03:00:00+02:00 - 2024-03-31T03:30:00+02:00",
+			},
+		},
+		{
+			Name:  "frame spanning the repeated hour",
+			From:  time.Date(2024, 10, 26, 20, 0, 0, 0, ctrl.location),
+			Kind:  KindPhone,
+			Limit: 1,
+			Expected: formatFrames([]daytime.TimeRange{
+				{
+					From: time.Date(2024, 10, 26, 23, 30, 0, 0, time.UTC).In(ctrl.location),
+					To:   time.Date(2024, 10, 27, 2, 30, 0, 0, time.UTC).In(ctrl.location),
+				},
+			}),
+		},
+	}
+
+	for _, c := range cases {
+		frames := ctrl.UpcomingFrames(context.Background(), c.From, c.Kind, c.Limit)
+		assert.Equal(t, c.Expected, formatFrames(frames), c.Name)
+	}
+}
+
+func TestUpcomingFramesPublicHolidays(t *testing.T) {
+	t.Parallel()
+
+	ctrl := newTestController(t,
+		Definition{
+			id:         "weekdays",
+			OnWeekday:  []string{"Mon", "Tue", "Wed", "Thu", "Fri"},
+			TimeRanges: []string{"08:00 - 12:00"},
+		},
+	)
+
+	holidays := &fakeHolidays{
+		holidays: []*calendarv1.PublicHoliday{
+			{Date: "2024-12-25", LocalName: "Christtag"},
+			{Date: "2024-12-26", LocalName: "Stefanitag"},
+			{Date: "2025-01-01", LocalName: "Neujahr"},
+		},
+	}
+	ctrl.holidays = holidays
+
+	frames := ctrl.UpcomingFrames(context.Background(), time.Date(2024, 12, 24, 20, 0, 0, 0, ctrl.location), KindBusiness, 4)
+	assert.Equal(t, []string{
+		"2024-12-27T08:00:00+01:00 - 2024-12-27T12:00:00+01:00",
+		"2024-12-30T08:00:00+01:00 - 2024-12-30T12:00:00+01:00",
+		"2024-12-31T08:00:00+01:00 - 2024-12-31T12:00:00+01:00",
+		"2025-01-02T08:00:00+01:00 - 2025-01-02T12:00:00+01:00",
+	}, formatFrames(frames))
+
+	// holidays are loaded once per year and not for each day.
+	assert.Equal(t, int32(2), holidays.calls.Load())
+
+	match := ctrl.HolidaysForDate(context.Background(), time.Date(2025, 1, 1, 10, 0, 0, 0, ctrl.location), KindBusiness)
+	assert.Equal(t, []Holiday{{Calendar: PublicCalendar, Name: "Neujahr"}}, match.Holidays)
+	assert.Equal(t, int32(2), holidays.calls.Load())
+}
+
+func TestClosuresAcrossKinds(t *testing.T) {
+	t.Parallel()
+
+	ctrl := newTestController(t,
+		Definition{
+			id:         "business",
+			OnWeekday:  []string{"Mon"},
+			TimeRanges: []string{"08:00 - 12:00"},
+			OpenBefore: 15 * time.Minute,
+			CloseAfter: 15 * time.Minute,
+		},
+		Definition{
+			id:         "door",
+			OnWeekday:  []string{"Mon"},
+			Kind:       string(KindDoor),
+			TimeRanges: []string{"12:30 - 13:00"},
+		},
+		Definition{
+			id:         "phone",
+			OnWeekday:  []string{"Mon"},
+			Kind:       string(KindPhone),
+			TimeRanges: []string{"07:00 - 14:00"},
+		},
+		Definition{
+			id:         "meeting",
+			OnWeekday:  []string{"Mon"},
+			Closed:     true,
+			Kind:       string(KindBusiness),
+			TimeRanges: []string{"10:00 - 12:45"},
+		},
+		Definition{
+			id:         "tuesday",
+			OnWeekday:  []string{"Tue"},
+			TimeRanges: []string{"08:00 - 11:00", "13:00 - 17:00"},
+			OpenBefore: 15 * time.Minute,
+			CloseAfter: 15 * time.Minute,
+		},
+		Definition{
+			id:         "lunch",
+			OnWeekday:  []string{"Tue"},
+			Closed:     true,
+			TimeRanges: []string{"11:10 - 13:00"},
+		},
+	)
+
+	date := time.Date(2024, 6, 3, 12, 0, 0, 0, ctrl.location)
+
+	cases := []struct {
+		Kind     FrameKind
+		Expected []string
+	}{
+		{
+			Kind: KindBusiness,
+			Expected: []string{
+				"2024-06-03T08:00:00+02:00 - 2024-06-03T10:00:00+02:00",
+			},
+		},
+		{
+			// the padding is dropped at the edge touching the closure.
+			Kind: KindDoor,
+			Expected: []string{
+				"2024-06-03T07:45:00+02:00 - 2024-06-03T10:00:00+02:00",
+				"2024-06-03T12:45:00+02:00 - 2024-06-03T13:00:00+02:00",
+			},
+		},
+		{
+			Kind: KindPhone,
+			Expected: []string{
+				"2024-06-03T07:00:00+02:00 - 2024-06-03T10:00:00+02:00",
+				"2024-06-03T12:45:00+02:00 - 2024-06-03T14:00:00+02:00",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		frames := ctrl.FramesForDate(context.Background(), date, c.Kind)
+		assert.Equal(t, c.Expected, formatFrames(frames), string(c.Kind))
+	}
+
+	// closures that only overlap the door padding clip it.
+	date = date.AddDate(0, 0, 1)
+	assert.Equal(t, []string{
+		"2024-06-04T08:00:00+02:00 - 2024-06-04T11:00:00+02:00",
+		"2024-06-04T13:00:00+02:00 - 2024-06-04T17:00:00+02:00",
+	}, formatFrames(ctrl.FramesForDate(context.Background(), date, KindBusiness)))
+	assert.Equal(t, []string{
+		"2024-06-04T07:45:00+02:00 - 2024-06-04T11:10:00+02:00",
+		"2024-06-04T13:00:00+02:00 - 2024-06-04T17:15:00+02:00",
+	}, formatFrames(ctrl.FramesForDate(context.Background(), date, KindDoor)))
+
+	// closures always apply to all kinds.
+	for _, kind := range []FrameKind{KindDoor, KindPhone} {
+		def := Definition{
+			OnWeekday: []string{"Mon"},
+			Closed:    true,
+			Kind:      string(kind),
+		}
+		assert.Error(t, def.Validate(), string(kind))
+	}
+}
+
+func TestPreview(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctrl := newTestController(t,
+		Definition{
+			id:         "monday",
+			OnWeekday:  []string{"Mon"},
+			TimeRanges: []string{"08:00 - 12:00"},
+		},
+		Definition{
+			id:         "friday",
+			OnWeekday:  []string{"Fri"},
+			TimeRanges: []string{"08:00 - 12:00"},
+		},
+	)
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.June, day, hour, minute, 0, 0, ctrl.location)
+	}
+	monday := at(3, 0, 0)
+
+	// moving the opening hour on mondays changes the frames and the
+	// door transitions.
+	diff, err := ctrl.Preview(ctx, runtime.ChangeTypeUpdate, "monday", &conf.Section{
+		Name: "OpeningHour",
+		Options: conf.Options{
+			{Name: "OnWeekday", Value: "Mon"},
+			{Name: "TimeRanges", Value: "09:00 - 12:00"},
+		},
+	}, monday, 7)
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	assert.Equal(t, "2024-06-03", diff[0].Date)
+	assert.True(t, diff[0].FramesChanged)
+	assert.True(t, diff[0].DoorChanged)
+	assert.Equal(t, []DoorTransition{
+		{At: at(3, 8, 0), Action: DoorUnlock},
+		{At: at(3, 12, 0), Action: DoorLock},
+	}, diff[0].CurrentDoor)
+	assert.Equal(t, []DoorTransition{
+		{At: at(3, 9, 0), Action: DoorUnlock},
+		{At: at(3, 12, 0), Action: DoorLock},
+	}, diff[0].ProposedDoor)
+
+	// unlocking the door earlier only changes the door transitions.
+	diff, err = ctrl.Preview(ctx, runtime.ChangeTypeUpdate, "monday", &conf.Section{
+		Name: "OpeningHour",
+		Options: conf.Options{
+			{Name: "OnWeekday", Value: "Mon"},
+			{Name: "TimeRanges", Value: "08:00 - 12:00"},
+			{Name: "OpenBefore", Value: "15m"},
+		},
+	}, monday, 7)
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	assert.Equal(t, "2024-06-03", diff[0].Date)
+	assert.False(t, diff[0].FramesChanged)
+	assert.True(t, diff[0].DoorChanged)
+	assert.Equal(t, []DoorTransition{
+		{At: at(3, 7, 45), Action: DoorUnlock},
+		{At: at(3, 12, 0), Action: DoorLock},
+	}, diff[0].ProposedDoor)
+
+	// deleting the opening hour on fridays keeps the door locked.
+	diff, err = ctrl.Preview(ctx, runtime.ChangeTypeDelete, "friday", nil, monday, 7)
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	assert.Equal(t, "2024-06-07", diff[0].Date)
+	assert.True(t, diff[0].FramesChanged)
+	assert.True(t, diff[0].DoorChanged)
+	assert.Equal(t, []DoorTransition{
+		{At: at(7, 8, 0), Action: DoorUnlock},
+		{At: at(7, 12, 0), Action: DoorLock},
+	}, diff[0].CurrentDoor)
+	assert.Empty(t, diff[0].ProposedDoor)
+
+	// the current state is never modified.
+	assert.Equal(t, []string{
+		"2024-06-03T08:00:00+02:00 - 2024-06-03T12:00:00+02:00",
+	}, formatFrames(ctrl.FramesForDate(ctx, monday, KindDoor)))
+	assert.Equal(t, []string{
+		"2024-06-07T08:00:00+02:00 - 2024-06-07T12:00:00+02:00",
+	}, formatFrames(ctrl.FramesForDate(ctx, at(7, 0, 0), KindDoor)))
+}
+
+func TestOnCallAt(t *testing.T) {
+	t.Parallel()
+
+	ctrl := newTestController(t,
+		Definition{
+			id:               "weekdays",
+			OnWeekday:        []string{"Mon", "Tue", "Wed", "Thu", "Fri"},
+			OnCallDayStart:   "07:30",
+			OnCallNightStart: "18:00",
+		},
+		Definition{
+			id:               "weekend",
+			OnWeekday:        []string{"Sat", "Sun"},
+			OnCallDayStart:   "09:00",
+			OnCallNightStart: "17:00",
+		},
+		Definition{
+			id:               "holiday",
+			Holiday:          "only",
+			OnCallDayStart:   "10:00",
+			OnCallNightStart: "16:00",
+		},
+	)
+	ctrl.holidays = &fakeHolidays{
+		holidays: []*calendarv1.PublicHoliday{
+			{Date: "2024-05-01", LocalName: "Staatsfeiertag"},
+		},
+	}
+
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, ctrl.location)
+	}
+
+	cases := []struct {
+		Name  string
+		At    time.Time
+		Shift OnCallShift
+		Since time.Time
+		Until time.Time
+	}{
+		{
+			Name:  "right before the day shift starts",
+			At:    at(time.June, 7, 7, 29),
+			Shift: OnCallNight,
+			Since: at(time.June, 6, 18, 0),
+			Until: at(time.June, 7, 7, 30),
+		},
+		{
+			Name:  "when the day shift starts",
+			At:    at(time.June, 7, 7, 30),
+			Shift: OnCallDay,
+			Since: at(time.June, 7, 7, 30),
+			Until: at(time.June, 7, 18, 0),
+		},
+		{
+			Name:  "night shift lasts until the weekend day shift",
+			At:    at(time.June, 7, 18, 0),
+			Shift: OnCallNight,
+			Since: at(time.June, 7, 18, 0),
+			Until: at(time.June, 8, 9, 0),
+		},
+		{
+			Name:  "weekday night shift overlaps into the weekend",
+			At:    at(time.June, 8, 8, 59),
+			Shift: OnCallNight,
+			Since: at(time.June, 7, 18, 0),
+			Until: at(time.June, 8, 9, 0),
+		},
+		{
+			Name:  "night shift before a public holiday",
+			At:    at(time.May, 1, 9, 0),
+			Shift: OnCallNight,
+			Since: at(time.April, 30, 18, 0),
+			Until: at(time.May, 1, 10, 0),
+		},
+		{
+			Name:  "night shift after a public holiday",
+			At:    at(time.May, 1, 16, 0),
+			Shift: OnCallNight,
+			Since: at(time.May, 1, 16, 0),
+			Until: at(time.May, 2, 7, 30),
+		},
+	}
+
+	for _, c := range cases {
+		shift, since, until, err := ctrl.OnCallAt(context.Background(), c.At)
+		require.NoError(t, err, c.Name)
+		assert.Equal(t, c.Shift, shift, c.Name)
+		assert.True(t, c.Since.Equal(since), "%s: since %s", c.Name, since)
+		assert.True(t, c.Until.Equal(until), "%s: until %s", c.Name, until)
+	}
+
+	// on-call definitions must be bound to days or holidays.
+	for _, def := range []Definition{
+		{
+			id:             "only-with-days",
+			Holiday:        "only",
+			OnWeekday:      []string{"Mon"},
+			OnCallDayStart: "08:00",
+		},
+		{
+			id:             "unbound",
+			OnCallDayStart: "08:00",
+		},
+	} {
+		assert.Error(t, ctrl.AddOpeningHours(context.Background(), def), def.id)
+	}
+}
+
+func TestOnCallOrderAcrossDefinitions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := newTestController(t)
+	ctrl.holidays = &fakeHolidays{}
+
+	dayStart, nightStart := daytime.DayTime{7, 30}, daytime.DayTime{20, 0}
+	ctrl.state.defaultOnCall = onCallStart{ID: "default", DayStart: &dayStart, NightStart: &nightStart}
+
+	// the night shift would start before the default day shift.
+	assert.Error(t, ctrl.AddOpeningHours(context.Background(), Definition{
+		id:               "early-night",
+		OnWeekday:        []string{"Mon"},
+		OnCallNightStart: "06:00",
+	}))
+
+	// each definition is valid on its own but the date specific day
+	// start is after the regular night start on Mondays.
+	require.NoError(t, ctrl.AddOpeningHours(context.Background(),
+		Definition{
+			id:               "monday",
+			OnWeekday:        []string{"Mon"},
+			OnCallNightStart: "18:00",
+		},
+		Definition{
+			id:             "late-day",
+			UseAtDate:      []string{"06/03"},
+			OnCallDayStart: "19:00",
+		},
+	))
+
+	_, _, _, err := ctrl.OnCallAt(context.Background(), time.Date(2024, time.June, 3, 12, 0, 0, 0, ctrl.location))
+	assert.Error(t, err)
+
+	shift, _, _, err := ctrl.OnCallAt(context.Background(), time.Date(2024, time.June, 10, 12, 0, 0, 0, ctrl.location))
+	require.NoError(t, err)
+	assert.Equal(t, OnCallDay, shift)
+}
+
+func TestHolidayCalendars(t *testing.T) {
+	t.Parallel()
+
+	ctrl := newTestController(t)
+
+	for _, def := range []CalendarDefinition{
+		{id: "school", Name: "school", Dates: []string{"2025-02-03 - 2025-02-08: Semesterferien", "12/24 - 01/06: Weihnachtsferien"}},
+		{id: "noe", Name: "noe", Region: "AT-3"},
+	} {
+		cal, err := def.parse()
+		require.NoError(t, err)
+		ctrl.state.Calendars = append(ctrl.state.Calendars, cal)
+	}
+
+	require.NoError(t, ctrl.AddOpeningHours(context.Background(),
+		Definition{
+			id:         "regular",
+			OnWeekday:  []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+			TimeRanges: []string{"08:00 - 18:00"},
+		},
+		Definition{
+			id:              "school",
+			Holiday:         "only",
+			HolidayCalendar: []string{"school"},
+			TimeRanges:      []string{"08:00 - 12:00"},
+		},
+		Definition{
+			id:              "noe",
+			Holiday:         "only",
+			HolidayCalendar: []string{"noe"},
+			Closed:          true,
+		},
+	))
+
+	cases := []struct {
+		Name     string
+		Date     time.Time
+		Holidays []Holiday
+		Calendar string
+		Frames   []string
+	}{
+		{
+			Name:   "regular day",
+			Date:   time.Date(2025, 2, 10, 12, 0, 0, 0, ctrl.location),
+			Frames: []string{"2025-02-10T08:00:00+01:00 - 2025-02-10T18:00:00+01:00"},
+		},
+		{
+			Name:     "school holiday range",
+			Date:     time.Date(2025, 2, 4, 12, 0, 0, 0, ctrl.location),
+			Holidays: []Holiday{{Calendar: "school", Name: "Semesterferien"}},
+			Calendar: "school",
+			Frames:   []string{"2025-02-04T08:00:00+01:00 - 2025-02-04T12:00:00+01:00"},
+		},
+		{
+			Name:     "recurring school holiday across the new year",
+			Date:     time.Date(2025, 1, 3, 12, 0, 0, 0, ctrl.location),
+			Holidays: []Holiday{{Calendar: "school", Name: "Weihnachtsferien"}},
+			Calendar: "school",
+			Frames:   []string{"2025-01-03T08:00:00+01:00 - 2025-01-03T12:00:00+01:00"},
+		},
+		{
+			Name:     "regional holiday closure",
+			Date:     time.Date(2024, 11, 15, 12, 0, 0, 0, ctrl.location),
+			Holidays: []Holiday{{Calendar: "noe", Name: "Hl. Leopold"}},
+			Frames:   []string{},
+		},
+	}
+
+	for _, c := range cases {
+		match := ctrl.HolidaysForDate(context.Background(), c.Date, KindBusiness)
+		assert.Equal(t, c.Holidays, match.Holidays, c.Name)
+		assert.Equal(t, c.Calendar, match.Calendar, c.Name)
+
+		frames := ctrl.FramesForDate(context.Background(), c.Date, KindBusiness)
+		assert.Equal(t, c.Frames, formatFrames(frames), c.Name)
+	}
+}
+
+// memoryHistory is an in-memory HistoryDatabase.
+type memoryHistory struct {
+	revisions []Revision
+}
+
+func (m *memoryHistory) Append(_ context.Context, rev *Revision) error {
+	rev.Version = len(m.revisions) + 1
+	m.revisions = append(m.revisions, *rev)
+
+	return nil
+}
+
+func (m *memoryHistory) Latest(ctx context.Context) (*Revision, error) {
+	if len(m.revisions) == 0 {
+		return nil, ErrNoRevision
+	}
+
+	return &m.revisions[len(m.revisions)-1], nil
+}
+
+func (m *memoryHistory) Get(_ context.Context, version int) (*Revision, error) {
+	for idx := range m.revisions {
+		if m.revisions[idx].Version == version {
+			return &m.revisions[idx], nil
+		}
+	}
+
+	return nil, ErrNoRevision
+}
+
+func (m *memoryHistory) At(_ context.Context, t time.Time) (*Revision, error) {
+	for idx := len(m.revisions) - 1; idx >= 0; idx-- {
+		if !m.revisions[idx].Time.After(t) {
+			return &m.revisions[idx], nil
+		}
+	}
+
+	return nil, ErrNoRevision
+}
+
+func (m *memoryHistory) List(_ context.Context, from, to time.Time) ([]Revision, error) {
+	return m.revisions, nil
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctrl := newTestController(t)
+	db := new(memoryHistory)
+
+	require.NoError(t, ctrl.EnableHistory(ctx, db))
+	assert.Empty(t, db.revisions)
+
+	require.NoError(t, ctrl.applyOpeningHours(ctx, []runtime.TypedChange[Definition]{
+		mondayHours(t, runtime.ChangeTypeCreate, "monday", "08:00 - 12:00"),
+	}))
+	require.NoError(t, ctrl.applyOpeningHours(ctx, []runtime.TypedChange[Definition]{
+		mondayHours(t, runtime.ChangeTypeUpdate, "monday", "09:00 - 12:00"),
+	}))
+
+	require.Len(t, db.revisions, 2)
+	assert.Equal(t, 2, db.revisions[1].Version)
+	assert.Equal(t, []OptionDiff{
+		{Name: "TimeRanges", Before: []string{"08:00 - 12:00"}, After: []string{"09:00 - 12:00"}},
+	}, db.revisions[1].Changes[0].Diff)
+
+	// make the first revision an hour older so AsOf can select it.
+	db.revisions[0].Time = db.revisions[0].Time.Add(-time.Hour)
+
+	monday := time.Date(2024, time.March, 4, 0, 0, 0, 0, ctrl.location)
+
+	historic, rev, err := ctrl.AsOf(ctx, db.revisions[0].Time.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, rev.Version)
+	assert.Equal(t, []string{
+		"2024-03-04T08:00:00+01:00 - 2024-03-04T12:00:00+01:00",
+	}, formatFrames(historic.FramesForDate(ctx, monday, KindBusiness)))
+
+	assert.Equal(t, []string{
+		"2024-03-04T09:00:00+01:00 - 2024-03-04T12:00:00+01:00",
+	}, formatFrames(ctrl.FramesForDate(ctx, monday, KindBusiness)))
+
+	// restarting with an unchanged configuration must not create a
+	// new revision.
+	require.NoError(t, ctrl.EnableHistory(ctx, db))
+	assert.Len(t, db.revisions, 2)
+}
+
+func TestHistoryExternalChanges(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+
+	provider, err := dirprovider.New(dir)
+	require.NoError(t, err)
+
+	schema := new(runtime.ConfigSchema)
+	require.NoError(t, AddToSchema(schema))
+	schema.SetProvider(provider)
+
+	ctrl := newTestController(t)
+	db := new(memoryHistory)
+	require.NoError(t, ctrl.EnableHistory(ctx, db))
+
+	require.NoError(t, ctrl.listen(ctx, schema))
+
+	changed := make(chan struct{}, 10)
+	ctrl.OnChange(func() { changed <- struct{}{} })
+
+	waitForChange := func() {
+		t.Helper()
+
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for opening hour change")
+		}
+	}
+
+	require.NoError(t, schema.Watch(ctx))
+
+	// changes made by other instances are recorded by them.
+	other, err := dirprovider.New(dir)
+	require.NoError(t, err)
+
+	id, err := other.Create(ctx, conf.Section{
+		Name: "OpeningHour",
+		Options: conf.Options{
+			{Name: "OnWeekday", Value: "Mon"},
+			{Name: "TimeRanges", Value: "08:00 - 12:00"},
+		},
+	})
+	require.NoError(t, err)
+	waitForChange()
+	assert.Empty(t, db.revisions)
+
+	monday := time.Date(2024, time.March, 4, 0, 0, 0, 0, ctrl.location)
+	assert.Equal(t, []string{
+		"2024-03-04T08:00:00+01:00 - 2024-03-04T12:00:00+01:00",
+	}, formatFrames(ctrl.FramesForDate(ctx, monday, KindBusiness)))
+
+	// while changes made through this instance create a new revision.
+	require.NoError(t, schema.Update(ctx, id, "OpeningHour", []conf.Option{
+		{Name: "OnWeekday", Value: "Mon"},
+		{Name: "TimeRanges", Value: "09:00 - 12:00"},
+	}))
+	waitForChange()
+
+	require.Len(t, db.revisions, 1)
+	assert.Equal(t, 1, db.revisions[0].Version)
+	assert.Equal(t, runtime.ChangeTypeUpdate, db.revisions[0].Type)
+}
+
+func TestOnWeekdaySpellings(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	provider, err := dirprovider.New(t.TempDir())
+	require.NoError(t, err)
+
+	schema := new(runtime.ConfigSchema)
+	require.NoError(t, AddToSchema(schema))
+	schema.SetProvider(provider)
+
+	id, err := schema.Create(ctx, "OpeningHour", []conf.Option{
+		{Name: "OnWeekday", Value: "Mon"},
+		{Name: "TimeRanges", Value: "08:00 - 12:00"},
+	})
+	require.NoError(t, err)
+
+	// all spellings accepted by ValidDay are allowed.
+	for _, day := range []string{"Mo", "monday", "Tue", "Th"} {
+		assert.NoError(t, schema.Update(ctx, id, "OpeningHour", []conf.Option{
+			{Name: "OnWeekday", Value: day},
+			{Name: "TimeRanges", Value: "08:00 - 12:00"},
+		}), day)
+	}
+
+	assert.Error(t, schema.Update(ctx, id, "OpeningHour", []conf.Option{
+		{Name: "OnWeekday", Value: "Mond"},
+		{Name: "TimeRanges", Value: "08:00 - 12:00"},
+	}))
+}
+
+func TestBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctrl := newTestController(t)
+	db := new(memoryHistory)
+
+	require.NoError(t, ctrl.EnableHistory(ctx, db))
+
+	for id, ranges := range map[string]string{"a": "08:00 - 09:00", "b": "10:00 - 11:00"} {
+		require.NoError(t, ctrl.applyOpeningHours(ctx, []runtime.TypedChange[Definition]{
+			mondayHours(t, runtime.ChangeTypeCreate, id, ranges),
+		}))
+	}
+
+	// swapping both ranges is only valid as a whole.
+	swap := []runtime.TypedChange[Definition]{
+		mondayHours(t, runtime.ChangeTypeUpdate, "a", "10:00 - 11:00"),
+		mondayHours(t, runtime.ChangeTypeUpdate, "b", "08:00 - 09:00"),
+	}
+	assert.Error(t, ctrl.validateOpeningHours(ctx, swap[:1]))
+	require.NoError(t, ctrl.validateOpeningHours(ctx, swap))
+
+	notified := 0
+	ctrl.OnChange(func() { notified++ })
+
+	require.NoError(t, ctrl.applyOpeningHours(ctx, swap))
+	assert.Equal(t, 1, notified)
+
+	require.Len(t, db.revisions, 3)
+	assert.Equal(t, ChangeTypeBatch, db.revisions[2].Type)
+	assert.Len(t, db.revisions[2].Changes, 2)
+
+	// overlapping results are still rejected
+	assert.Error(t, ctrl.validateOpeningHours(ctx, []runtime.TypedChange[Definition]{
+		mondayHours(t, runtime.ChangeTypeUpdate, "a", "08:30 - 09:30"),
+	}))
+}
+
+func TestEventOverrides(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctrl := newTestController(t, Definition{
+		id:         "daily",
+		OnWeekday:  []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
+		TimeRanges: []string{"08:00 - 12:00", "14:00 - 18:00"},
+	})
+
+	now := time.Now().In(ctrl.location)
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, ctrl.location)
+	at := func(days, hour, minute int) time.Time {
+		return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day()+days, hour, minute, 0, 0, ctrl.location)
+	}
+
+	srv := caldav.NewServer("/calendars/clinic", ctrl.location)
+	httpSrv := httptest.NewServer(srv)
+	defer httpSrv.Close()
+
+	require.NoError(t, srv.PutEvent(ical.Event{
+		UID:     "training",
+		Summary: "Team training #closed",
+		Start:   at(0, 14, 0),
+		End:     at(0, 16, 0),
+	}))
+	require.NoError(t, srv.PutEvent(ical.Event{
+		UID:         "open-house",
+		Summary:     "Open house",
+		Description: "Let visitors in (#door-unlock)",
+		Start:       at(0, 19, 0),
+		End:         at(0, 21, 0),
+	}))
+	require.NoError(t, srv.PutEvent(ical.Event{
+		UID:        "holidays",
+		Summary:    "Company holidays",
+		Categories: []string{"closed"},
+		AllDay:     true,
+		Start:      at(1, 0, 0),
+		End:        at(3, 0, 0),
+	}))
+	require.NoError(t, srv.PutEvent(ical.Event{
+		UID:     "meeting",
+		Summary: "Untagged meeting",
+		Start:   at(0, 8, 0),
+		End:     at(0, 9, 0),
+	}))
+
+	src, err := caldav.NewClient(httpSrv.URL+"/calendars/clinic/", nil, ctrl.location)
+	require.NoError(t, err)
+
+	notified := 0
+	ctrl.OnChange(func() { notified++ })
+
+	require.NoError(t, ctrl.SyncEvents(ctx, src))
+	assert.Equal(t, 1, notified)
+
+	assert.Equal(t, []string{
+		at(0, 8, 0).Format(time.RFC3339) + " - " + at(0, 12, 0).Format(time.RFC3339),
+		at(0, 16, 0).Format(time.RFC3339) + " - " + at(0, 18, 0).Format(time.RFC3339),
+	}, formatFrames(ctrl.FramesForDate(ctx, tomorrow, KindBusiness)))
+
+	assert.Equal(t, []string{
+		at(0, 8, 0).Format(time.RFC3339) + " - " + at(0, 12, 0).Format(time.RFC3339),
+		at(0, 16, 0).Format(time.RFC3339) + " - " + at(0, 18, 0).Format(time.RFC3339),
+		at(0, 19, 0).Format(time.RFC3339) + " - " + at(0, 21, 0).Format(time.RFC3339),
+	}, formatFrames(ctrl.FramesForDate(ctx, tomorrow, KindDoor)))
+
+	closures := ctrl.ClosuresForDate(ctx, tomorrow)
+	require.Len(t, closures, 1)
+	assert.Equal(t, "Team training", closures[0].Reason)
+
+	for days := 1; days <= 2; days++ {
+		date := at(days, 0, 0)
+		assert.Empty(t, ctrl.FramesForDate(ctx, date, KindBusiness), date)
+
+		closures := ctrl.ClosuresForDate(ctx, date)
+		require.Len(t, closures, 1)
+		assert.True(t, closures[0].AllDay)
+	}
+	assert.Len(t, ctrl.FramesForDate(ctx, at(3, 0, 0), KindBusiness), 2)
+
+	// unchanged events must not trigger change notifications
+	require.NoError(t, ctrl.SyncEvents(ctx, src))
+	assert.Equal(t, 1, notified)
+
+	srv.DeleteEvent("holidays")
+	require.NoError(t, ctrl.SyncEvents(ctx, src))
+	assert.Equal(t, 2, notified)
+	assert.Len(t, ctrl.FramesForDate(ctx, at(1, 0, 0), KindBusiness), 2)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/pkg/daytime"
 	"github.com/tierklinik-dobersberg/cis/runtime"
 )
 
@@ -21,6 +22,10 @@ type Definition struct {
 	// UseAtDate should have the format MM/DD and are year independent.
 	UseAtDate []string
 
+	// Kind defines the kind of the time ranges. It defaults to
+	// "business".
+	Kind string
+
 	// OpenBefore describes the amount of time the entry door
 	// should open before the specified time.
 	OpenBefore time.Duration
@@ -34,6 +39,26 @@ type Definition struct {
 	TimeRanges []string
 
 	Holiday string
+
+	// HolidayCalendar is a list of holiday calendars that are used to
+	// decide if a day is a holiday for this definition. It defaults to
+	// the public holidays of the configured country.
+	HolidayCalendar []string
+
+	// Closed marks the definition as a closure. Closures are subtracted
+	// from all other time ranges. If TimeRanges is empty the closure
+	// spans the whole day.
+	Closed bool
+
+	// Reason holds an optional, human readable reason for a closure.
+	Reason string
+
+	// OnCallDayStart and OnCallNightStart define when the day and night
+	// on-call shifts start on the specified days in the format of HH:MM.
+	// If unset, the values from DefaultOnCallDayStart= and
+	// DefaultOnCallNightStart= are used.
+	OnCallDayStart   string
+	OnCallNightStart string
 }
 
 // Spec describes the different configuration stanzas for the Definition struct.
@@ -47,30 +72,37 @@ var Spec = conf.SectionSpec{
 				runtime.PossibleValue{
 					Value:   "Mon",
 					Display: "Monday",
+					Aliases: []string{"Mo", "Monday"},
 				},
 				runtime.PossibleValue{
 					Value:   "Tue",
 					Display: "Tuesday",
+					Aliases: []string{"Tu", "Tuesday"},
 				},
 				runtime.PossibleValue{
 					Value:   "Wed",
 					Display: "Wednesday",
+					Aliases: []string{"We", "Wednesday"},
 				},
 				runtime.PossibleValue{
 					Value:   "Thu",
 					Display: "Thursday",
+					Aliases: []string{"Th", "Thursday"},
 				},
 				runtime.PossibleValue{
 					Value:   "Fri",
 					Display: "Friday",
+					Aliases: []string{"Fr", "Friday"},
 				},
 				runtime.PossibleValue{
 					Value:   "Sat",
 					Display: "Saturday",
+					Aliases: []string{"Sa", "Saturday"},
 				},
 				runtime.PossibleValue{
 					Value:   "Sun",
 					Display: "Sunday",
+					Aliases: []string{"Su", "Sunday"},
 				},
 			),
 		),
@@ -80,21 +112,42 @@ var Spec = conf.SectionSpec{
 		Description: "A list of dates at which this section takes effect. Format is defined MM/DD",
 		Type:        conf.StringSliceType,
 	},
+	{
+		Name:        "Kind",
+		Type:        conf.StringType,
+		Default:     string(KindBusiness),
+		Description: "The kind of time ranges. Business hours are publicly visible and unlock the entry door, door hours only unlock the entry door and phone hours define when the reception is staffed. Not allowed for closures as they apply to all kinds.",
+		Annotations: new(conf.Annotation).With(
+			runtime.OneOf(
+				runtime.PossibleValue{
+					Value:   string(KindBusiness),
+					Display: "Business hours",
+				},
+				runtime.PossibleValue{
+					Value:   string(KindDoor),
+					Display: "Door unlocked",
+				},
+				runtime.PossibleValue{
+					Value:   string(KindPhone),
+					Display: "Phone/Reception",
+				},
+			),
+		),
+	},
 	{
 		Name:        "OpenBefore",
 		Type:        conf.DurationType,
-		Description: "Defines how long before the opening hour the entry door to should get unlocked.",
+		Description: "Defines how long before the opening hour the entry door to should get unlocked. Only valid for business hours.",
 	},
 	{
 		Name:        "CloseAfter",
 		Type:        conf.DurationType,
-		Description: "Defines how long after the opening hour the entry door should get locked.",
+		Description: "Defines how long after the opening hour the entry door should get locked. Only valid for business hours.",
 	},
 	{
 		Name:        "TimeRanges",
 		Type:        conf.StringSliceType,
-		Description: "A list of office/opening hour time ranges (HH:MM - HH:MM).",
-		Required:    true,
+		Description: "A list of office/opening hour time ranges (HH:MM - HH:MM). Required unless Closed= is set in which case an empty list closes the whole day.",
 	},
 	{
 		Name:        "Holiday",
@@ -117,6 +170,35 @@ var Spec = conf.SectionSpec{
 			),
 		),
 	},
+	{
+		Name:        "HolidayCalendar",
+		Type:        conf.StringSliceType,
+		Description: "A list of holiday calendars used for Holiday=yes and Holiday=only. Defaults to the public holidays (" + PublicCalendar + ").",
+		Annotations: new(conf.Annotation).With(
+			runtime.OneOfRef("HolidayCalendar", "Name", "Description", true),
+		),
+	},
+	{
+		Name:        "Closed",
+		Type:        conf.BoolType,
+		Default:     "no",
+		Description: "Marks the definition as a closure. The time ranges (or the whole day if none are set) are removed from all other opening hours.",
+	},
+	{
+		Name:        "Reason",
+		Type:        conf.StringType,
+		Description: "An optional reason for the closure that is displayed together with the opening hours.",
+	},
+	{
+		Name:        "OnCallDayStart",
+		Type:        conf.StringType,
+		Description: "The time (HH:MM) at which the day on-call shift starts on the specified days. Defaults to DefaultOnCallDayStart=.",
+	},
+	{
+		Name:        "OnCallNightStart",
+		Type:        conf.StringType,
+		Description: "The time (HH:MM) at which the night on-call shift starts on the specified days. Defaults to DefaultOnCallNightStart=.",
+	},
 }
 
 // Validate validates the opening hours defined in opt.
@@ -128,9 +210,86 @@ func (opt *Definition) Validate() error {
 		}
 	}
 
+	kind, err := ParseFrameKind(opt.Kind)
+	if err != nil {
+		return err
+	}
+
+	if len(opt.HolidayCalendar) > 0 {
+		switch strings.ToLower(opt.Holiday) {
+		case "yes", "only":
+		default:
+			return fmt.Errorf("HolidayCalendar= is only allowed with Holiday=yes or Holiday=only")
+		}
+	}
+
+	if opt.Closed {
+		if opt.OpenBefore != 0 || opt.CloseAfter != 0 {
+			return fmt.Errorf("OpenBefore= and CloseAfter= are not allowed for closures")
+		}
+
+		if opt.OnCallDayStart != "" || opt.OnCallNightStart != "" {
+			return fmt.Errorf("OnCallDayStart= and OnCallNightStart= are not allowed for closures")
+		}
+
+		// closures are subtracted from the time ranges of all kinds
+		// so only the default kind is accepted.
+		if kind != KindBusiness {
+			return fmt.Errorf("Kind= is not allowed for closures, they apply to all kinds")
+		}
+
+		return nil
+	}
+
+	onCall, err := opt.onCall()
+	if err != nil {
+		return err
+	}
+
+	if len(opt.TimeRanges) == 0 && onCall.isZero() {
+		return fmt.Errorf("TimeRanges= is required unless Closed=, OnCallDayStart= or OnCallNightStart= is set")
+	}
+
+	if opt.Reason != "" {
+		return fmt.Errorf("Reason= is only allowed for closures")
+	}
+
+	if kind != KindBusiness && (opt.OpenBefore != 0 || opt.CloseAfter != 0) {
+		return fmt.Errorf("OpenBefore= and CloseAfter= are only allowed for Kind=%s", KindBusiness)
+	}
+
 	return nil
 }
 
+// onCall returns the on-call boundaries configured in opt.
+func (opt *Definition) onCall() (onCallStart, error) {
+	result := onCallStart{
+		ID: opt.id,
+	}
+
+	if opt.OnCallDayStart != "" {
+		dt, err := daytime.ParseDayTime(opt.OnCallDayStart)
+		if err != nil {
+			return result, fmt.Errorf("OnCallDayStart: %w", err)
+		}
+		result.DayStart = &dt
+	}
+
+	if opt.OnCallNightStart != "" {
+		dt, err := daytime.ParseDayTime(opt.OnCallNightStart)
+		if err != nil {
+			return result, fmt.Errorf("OnCallNightStart: %w", err)
+		}
+		result.NightStart = &dt
+	}
+
+	if err := checkOnCallOrder(result.DayStart, result.NightStart); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
 // ParseDay parses the weekday specified in day. For strict parsing,
 // day should be validated using ValidDay before using ParseDay.
 func ParseDay(day string) (time.Weekday, bool) {
@@ -176,7 +335,7 @@ func ValidDay(day string) error {
 }
 
 func addOpeningHours(s *runtime.ConfigSchema) error {
-	return s.Register(runtime.Schema{
+	_, err := runtime.RegisterTyped[Definition](s, runtime.Schema{
 		Name:        "OpeningHour",
 		DisplayName: "Öffnungszeiten",
 		Description: "Opening hours definitions",
@@ -184,7 +343,9 @@ func addOpeningHours(s *runtime.ConfigSchema) error {
 		Multi:       true,
 		SVGData:     `<path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M19 21V5a2 2 0 00-2-2H7a2 2 0 00-2 2v16m14 0h2m-2 0h-5m-9 0H3m2 0h5M9 7h1m-1 4h1m4-4h1m-1 4h1m-5 10v-5a1 1 0 011-1h2a1 1 0 011 1v5m-4 0h4" />`,
 		Annotations: new(conf.Annotation).With(
-			runtime.OverviewFields("OnWeekday", "UseAtDate", "Holiday", "TimeRanges", "OnCallDayStart", "OnCallNightStart"),
+			runtime.OverviewFields("Kind", "OnWeekday", "UseAtDate", "Holiday", "HolidayCalendar", "TimeRanges", "Closed", "Reason", "OnCallDayStart", "OnCallNightStart"),
 		),
 	})
+
+	return err
 }
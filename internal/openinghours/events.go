@@ -0,0 +1,312 @@
+package openinghours
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bufbuild/connect-go"
+	calendarv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1"
+	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1/calendarv1connect"
+	commonv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/common/v1"
+	"github.com/tierklinik-dobersberg/apis/pkg/discovery/consuldiscover"
+	"github.com/tierklinik-dobersberg/apis/pkg/discovery/wellknown"
+	"github.com/tierklinik-dobersberg/cis/internal/cfgspec"
+	"github.com/tierklinik-dobersberg/cis/pkg/caldav"
+	"github.com/tierklinik-dobersberg/cis/pkg/daytime"
+	"github.com/tierklinik-dobersberg/cis/pkg/ical"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Tags that can be used in the summary, description or categories of
+// calendar events to override the opening hours.
+const (
+	// TagDoorUnlock unlocks the entry door for the duration of the
+	// event.
+	TagDoorUnlock = "#door-unlock"
+
+	// TagClosed closes for the duration of the event. The summary of
+	// the event is used as the reason of the closure.
+	TagClosed = "#closed"
+)
+
+// eventLookahead is the time range after now for which events are
+// loaded. Event overrides further in the future are ignored until
+// they move into the window.
+const eventLookahead = 90 * 24 * time.Hour
+
+// EventSource provides calendar events that override the configured
+// opening hours.
+type EventSource interface {
+	// Events returns all events that overlap with from - to.
+	Events(ctx context.Context, from, to time.Time) ([]ical.Event, error)
+}
+
+// eventOverrides holds the time ranges created from calendar events
+// indexed by date in the format "2006-01-02".
+type eventOverrides struct {
+	doors    map[string][]OpeningHour
+	closures map[string][]Closure
+}
+
+// NewEventSource returns the event source configured in cfg or nil if
+// event overrides are disabled. OverrideCalDAVURL takes precedence over
+// OverrideCalendarID.
+func NewEventSource(ctx context.Context, cfg cfgspec.Config, loc *time.Location) (EventSource, error) {
+	switch {
+	case cfg.OverrideCalDAVURL != "":
+		cli, err := caldav.NewClient(cfg.OverrideCalDAVURL, http.DefaultClient, loc)
+		if err != nil {
+			return nil, fmt.Errorf("option OverrideCalDAVURL: %w", err)
+		}
+
+		return cli, nil
+
+	case cfg.OverrideCalendarID != "":
+		disc, err := consuldiscover.NewFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get consul service catalog: %w", err)
+		}
+
+		cli, err := wellknown.CalendarService.Create(ctx, disc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get calendar service client: %w", err)
+		}
+
+		return NewCalendarServiceSource(cli, cfg.OverrideCalendarID, loc), nil
+	}
+
+	return nil, nil
+}
+
+type calendarServiceSource struct {
+	cli        calendarv1connect.CalendarServiceClient
+	calendarID string
+	location   *time.Location
+}
+
+// NewCalendarServiceSource returns an event source that loads the events
+// of calendarID from the calendar service.
+func NewCalendarServiceSource(cli calendarv1connect.CalendarServiceClient, calendarID string, loc *time.Location) EventSource {
+	return &calendarServiceSource{
+		cli:        cli,
+		calendarID: calendarID,
+		location:   loc,
+	}
+}
+
+func (src *calendarServiceSource) Events(ctx context.Context, from, to time.Time) ([]ical.Event, error) {
+	res, err := src.cli.ListEvents(ctx, connect.NewRequest(&calendarv1.ListEventsRequest{
+		Source: &calendarv1.ListEventsRequest_Sources{
+			Sources: &calendarv1.EventSource{
+				CalendarIds: []string{src.calendarID},
+			},
+		},
+		SearchTime: &calendarv1.ListEventsRequest_TimeRange{
+			TimeRange: &commonv1.TimeRange{
+				From: timestamppb.New(from),
+				To:   timestamppb.New(to),
+			},
+		},
+		RequestKinds: []calendarv1.CalenarEventRequestKind{
+			calendarv1.CalenarEventRequestKind_CALENDAR_EVENT_REQUEST_KIND_EVENTS,
+		},
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ical.Event
+	for _, list := range res.Msg.GetResults() {
+		for _, evt := range list.GetEvents() {
+			if evt.GetIsFree() {
+				continue
+			}
+
+			e := ical.Event{
+				UID:         evt.GetId(),
+				Summary:     evt.GetSummary(),
+				Description: evt.GetDescription(),
+				Start:       evt.GetStartTime().AsTime().In(src.location),
+				AllDay:      evt.GetFullDay(),
+			}
+
+			if evt.GetEndTime() != nil {
+				e.End = evt.GetEndTime().AsTime().In(src.location)
+			}
+
+			if e.AllDay {
+				e.Start = daytime.Midnight(e.Start)
+				if !e.End.After(e.Start) {
+					e.End = e.Start.AddDate(0, 0, 1)
+				}
+			}
+
+			result = append(result, e)
+		}
+	}
+
+	return result, nil
+}
+
+// SyncEvents loads all events from src that overlap with the time
+// range from yesterday until eventLookahead and replaces the current
+// event overrides. Change notifiers are called if the overrides
+// changed.
+func (ctrl *Controller) SyncEvents(ctx context.Context, src EventSource) error {
+	now := time.Now().In(ctrl.location)
+	from := daytime.Midnight(now).AddDate(0, 0, -1)
+
+	events, err := src.Events(ctx, from, now.Add(eventLookahead))
+	if err != nil {
+		return fmt.Errorf("failed to load events: %w", err)
+	}
+
+	overrides := eventOverrides{
+		doors:    make(map[string][]OpeningHour),
+		closures: make(map[string][]Closure),
+	}
+	for _, evt := range events {
+		overrides.add(evt, ctrl.location)
+	}
+
+	ctrl.rw.Lock()
+
+	if reflect.DeepEqual(ctrl.events, overrides) {
+		ctrl.rw.Unlock()
+
+		return nil
+	}
+
+	ctrl.events = overrides
+	notifier := ctrl.notifier
+
+	ctrl.rw.Unlock()
+
+	log.From(ctx).Infof("loaded %d event overrides", len(events))
+
+	for _, fn := range notifier {
+		fn()
+	}
+
+	return nil
+}
+
+// WatchEvents calls SyncEvents every interval until ctx is cancelled.
+// Errors are logged and the previous overrides are kept.
+func (ctrl *Controller) WatchEvents(ctx context.Context, src EventSource, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := ctrl.SyncEvents(ctx, src); err != nil {
+			log.From(ctx).Errorf("failed to sync event overrides: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// add adds the time ranges of evt to ev. Events that span multiple days
+// are split at midnight. Events without a known tag are ignored.
+func (ev *eventOverrides) add(evt ical.Event, loc *time.Location) {
+	unlock, closed := eventTags(evt)
+	if !unlock && !closed {
+		return
+	}
+
+	start, end := evt.Start.In(loc), evt.End.In(loc)
+
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	for day.Before(end) {
+		next := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc)
+
+		from, to := start, end
+		if from.Before(day) {
+			from = day
+		}
+		if to.After(next) {
+			to = next
+		}
+
+		r := daytime.Range{
+			From: daytime.DayTime{from.Hour(), from.Minute()},
+			To:   daytime.DayTime{to.Hour(), to.Minute()},
+		}
+		if to.Equal(next) {
+			r.To = daytime.DayTime{24, 0}
+		}
+
+		key := day.Format("2006-01-02")
+		id := "event:" + evt.UID
+
+		if closed {
+			ev.closures[key] = append(ev.closures[key], Closure{
+				ID:     id,
+				Reason: eventReason(evt.Summary),
+				AllDay: from.Equal(day) && to.Equal(next),
+				Range:  r,
+			})
+		}
+
+		if unlock {
+			ev.doors[key] = append(ev.doors[key], OpeningHour{
+				ID:    id,
+				Kind:  KindDoor,
+				Range: r,
+			})
+			sort.Sort(OpeningHourSlice(ev.doors[key]))
+		}
+
+		day = next
+	}
+}
+
+// eventTags reports whether evt is tagged with TagDoorUnlock or
+// TagClosed. Categories may omit the leading hash.
+func eventTags(evt ical.Event) (unlock bool, closed bool) {
+	words := strings.FieldsFunc(strings.ToLower(evt.Summary+" "+evt.Description), isNotTagRune)
+
+	for _, c := range evt.Categories {
+		words = append(words, "#"+strings.TrimPrefix(strings.ToLower(strings.TrimSpace(c)), "#"))
+	}
+
+	for _, w := range words {
+		switch w {
+		case TagDoorUnlock:
+			unlock = true
+		case TagClosed:
+			closed = true
+		}
+	}
+
+	return unlock, closed
+}
+
+// eventReason returns summary without any tags.
+func eventReason(summary string) string {
+	var words []string
+	for _, w := range strings.Fields(summary) {
+		switch strings.ToLower(strings.TrimFunc(w, isNotTagRune)) {
+		case TagDoorUnlock, TagClosed:
+			continue
+		}
+
+		words = append(words, w)
+	}
+
+	return strings.Join(words, " ")
+}
+
+func isNotTagRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '#' && r != '-'
+}
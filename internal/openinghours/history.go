@@ -0,0 +1,522 @@
+package openinghours
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/session"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// HistoryCollectionName is the name of the mongodb collection used to
+// store opening hour revisions.
+const HistoryCollectionName = "cis:openinghours-history"
+
+// maxAppendAttempts limits the number of retries if another process
+// stored a revision with the same version concurrently.
+const maxAppendAttempts = 5
+
+// Revision types in addition to the runtime change types.
+const (
+	// ChangeTypeSync is used for revisions that are created when the stored
+	// history does not match the configuration loaded at startup.
+	ChangeTypeSync = "sync"
+
+	// ChangeTypeBatch is used for revisions that are created by a batch
+	// of configuration changes.
+	ChangeTypeBatch = "batch"
+)
+
+// Common errors when working with the opening hour history.
+var (
+	ErrNoRevision      = errors.New("no opening hour revision found")
+	ErrHistoryDisabled = errors.New("opening hour history is disabled")
+)
+
+// historySchemas holds the configuration schemas that are part of an
+// opening hour revision in the order they must be applied.
+var historySchemas = []string{"HolidayCalendar", "OpeningHour"}
+
+// SnapshotSection is a single configuration section that is part of
+// a revision.
+type SnapshotSection struct {
+	ID      string        `json:"id" bson:"id"`
+	Schema  string        `json:"schema" bson:"schema"`
+	Options []conf.Option `json:"options" bson:"options"`
+}
+
+// OptionDiff describes the values of an option before and after a change.
+type OptionDiff struct {
+	Name   string   `json:"name" bson:"name"`
+	Before []string `json:"before,omitempty" bson:"before,omitempty"`
+	After  []string `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+// Change describes a change of a single configuration section.
+type Change struct {
+	// Type is one of "create", "update" or "delete".
+	Type   string       `json:"type" bson:"type"`
+	Schema string       `json:"schema" bson:"schema"`
+	ID     string       `json:"id" bson:"id"`
+	Diff   []OptionDiff `json:"diff,omitempty" bson:"diff,omitempty"`
+}
+
+// Revision is a snapshot of the opening hour configuration that has
+// been created by one or more changes.
+type Revision struct {
+	Version int       `json:"version" bson:"version"`
+	Time    time.Time `json:"time" bson:"time"`
+
+	// Author holds the name of the user that performed the change,
+	// if known.
+	Author string `json:"author,omitempty" bson:"author,omitempty"`
+
+	// Type is the type of the change or ChangeTypeSync.
+	Type    string   `json:"type" bson:"type"`
+	Changes []Change `json:"changes" bson:"changes"`
+
+	// Sections holds the complete configuration after the change.
+	Sections []SnapshotSection `json:"sections,omitempty" bson:"sections"`
+}
+
+// HistoryDatabase stores opening hour revisions.
+type HistoryDatabase interface {
+	// Append assigns the next version to rev and stores it.
+	Append(ctx context.Context, rev *Revision) error
+
+	// Latest returns the most recent revision or ErrNoRevision.
+	Latest(ctx context.Context) (*Revision, error)
+
+	// Get returns the revision with the given version or ErrNoRevision.
+	Get(ctx context.Context, version int) (*Revision, error)
+
+	// At returns the revision that has been active at t or ErrNoRevision.
+	At(ctx context.Context, t time.Time) (*Revision, error)
+
+	// List returns all revisions created between from and to, newest
+	// first. The configuration snapshot is not included.
+	List(ctx context.Context, from, to time.Time) ([]Revision, error)
+}
+
+type historyDatabase struct {
+	col *mongo.Collection
+}
+
+// NewHistoryDatabase returns a new history database that stores revisions
+// in the HistoryCollectionName collection of dbName. A unique index on
+// the version is created if it does not exist yet.
+func NewHistoryDatabase(ctx context.Context, cli *mongo.Client, dbName string) (HistoryDatabase, error) {
+	col := cli.Database(dbName).Collection(HistoryCollectionName)
+
+	_, err := col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "version", Value: -1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &historyDatabase{
+		col: col,
+	}, nil
+}
+
+func (db *historyDatabase) Append(ctx context.Context, rev *Revision) error {
+	var err error
+
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		var latest *Revision
+
+		latest, err = db.Latest(ctx)
+		switch {
+		case errors.Is(err, ErrNoRevision):
+			rev.Version = 1
+		case err != nil:
+			return err
+		default:
+			rev.Version = latest.Version + 1
+		}
+
+		_, err = db.col.InsertOne(ctx, rev)
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (db *historyDatabase) findOne(ctx context.Context, filter bson.M) (*Revision, error) {
+	opts := options.FindOne().SetSort(bson.M{"version": -1})
+
+	res := db.col.FindOne(ctx, filter, opts)
+	if res.Err() != nil {
+		if errors.Is(res.Err(), mongo.ErrNoDocuments) {
+			return nil, ErrNoRevision
+		}
+
+		return nil, res.Err()
+	}
+
+	var rev Revision
+	if err := res.Decode(&rev); err != nil {
+		return nil, err
+	}
+
+	return &rev, nil
+}
+
+func (db *historyDatabase) Latest(ctx context.Context) (*Revision, error) {
+	return db.findOne(ctx, bson.M{})
+}
+
+func (db *historyDatabase) Get(ctx context.Context, version int) (*Revision, error) {
+	return db.findOne(ctx, bson.M{"version": version})
+}
+
+func (db *historyDatabase) At(ctx context.Context, t time.Time) (*Revision, error) {
+	return db.findOne(ctx, bson.M{"time": bson.M{"$lte": t}})
+}
+
+func (db *historyDatabase) List(ctx context.Context, from, to time.Time) ([]Revision, error) {
+	opts := options.Find().
+		SetSort(bson.M{"version": -1}).
+		SetProjection(bson.M{"sections": 0})
+
+	cursor, err := db.col.Find(ctx, bson.M{
+		"time": bson.M{
+			"$gte": from,
+			"$lte": to,
+		},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Revision
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// EnableHistory enables recording of opening hour revisions in db. If
+// the most recent revision stored in db does not match the current
+// configuration a new revision of type ChangeTypeSync is created.
+func (ctrl *Controller) EnableHistory(ctx context.Context, db HistoryDatabase) error {
+	ctrl.historyLock.Lock()
+	defer ctrl.historyLock.Unlock()
+
+	ctrl.rw.RLock()
+	snapshot := ctrl.snapshot()
+	ctrl.rw.RUnlock()
+
+	latest, err := db.Latest(ctx)
+	switch {
+	case errors.Is(err, ErrNoRevision):
+		latest = &Revision{}
+	case err != nil:
+		return fmt.Errorf("failed to load latest revision: %w", err)
+	}
+
+	ctrl.history = db
+
+	changes := diffSnapshots(latest.Sections, snapshot)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return ctrl.appendRevision(ctx, ChangeTypeSync, changes, snapshot)
+}
+
+// AsOf returns a controller that evaluates opening hours against the
+// configuration as it was at t together with the revision used. The
+// returned controller does not receive configuration changes.
+func (ctrl *Controller) AsOf(ctx context.Context, t time.Time) (*Controller, *Revision, error) {
+	ctrl.historyLock.Lock()
+	db := ctrl.history
+	ctrl.historyLock.Unlock()
+
+	if db == nil {
+		return nil, nil, ErrHistoryDisabled
+	}
+
+	rev, err := db.At(ctx, t)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	historic, err := ctrl.fromSnapshot(ctx, rev.Sections)
+	if err != nil {
+		return nil, nil, fmt.Errorf("revision %d: %w", rev.Version, err)
+	}
+
+	return historic, rev, nil
+}
+
+// Revision returns the revision with the given version.
+func (ctrl *Controller) Revision(ctx context.Context, version int) (*Revision, error) {
+	ctrl.historyLock.Lock()
+	db := ctrl.history
+	ctrl.historyLock.Unlock()
+
+	if db == nil {
+		return nil, ErrHistoryDisabled
+	}
+
+	return db.Get(ctx, version)
+}
+
+// Revisions returns all revisions created between from and to.
+func (ctrl *Controller) Revisions(ctx context.Context, from, to time.Time) ([]Revision, error) {
+	ctrl.historyLock.Lock()
+	db := ctrl.history
+	ctrl.historyLock.Unlock()
+
+	if db == nil {
+		return nil, ErrHistoryDisabled
+	}
+
+	return db.List(ctx, from, to)
+}
+
+// fromSnapshot returns a new controller that uses the configuration
+// in sections.
+func (ctrl *Controller) fromSnapshot(ctx context.Context, sections []SnapshotSection) (*Controller, error) {
+	historic := &Controller{
+		location: ctrl.location,
+		country:  ctrl.country,
+		holidays: ctrl.holidays,
+		state:    ctrl.state.empty(),
+		sections: make(map[string]SnapshotSection),
+	}
+
+	for _, schema := range historySchemas {
+		for _, sec := range sections {
+			if sec.Schema != schema {
+				continue
+			}
+
+			var err error
+			if schema == "HolidayCalendar" {
+				err = applySnapshot(ctx, historic.applyCalendars, CalendarSpec, sec)
+			} else {
+				err = applySnapshot(ctx, historic.applyOpeningHours, Spec, sec)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", schema, sec.ID, err)
+			}
+		}
+	}
+
+	return historic, nil
+}
+
+// applySnapshot decodes sec using spec and passes it to apply as a newly
+// created instance.
+func applySnapshot[T any](ctx context.Context, apply runtime.TypedBatchFunc[T], spec conf.OptionRegistry, sec SnapshotSection) error {
+	value, err := runtime.DecodeOptions[T](spec, sec.Options)
+	if err != nil {
+		return err
+	}
+
+	return apply(ctx, []runtime.TypedChange[T]{
+		{
+			Type:    runtime.ChangeTypeCreate,
+			ID:      sec.ID,
+			Value:   &value,
+			Options: sec.Options,
+		},
+	})
+}
+
+// updateSection tracks the configuration section id and returns the
+// resulting change. It must be called with ctrl.rw locked.
+func (ctrl *Controller) updateSection(schema, changeType, id string, opts []conf.Option) Change {
+	if ctrl.sections == nil {
+		ctrl.sections = make(map[string]SnapshotSection)
+	}
+
+	before := ctrl.sections[id]
+
+	var after SnapshotSection
+	if changeType == runtime.ChangeTypeDelete {
+		delete(ctrl.sections, id)
+	} else {
+		after = SnapshotSection{
+			ID:      id,
+			Schema:  schema,
+			Options: opts,
+		}
+		ctrl.sections[id] = after
+	}
+
+	return Change{
+		Type:   changeType,
+		Schema: schema,
+		ID:     id,
+		Diff:   diffOptions(before.Options, after.Options),
+	}
+}
+
+// snapshot returns all tracked configuration sections sorted by schema
+// and ID. It must be called with ctrl.rw locked.
+func (ctrl *Controller) snapshot() []SnapshotSection {
+	result := make([]SnapshotSection, 0, len(ctrl.sections))
+	for _, sec := range ctrl.sections {
+		result = append(result, sec)
+	}
+
+	sortSnapshot(result)
+
+	return result
+}
+
+// recordRevision stores a new revision for changes if history recording
+// is enabled. Revisions with more than one change are of type
+// ChangeTypeBatch. Changes made by other instances are not recorded as
+// the instance that made the change records it already. Errors are
+// logged as the configuration change itself already happened. It must
+// be called with ctrl.historyLock held.
+func (ctrl *Controller) recordRevision(ctx context.Context, changes []Change, snapshot []SnapshotSection) {
+	if ctrl.history == nil || len(changes) == 0 || runtime.IsExternalChange(ctx) {
+		return
+	}
+
+	changeType := changes[0].Type
+	if len(changes) > 1 {
+		changeType = ChangeTypeBatch
+	}
+
+	if err := ctrl.appendRevision(ctx, changeType, changes, snapshot); err != nil {
+		log.From(ctx).Errorf("failed to record opening hour revision: %s", err)
+	}
+}
+
+// appendRevision must be called with ctrl.historyLock held.
+func (ctrl *Controller) appendRevision(ctx context.Context, changeType string, changes []Change, snapshot []SnapshotSection) error {
+	rev := &Revision{
+		Time:     time.Now(),
+		Type:     changeType,
+		Changes:  changes,
+		Sections: snapshot,
+	}
+
+	if user := session.UserFromCtx(ctx).GetUser(); user != nil {
+		rev.Author = user.GetUsername()
+		if rev.Author == "" {
+			rev.Author = user.GetId()
+		}
+	}
+
+	return ctrl.history.Append(ctx, rev)
+}
+
+func sortSnapshot(sections []SnapshotSection) {
+	sort.Slice(sections, func(i, j int) bool {
+		if sections[i].Schema != sections[j].Schema {
+			return sections[i].Schema < sections[j].Schema
+		}
+
+		return sections[i].ID < sections[j].ID
+	})
+}
+
+// diffSnapshots returns the changes required to get from old to new.
+func diffSnapshots(old, new []SnapshotSection) []Change {
+	byID := make(map[string]SnapshotSection, len(old))
+	for _, sec := range old {
+		byID[sec.ID] = sec
+	}
+
+	var changes []Change
+	for _, sec := range new {
+		before, ok := byID[sec.ID]
+		delete(byID, sec.ID)
+
+		changeType := runtime.ChangeTypeUpdate
+		if !ok {
+			changeType = runtime.ChangeTypeCreate
+		}
+
+		diff := diffOptions(before.Options, sec.Options)
+		if len(diff) == 0 {
+			continue
+		}
+
+		changes = append(changes, Change{
+			Type:   changeType,
+			Schema: sec.Schema,
+			ID:     sec.ID,
+			Diff:   diff,
+		})
+	}
+
+	removed := make([]SnapshotSection, 0, len(byID))
+	for _, sec := range byID {
+		removed = append(removed, sec)
+	}
+	sortSnapshot(removed)
+
+	for _, sec := range removed {
+		changes = append(changes, Change{
+			Type:   runtime.ChangeTypeDelete,
+			Schema: sec.Schema,
+			ID:     sec.ID,
+			Diff:   diffOptions(sec.Options, nil),
+		})
+	}
+
+	return changes
+}
+
+// diffOptions returns the options that differ between before and after.
+func diffOptions(before, after []conf.Option) []OptionDiff {
+	group := func(opts []conf.Option) map[string][]string {
+		m := make(map[string][]string)
+		for _, opt := range opts {
+			m[opt.Name] = append(m[opt.Name], opt.Value)
+		}
+
+		return m
+	}
+
+	b, a := group(before), group(after)
+
+	names := make([]string, 0, len(b)+len(a))
+	for name := range b {
+		names = append(names, name)
+	}
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var result []OptionDiff
+	for _, name := range names {
+		if strings.Join(b[name], "\x00") == strings.Join(a[name], "\x00") && len(b[name]) == len(a[name]) {
+			continue
+		}
+
+		result = append(result, OptionDiff{
+			Name:   name,
+			Before: b[name],
+			After:  a[name],
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,64 @@
+package openinghours
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	calendarv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1"
+	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/calendar/v1/calendarv1connect"
+)
+
+// holidayCacheTTL is the duration after which the public holidays of a
+// year are loaded again.
+const holidayCacheTTL = 12 * time.Hour
+
+// holidayCache caches the public holidays of whole years.
+type holidayCache struct {
+	l     sync.Mutex
+	years map[int]cachedHolidays
+}
+
+type cachedHolidays struct {
+	holidays  map[string]Holiday
+	expiresAt time.Time
+}
+
+// year returns the public holidays of year indexed by their date in the
+// format YYYY-MM-DD. Holidays are loaded from cli if they are not cached
+// or the cache entry expired. Errors are not cached.
+func (hc *holidayCache) year(ctx context.Context, cli calendarv1connect.HolidayServiceClient, year int) (map[string]Holiday, error) {
+	hc.l.Lock()
+	defer hc.l.Unlock()
+
+	if cached, ok := hc.years[year]; ok && time.Now().Before(cached.expiresAt) {
+		return cached.holidays, nil
+	}
+
+	res, err := cli.GetHoliday(ctx, connect.NewRequest(&calendarv1.GetHolidayRequest{
+		Year: uint64(year),
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	holidays := make(map[string]Holiday, len(res.Msg.Holidays))
+	for _, h := range res.Msg.Holidays {
+		holidays[h.Date] = Holiday{
+			Calendar: PublicCalendar,
+			Name:     h.LocalName,
+		}
+	}
+
+	if hc.years == nil {
+		hc.years = make(map[int]cachedHolidays)
+	}
+
+	hc.years[year] = cachedHolidays{
+		holidays:  holidays,
+		expiresAt: time.Now().Add(holidayCacheTTL),
+	}
+
+	return holidays, nil
+}
@@ -0,0 +1,269 @@
+package openinghours
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tierklinik-dobersberg/cis/pkg/daytime"
+)
+
+// ErrNoOnCallConfig is returned if the on-call shift boundaries for a
+// date cannot be determined because neither a definition nor the global
+// defaults configure them.
+var ErrNoOnCallConfig = errors.New("on-call: no shift start times configured")
+
+// OnCallShift describes an on-call shift.
+type OnCallShift string
+
+// Supported on-call shifts.
+const (
+	OnCallDay   = OnCallShift("day")
+	OnCallNight = OnCallShift("night")
+)
+
+// onCallStart holds the on-call boundaries configured by a single
+// definition. Unset boundaries are nil.
+type onCallStart struct {
+	ID         string
+	DayStart   *daytime.DayTime
+	NightStart *daytime.DayTime
+	Calendars  []string
+}
+
+func (oc onCallStart) isZero() bool {
+	return oc.DayStart == nil && oc.NightStart == nil
+}
+
+// OnCallBoundaries holds the start times of the day and the night shift
+// at a specific day. The night shift lasts until the day shift of the
+// next day starts.
+type OnCallBoundaries struct {
+	DayStart   daytime.DayTime `json:"dayStart"`
+	NightStart daytime.DayTime `json:"nightStart"`
+}
+
+// resolveOnCall returns the first boundaries set in entries. It returns
+// nil for each boundary that is not set.
+func resolveOnCall(entries []onCallStart) (dayStart, nightStart *daytime.DayTime) {
+	for _, e := range entries {
+		if dayStart == nil {
+			dayStart = e.DayStart
+		}
+		if nightStart == nil {
+			nightStart = e.NightStart
+		}
+	}
+
+	return dayStart, nightStart
+}
+
+// checkOnCallOrder ensures that the day shift starts before the night
+// shift. Boundaries that are not set are ignored.
+func checkOnCallOrder(dayStart, nightStart *daytime.DayTime) error {
+	if dayStart != nil && nightStart != nil && dayStart.AsMinutes() >= nightStart.AsMinutes() {
+		return fmt.Errorf("OnCallDayStart= %s must be before OnCallNightStart= %s", dayStart, nightStart)
+	}
+
+	return nil
+}
+
+// validateOnCall ensures that entries do not configure conflicting
+// boundaries.
+func validateOnCall(entries []onCallStart) error {
+	var day, night *onCallStart
+	for idx := range entries {
+		e := &entries[idx]
+
+		if e.DayStart != nil {
+			if day != nil && !day.DayStart.Equals(*e.DayStart) {
+				return fmt.Errorf("conflicting OnCallDayStart= in %s and %s", day.ID, e.ID)
+			}
+			day = e
+		}
+
+		if e.NightStart != nil {
+			if night != nil && !night.NightStart.Equals(*e.NightStart) {
+				return fmt.Errorf("conflicting OnCallNightStart= in %s and %s", night.ID, e.ID)
+			}
+			night = e
+		}
+	}
+
+	return nil
+}
+
+func deleteOnCall(entries []onCallStart, id string) ([]onCallStart, bool) {
+	found := false
+	res := make([]onCallStart, 0, len(entries))
+	for _, e := range entries {
+		if e.ID == id {
+			found = true
+
+			continue
+		}
+		res = append(res, e)
+	}
+
+	return res, found
+}
+
+func (s *state) addOnCall(def Definition, days []time.Weekday, dates []string) error {
+	entry, err := def.onCall()
+	if err != nil {
+		return err
+	}
+	entry.Calendars = def.HolidayCalendar
+
+	if entry.isZero() {
+		return nil
+	}
+
+	holiday := strings.ToLower(def.Holiday)
+
+	switch {
+	case holiday == "only" && len(days) > 0:
+		return fmt.Errorf("OnWeekday= is not allowed with Holiday=only")
+	case holiday != "yes" && holiday != "only" && len(days) == 0 && len(dates) == 0:
+		return fmt.Errorf("OnWeekday= or UseAtDate= is required for OnCallDayStart= and OnCallNightStart=")
+	}
+
+	if holiday == "yes" || holiday == "only" {
+		s.OnCallHoliday = append(s.OnCallHoliday, entry)
+	}
+
+	if holiday != "only" {
+		for _, d := range days {
+			s.OnCallRegular[d] = append(s.OnCallRegular[d], entry)
+		}
+	}
+
+	for _, d := range dates {
+		s.OnCallDateSpecific[d] = append(s.OnCallDateSpecific[d], entry)
+	}
+
+	return nil
+}
+
+// validateOnCall ensures that the on-call entries do not conflict and
+// that, combined with the global defaults, the day shift starts before
+// the night shift. Entries of different kinds that apply to the same
+// date are checked by onCallBoundaries.
+func (s *state) validateOnCall() error {
+	validate := func(entries []onCallStart) error {
+		if err := validateOnCall(entries); err != nil {
+			return err
+		}
+
+		return checkOnCallOrder(resolveOnCall(append(append([]onCallStart(nil), entries...), s.defaultOnCall)))
+	}
+
+	for k := range s.OnCallRegular {
+		if err := validate(s.OnCallRegular[k]); err != nil {
+			return fmt.Errorf("regular: %w", err)
+		}
+	}
+	for k := range s.OnCallDateSpecific {
+		if err := validate(s.OnCallDateSpecific[k]); err != nil {
+			return fmt.Errorf("date-specific: %w", err)
+		}
+	}
+	for _, cal := range holidayCalendarNames(s.OnCallHoliday, func(e onCallStart) []string { return e.Calendars }) {
+		if err := validate(filterCalendar(s.OnCallHoliday, cal, func(e onCallStart) []string { return e.Calendars })); err != nil {
+			return fmt.Errorf("holiday %s: %w", cal, err)
+		}
+	}
+
+	return nil
+}
+
+// OnCallBoundariesForDate returns the start times of the on-call shifts
+// at date. Date specific boundaries have precedence over holiday
+// boundaries which have precedence over regular boundaries. Boundaries
+// that are not configured at all fall back to the global defaults.
+func (ctrl *Controller) OnCallBoundariesForDate(ctx context.Context, date time.Time) (OnCallBoundaries, error) {
+	ctrl.rw.RLock()
+	defer ctrl.rw.RUnlock()
+
+	return ctrl.onCallBoundaries(ctx, ctrl.state, date)
+}
+
+func (ctrl *Controller) onCallBoundaries(ctx context.Context, s *state, date time.Time) (OnCallBoundaries, error) {
+	date = date.In(ctrl.location)
+	key := fmt.Sprintf("%02d/%02d", date.Month(), date.Day())
+
+	entries := append([]onCallStart(nil), s.OnCallDateSpecific[key]...)
+
+	// holiday boundaries are used for all matching calendars while
+	// regular ones are ignored on public holidays.
+	holidays := ctrl.holidaysAt(ctx, s, date)
+	for _, e := range s.OnCallHoliday {
+		if _, ok := calendarsMatch(e.Calendars, holidays); ok {
+			entries = append(entries, e)
+		}
+	}
+	if !isPublic(holidays) {
+		entries = append(entries, s.OnCallRegular[date.Weekday()]...)
+	}
+
+	entries = append(entries, s.defaultOnCall)
+
+	dayStart, nightStart := resolveOnCall(entries)
+	if dayStart == nil || nightStart == nil {
+		return OnCallBoundaries{}, fmt.Errorf("%s: %w", date.Format("2006-01-02"), ErrNoOnCallConfig)
+	}
+	if err := checkOnCallOrder(dayStart, nightStart); err != nil {
+		return OnCallBoundaries{}, fmt.Errorf("%s: %w", date.Format("2006-01-02"), err)
+	}
+
+	return OnCallBoundaries{
+		DayStart:   *dayStart,
+		NightStart: *nightStart,
+	}, nil
+}
+
+// OnCallAt returns the on-call shift that is active at t together with
+// the time the shift started and the time the next shift starts.
+func (ctrl *Controller) OnCallAt(ctx context.Context, t time.Time) (shift OnCallShift, since, until time.Time, err error) {
+	ctrl.rw.RLock()
+	defer ctrl.rw.RUnlock()
+
+	t = t.In(ctrl.location)
+
+	today, err := ctrl.onCallBoundaries(ctx, ctrl.state, t)
+	if err != nil {
+		return "", since, until, err
+	}
+
+	dayStart := today.DayStart.At(t, ctrl.location)
+	nightStart := today.NightStart.At(t, ctrl.location)
+
+	switch {
+	// we're still in the night shift that started yesterday
+	case t.Before(dayStart):
+		yesterdayDate := daytime.Midnight(t).AddDate(0, 0, -1)
+
+		yesterday, err := ctrl.onCallBoundaries(ctx, ctrl.state, yesterdayDate)
+		if err != nil {
+			return "", since, until, err
+		}
+
+		return OnCallNight, yesterday.NightStart.At(yesterdayDate, ctrl.location), dayStart, nil
+
+	case t.Before(nightStart):
+		return OnCallDay, dayStart, nightStart, nil
+
+	// the night shift lasts until the day shift of tomorrow starts
+	default:
+		tomorrowDate := daytime.Midnight(t).AddDate(0, 0, 1)
+
+		tomorrow, err := ctrl.onCallBoundaries(ctx, ctrl.state, tomorrowDate)
+		if err != nil {
+			return "", since, until, err
+		}
+
+		return OnCallNight, nightStart, tomorrow.DayStart.At(tomorrowDate, ctrl.location), nil
+	}
+}
@@ -2,19 +2,60 @@ package openinghours
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/tierklinik-dobersberg/cis/pkg/daytime"
 )
 
-// OpeningHour describes a single business-open time range
-// with an additional OpenBefore and CloseAfter threshold for the
+// FrameKind describes what a time frame is used for.
+type FrameKind string
+
+// Supported frame kinds.
+const (
+	// KindBusiness marks the time frames during which we are open
+	// for business. Business hours also unlock the entry door,
+	// including the OpenBefore and CloseAfter padding.
+	KindBusiness = FrameKind("business")
+
+	// KindDoor marks additional time frames during which the
+	// entry door should be unlocked.
+	KindDoor = FrameKind("door")
+
+	// KindPhone marks the time frames during which the phone/reception
+	// is staffed.
+	KindPhone = FrameKind("phone")
+)
+
+// FrameKinds holds all supported frame kinds.
+var FrameKinds = []FrameKind{KindBusiness, KindDoor, KindPhone}
+
+// ParseFrameKind parses s into a FrameKind. An empty string is parsed
+// as KindBusiness.
+func ParseFrameKind(s string) (FrameKind, error) {
+	if s == "" {
+		return KindBusiness, nil
+	}
+
+	for _, k := range FrameKinds {
+		if strings.EqualFold(string(k), s) {
+			return k, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid frame kind: %q", s)
+}
+
+// OpeningHour describes a single time range of a given kind. Business
+// hours have an additional OpenBefore and CloseAfter threshold for the
 // entry door.
 type OpeningHour struct {
 	daytime.Range
 
 	ID         string        `json:"id"`
+	Kind       FrameKind     `json:"kind"`
 	Holiday    bool          `json:"holiday"`
+	Calendars  []string      `json:"calendars,omitempty"`
 	OpenBefore time.Duration `json:"closeBefore"`
 	CloseAfter time.Duration `json:"closeAfter"`
 }
@@ -32,7 +73,18 @@ func (oh OpeningHour) EffectiveClose() time.Duration {
 }
 
 func (oh OpeningHour) String() string {
-	return fmt.Sprintf("<ID:%s %s (-%s) - %s (+%s)>", oh.ID, oh.From, oh.OpenBefore, oh.To, oh.CloseAfter)
+	return fmt.Sprintf("<ID:%s %s %s (-%s) - %s (+%s)>", oh.ID, oh.Kind, oh.From, oh.OpenBefore, oh.To, oh.CloseAfter)
+}
+
+// DoorRange returns the time range at date during which the entry door
+// should be unlocked for oh.
+func (oh OpeningHour) DoorRange(date time.Time, loc *time.Location) daytime.TimeRange {
+	tr := oh.At(date, loc)
+
+	return daytime.TimeRange{
+		From: tr.From.Add(-oh.OpenBefore),
+		To:   tr.To.Add(oh.CloseAfter),
+	}
 }
 
 // OpeningHourSlice is a slice of opening hours used
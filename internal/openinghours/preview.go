@@ -0,0 +1,126 @@
+package openinghours
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/pkg/daytime"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+// Possible door states used in DoorTransition.
+const (
+	DoorUnlock = "unlock"
+	DoorLock   = "lock"
+)
+
+// DoorTransition describes a point in time at which the entry door
+// changes its state.
+type DoorTransition struct {
+	At     time.Time `json:"at"`
+	Action string    `json:"action"`
+}
+
+// DayDiff describes how the opening hours and door transitions of a
+// single day would change if a proposed configuration change would be
+// applied.
+type DayDiff struct {
+	Date string `json:"date"`
+
+	// Current and Proposed hold the business hours of the day before and
+	// after the change.
+	Current  []daytime.TimeRange `json:"current"`
+	Proposed []daytime.TimeRange `json:"proposed"`
+
+	// CurrentDoor and ProposedDoor hold the door transitions of the day
+	// before and after the change.
+	CurrentDoor  []DoorTransition `json:"currentDoor"`
+	ProposedDoor []DoorTransition `json:"proposedDoor"`
+
+	// FramesChanged is set to true if the opening hours of the day change.
+	FramesChanged bool `json:"framesChanged"`
+
+	// DoorChanged is set to true if the door transitions of the day change.
+	DoorChanged bool `json:"doorChanged"`
+}
+
+// Preview evaluates the configuration change of changeType ("create",
+// "update" or "delete") of the OpeningHour instance id against a copy
+// of the current state and returns the difference for each day that
+// would change, starting at from and spanning days.
+// The current state of the controller is never modified.
+func (ctrl *Controller) Preview(ctx context.Context, changeType, id string, sec *conf.Section, from time.Time, days int) ([]DayDiff, error) {
+	change := runtime.TypedChange[Definition]{
+		Type: changeType,
+		ID:   id,
+	}
+
+	if changeType != runtime.ChangeTypeDelete && sec != nil {
+		value, err := runtime.DecodeOptions[Definition](Spec, sec.Options)
+		if err != nil {
+			return nil, err
+		}
+
+		change.Value = &value
+	}
+
+	deletes, defs, err := openingHourChanges([]runtime.TypedChange[Definition]{change})
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl.rw.RLock()
+	defer ctrl.rw.RUnlock()
+
+	proposedState := ctrl.state.clone()
+	if err := proposedState.applyBatch(ctx, deletes, defs); err != nil {
+		return nil, err
+	}
+
+	var result []DayDiff
+
+	date := daytime.Midnight(from.In(ctrl.location))
+	for i := 0; i < days; i++ {
+		currentDoor := ctrl.framesFromState(ctx, ctrl.state, date, KindDoor)
+		proposedDoor := ctrl.framesFromState(ctx, proposedState, date, KindDoor)
+
+		diff := DayDiff{
+			Date:         date.Format("2006-01-02"),
+			Current:      ctrl.framesFromState(ctx, ctrl.state, date, KindBusiness),
+			Proposed:     ctrl.framesFromState(ctx, proposedState, date, KindBusiness),
+			CurrentDoor:  doorTransitions(currentDoor),
+			ProposedDoor: doorTransitions(proposedDoor),
+		}
+
+		diff.FramesChanged = !slices.EqualFunc(diff.Current, diff.Proposed, timeRangeEqual)
+		diff.DoorChanged = !slices.EqualFunc(diff.CurrentDoor, diff.ProposedDoor, func(a, b DoorTransition) bool {
+			return a.Action == b.Action && a.At.Equal(b.At)
+		})
+
+		if diff.FramesChanged || diff.DoorChanged {
+			result = append(result, diff)
+		}
+
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return result, nil
+}
+
+func doorTransitions(frames []daytime.TimeRange) []DoorTransition {
+	result := make([]DoorTransition, 0, len(frames)*2)
+	for _, f := range frames {
+		result = append(result,
+			DoorTransition{At: f.From, Action: DoorUnlock},
+			DoorTransition{At: f.To, Action: DoorLock},
+		)
+	}
+
+	return result
+}
+
+func timeRangeEqual(a, b daytime.TimeRange) bool {
+	return a.From.Equal(b.From) && a.To.Equal(b.To)
+}
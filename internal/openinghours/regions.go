@@ -0,0 +1,58 @@
+package openinghours
+
+import (
+	"sort"
+	"time"
+
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+// regionalHolidays holds the regional holidays of federal states indexed
+// by their ISO 3166-2 code. The holiday service only reports country
+// wide public holidays.
+var regionalHolidays = map[string][]calendarEntry{
+	"AT-1": {recurring("Hl. Martin", time.November, 11)},
+	"AT-2": {recurring("Hl. Josef", time.March, 19), recurring("Tag der Volksabstimmung", time.October, 10)},
+	"AT-3": {recurring("Hl. Leopold", time.November, 15)},
+	"AT-4": {recurring("Hl. Florian", time.May, 4)},
+	"AT-5": {recurring("Hl. Rupert", time.September, 24)},
+	"AT-6": {recurring("Hl. Josef", time.March, 19)},
+	"AT-7": {recurring("Hl. Josef", time.March, 19)},
+	"AT-8": {recurring("Hl. Josef", time.March, 19)},
+	"AT-9": {recurring("Hl. Leopold", time.November, 15)},
+}
+
+// regionNames holds display names for the regions in regionalHolidays.
+var regionNames = map[string]string{
+	"AT-1": "Burgenland",
+	"AT-2": "Kärnten",
+	"AT-3": "Niederösterreich",
+	"AT-4": "Oberösterreich",
+	"AT-5": "Salzburg",
+	"AT-6": "Steiermark",
+	"AT-7": "Tirol",
+	"AT-8": "Vorarlberg",
+	"AT-9": "Wien",
+}
+
+func recurring(name string, month time.Month, day int) calendarEntry {
+	d := civilDate{month: month, day: day}
+
+	return calendarEntry{name: name, from: d, to: d}
+}
+
+func regionValues() []runtime.PossibleValue {
+	values := make([]runtime.PossibleValue, 0, len(regionNames))
+	for code, name := range regionNames {
+		values = append(values, runtime.PossibleValue{
+			Value:   code,
+			Display: name,
+		})
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Value.(string) < values[j].Value.(string)
+	})
+
+	return values
+}
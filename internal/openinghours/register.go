@@ -3,7 +3,7 @@ package openinghours
 import "github.com/tierklinik-dobersberg/cis/runtime"
 
 var (
-	configBuilder = runtime.NewConfigSchemaBuilder(addOpeningHours)
+	configBuilder = runtime.NewConfigSchemaBuilder(addOpeningHours, addHolidayCalendars)
 
 	// AddToSchema adds the opening hour definition/config spec to the
 	// provided cofig schema.
@@ -2,7 +2,9 @@ package openinghours
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -23,17 +25,58 @@ type state struct {
 	// public holidays.
 	Holiday []OpeningHour `json:"holiday"`
 
+	// ClosedRegular, ClosedDateSpecific and ClosedHoliday hold
+	// closures. In contrast to opening hours, date specific closures
+	// do not replace regular or holiday closures but are applied
+	// on top.
+	ClosedRegular      map[time.Weekday][]Closure `json:"closedRegular"`
+	ClosedDateSpecific map[string][]Closure       `json:"closedDateSpecific"`
+	ClosedHoliday      []Closure                  `json:"closedHoliday"`
+
+	// OnCallRegular, OnCallDateSpecific and OnCallHoliday hold the
+	// start times for the on-call shifts.
+	OnCallRegular      map[time.Weekday][]onCallStart `json:"-"`
+	OnCallDateSpecific map[string][]onCallStart       `json:"-"`
+	OnCallHoliday      []onCallStart                  `json:"-"`
+
+	// Calendars holds all configured holiday calendars sorted by name.
+	Calendars []holidayCalendar `json:"-"`
+
+	// defaultOnCall holds the global default on-call start times.
+	defaultOnCall onCallStart
+
 	defaultCloseAfter time.Duration
 	defaultOpenBefore time.Duration
 }
 
+// empty returns a new state without any opening hours that uses the
+// same defaults as s.
+func (s *state) empty() *state {
+	return &state{
+		Regular:            make(map[time.Weekday][]OpeningHour),
+		DateSpecific:       make(map[string][]OpeningHour),
+		ClosedRegular:      make(map[time.Weekday][]Closure),
+		ClosedDateSpecific: make(map[string][]Closure),
+		OnCallRegular:      make(map[time.Weekday][]onCallStart),
+		OnCallDateSpecific: make(map[string][]onCallStart),
+		defaultOnCall:      s.defaultOnCall,
+		defaultCloseAfter:  s.defaultCloseAfter,
+		defaultOpenBefore:  s.defaultOpenBefore,
+	}
+}
+
 func (s *state) clone() *state {
 	newState := &state{
-		Regular:           make(map[time.Weekday][]OpeningHour, len(s.Regular)),
-		DateSpecific:      make(map[string][]OpeningHour, len(s.DateSpecific)),
-		Holiday:           make([]OpeningHour, len(s.Holiday)),
-		defaultCloseAfter: s.defaultCloseAfter,
-		defaultOpenBefore: s.defaultOpenBefore,
+		Regular:            make(map[time.Weekday][]OpeningHour, len(s.Regular)),
+		DateSpecific:       make(map[string][]OpeningHour, len(s.DateSpecific)),
+		Holiday:            make([]OpeningHour, len(s.Holiday)),
+		ClosedRegular:      make(map[time.Weekday][]Closure, len(s.ClosedRegular)),
+		ClosedDateSpecific: make(map[string][]Closure, len(s.ClosedDateSpecific)),
+		OnCallRegular:      make(map[time.Weekday][]onCallStart, len(s.OnCallRegular)),
+		OnCallDateSpecific: make(map[string][]onCallStart, len(s.OnCallDateSpecific)),
+		defaultOnCall:      s.defaultOnCall,
+		defaultCloseAfter:  s.defaultCloseAfter,
+		defaultOpenBefore:  s.defaultOpenBefore,
 	}
 
 	// clone the current state
@@ -53,6 +96,39 @@ func (s *state) clone() *state {
 		newState.DateSpecific[dateStr] = clone
 	}
 
+	newState.ClosedHoliday = make([]Closure, len(s.ClosedHoliday))
+	copy(newState.ClosedHoliday, s.ClosedHoliday)
+
+	for wd, c := range s.ClosedRegular {
+		clone := make([]Closure, len(c))
+		copy(clone, c)
+		newState.ClosedRegular[wd] = clone
+	}
+
+	for dateStr, c := range s.ClosedDateSpecific {
+		clone := make([]Closure, len(c))
+		copy(clone, c)
+		newState.ClosedDateSpecific[dateStr] = clone
+	}
+
+	newState.OnCallHoliday = make([]onCallStart, len(s.OnCallHoliday))
+	copy(newState.OnCallHoliday, s.OnCallHoliday)
+
+	for wd, oc := range s.OnCallRegular {
+		clone := make([]onCallStart, len(oc))
+		copy(clone, oc)
+		newState.OnCallRegular[wd] = clone
+	}
+
+	for dateStr, oc := range s.OnCallDateSpecific {
+		clone := make([]onCallStart, len(oc))
+		copy(clone, oc)
+		newState.OnCallDateSpecific[dateStr] = clone
+	}
+
+	newState.Calendars = make([]holidayCalendar, len(s.Calendars))
+	copy(newState.Calendars, s.Calendars)
+
 	return newState
 }
 
@@ -68,8 +144,16 @@ func (s *state) sortAndValidate() error {
 			return fmt.Errorf("date-specific: %w", err)
 		}
 	}
-	if err := sortAndValidate(s.Holiday); err != nil {
-		return fmt.Errorf("holiday: %w", err)
+	// holiday hours only overlap if they are used for the same calendar.
+	sort.Sort(OpeningHourSlice(s.Holiday))
+	for _, cal := range holidayCalendarNames(s.Holiday, func(oh OpeningHour) []string { return oh.Calendars }) {
+		if err := sortAndValidate(filterCalendar(s.Holiday, cal, func(oh OpeningHour) []string { return oh.Calendars })); err != nil {
+			return fmt.Errorf("holiday %s: %w", cal, err)
+		}
+	}
+
+	if err := s.validateOnCall(); err != nil {
+		return fmt.Errorf("on-call: %w", err)
 	}
 
 	return nil
@@ -114,6 +198,38 @@ func (s *state) deleteOpeningHour(_ context.Context, id string) error {
 	}
 	s.Holiday = res
 
+	for weekDay, closures := range s.ClosedRegular {
+		res, ok := deleteClosure(closures, id)
+		found = found || ok
+		s.ClosedRegular[weekDay] = res
+	}
+
+	for dateStr, closures := range s.ClosedDateSpecific {
+		res, ok := deleteClosure(closures, id)
+		found = found || ok
+		s.ClosedDateSpecific[dateStr] = res
+	}
+
+	closedHoliday, ok := deleteClosure(s.ClosedHoliday, id)
+	found = found || ok
+	s.ClosedHoliday = closedHoliday
+
+	for weekDay, entries := range s.OnCallRegular {
+		res, ok := deleteOnCall(entries, id)
+		found = found || ok
+		s.OnCallRegular[weekDay] = res
+	}
+
+	for dateStr, entries := range s.OnCallDateSpecific {
+		res, ok := deleteOnCall(entries, id)
+		found = found || ok
+		s.OnCallDateSpecific[dateStr] = res
+	}
+
+	onCallHoliday, ok := deleteOnCall(s.OnCallHoliday, id)
+	found = found || ok
+	s.OnCallHoliday = onCallHoliday
+
 	if !found {
 		return fmt.Errorf("opening-hour: id %q not found in controller state: %+v", id, s)
 	}
@@ -121,6 +237,41 @@ func (s *state) deleteOpeningHour(_ context.Context, id string) error {
 	return nil
 }
 
+// applyBatch removes all opening hours in deletes before adding defs so
+// only the final state is validated.
+func (s *state) applyBatch(ctx context.Context, deletes []string, defs []Definition) error {
+	var errs []error
+
+	for _, id := range deletes {
+		if err := s.deleteOpeningHour(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete: %w", err))
+		}
+	}
+
+	if len(defs) > 0 {
+		if err := s.addOpeningHours(ctx, defs...); err != nil {
+			errs = append(errs, fmt.Errorf("failed to create: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func deleteClosure(closures []Closure, id string) ([]Closure, bool) {
+	found := false
+	res := make([]Closure, 0, len(closures))
+	for _, c := range closures {
+		if c.ID == id {
+			found = true
+
+			continue
+		}
+		res = append(res, c)
+	}
+
+	return res, found
+}
+
 func (s *state) parseWeekDays(openingHourDef Definition) ([]time.Weekday, error) {
 	days := make([]time.Weekday, 0, len(openingHourDef.OnWeekday))
 	for _, d := range openingHourDef.OnWeekday {
@@ -170,6 +321,11 @@ func (s *state) parseDates(c Definition) ([]string, error) {
 }
 
 func (s *state) getTimeRanges(openingHourDef Definition) ([]OpeningHour, error) {
+	kind, err := ParseFrameKind(openingHourDef.Kind)
+	if err != nil {
+		return nil, err
+	}
+
 	ranges := make([]OpeningHour, 0, len(openingHourDef.TimeRanges))
 	for _, r := range openingHourDef.TimeRanges {
 		timeRange, err := daytime.ParseRange(r)
@@ -177,22 +333,27 @@ func (s *state) getTimeRanges(openingHourDef Definition) ([]OpeningHour, error)
 			return nil, err
 		}
 
-		closeAfter := openingHourDef.CloseAfter
-		if closeAfter == 0 {
-			closeAfter = s.defaultCloseAfter
+		oh := OpeningHour{
+			ID:        openingHourDef.id,
+			Kind:      kind,
+			Range:     timeRange,
+			Calendars: openingHourDef.HolidayCalendar,
 		}
 
-		openBefore := openingHourDef.OpenBefore
-		if openBefore == 0 {
-			openBefore = s.defaultOpenBefore
+		// door padding is only applied to business hours.
+		if kind == KindBusiness {
+			oh.CloseAfter = openingHourDef.CloseAfter
+			if oh.CloseAfter == 0 {
+				oh.CloseAfter = s.defaultCloseAfter
+			}
+
+			oh.OpenBefore = openingHourDef.OpenBefore
+			if oh.OpenBefore == 0 {
+				oh.OpenBefore = s.defaultOpenBefore
+			}
 		}
 
-		ranges = append(ranges, OpeningHour{
-			ID:         openingHourDef.id,
-			Range:      timeRange,
-			CloseAfter: closeAfter,
-			OpenBefore: openBefore,
-		})
+		ranges = append(ranges, oh)
 	}
 
 	return ranges, nil
@@ -211,12 +372,29 @@ func (s *state) addOpeningHours(_ context.Context, timeRanges ...Definition) err
 			return err
 		}
 
+		if openingHourDef.Closed {
+			if err := s.addClosure(openingHourDef, days, dates); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := s.addOnCall(openingHourDef, days, dates); err != nil {
+			return err
+		}
+
 		ranges, err := s.getTimeRanges(openingHourDef)
 		if err != nil {
 			return err
 		}
 
 		if len(ranges) == 0 {
+			// definitions may only configure on-call start times.
+			if openingHourDef.OnCallDayStart != "" || openingHourDef.OnCallNightStart != "" {
+				continue
+			}
+
 			return fmt.Errorf("no time ranges defined in opening hour")
 		}
 
@@ -251,3 +429,50 @@ func (s *state) addOpeningHours(_ context.Context, timeRanges ...Definition) err
 
 	return nil
 }
+
+func (s *state) addClosure(def Definition, days []time.Weekday, dates []string) error {
+	var closures []Closure
+
+	if len(def.TimeRanges) == 0 {
+		closures = append(closures, Closure{
+			ID:        def.id,
+			Reason:    def.Reason,
+			AllDay:    true,
+			Calendars: def.HolidayCalendar,
+		})
+	}
+
+	for _, r := range def.TimeRanges {
+		timeRange, err := daytime.ParseRange(r)
+		if err != nil {
+			return err
+		}
+
+		closures = append(closures, Closure{
+			ID:        def.id,
+			Reason:    def.Reason,
+			Range:     timeRange,
+			Calendars: def.HolidayCalendar,
+		})
+	}
+
+	holiday := strings.ToLower(def.Holiday)
+
+	if holiday == "yes" || holiday == "only" {
+		s.ClosedHoliday = append(s.ClosedHoliday, closures...)
+	}
+
+	if holiday != "only" {
+		for _, d := range days {
+			s.ClosedRegular[d] = append(s.ClosedRegular[d], closures...)
+		}
+	} else if len(days) > 0 {
+		return fmt.Errorf("stanza Days= not allowed with Holiday=only")
+	}
+
+	for _, d := range dates {
+		s.ClosedDateSpecific[d] = append(s.ClosedDateSpecific[d], closures...)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+// Package caldav implements the small subset of CalDAV (RFC 4791) that is
+// required to read events from a calendar collection together with an
+// in-memory server that can be used as a local stand-in.
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tierklinik-dobersberg/cis/pkg/ical"
+)
+
+// Namespaces used in CalDAV requests and responses.
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+)
+
+// timeFormat is the UTC date-time format used in time-range filters.
+const timeFormat = "20060102T150405Z"
+
+// Client reads events from a single CalDAV calendar collection.
+type Client struct {
+	url      string
+	username string
+	password string
+	cli      *http.Client
+	location *time.Location
+}
+
+// NewClient returns a new client for the calendar collection at
+// collectionURL. Credentials for basic authentication may be
+// included in the user-info part of the URL. Floating times are
+// interpreted in loc.
+func NewClient(collectionURL string, httpClient *http.Client, loc *time.Location) (*Client, error) {
+	u, err := url.Parse(collectionURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collection URL: %w", err)
+	}
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	cli := &Client{
+		cli:      httpClient,
+		location: loc,
+	}
+
+	if u.User != nil {
+		cli.username = u.User.Username()
+		cli.password, _ = u.User.Password()
+		u.User = nil
+	}
+
+	cli.url = u.String()
+
+	return cli, nil
+}
+
+// Events returns all events of the collection that overlap with the
+// time range from - to. Recurring events are expanded by the server.
+func (cli *Client) Events(ctx context.Context, from, to time.Time) ([]ical.Event, error) {
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <C:calendar-data>
+      <C:expand start="%[1]s" end="%[2]s"/>
+    </C:calendar-data>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT">
+        <C:time-range start="%[1]s" end="%[2]s"/>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`, from.UTC().Format(timeFormat), to.UTC().Format(timeFormat))
+
+	req, err := http.NewRequestWithContext(ctx, "REPORT", cli.url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "1")
+
+	if cli.username != "" {
+		req.SetBasicAuth(cli.username, cli.password)
+	}
+
+	res, err := cli.cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(res.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	var events []ical.Event
+	for _, r := range ms.Responses {
+		for _, ps := range r.PropStats {
+			if ps.Prop.CalendarData == "" {
+				continue
+			}
+
+			cal, err := ical.Decode(strings.NewReader(ps.Prop.CalendarData), cli.location)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", r.Href, err)
+			}
+
+			events = append(events, cal.Events...)
+		}
+	}
+
+	return events, nil
+}
+
+type multistatus struct {
+	XMLName   xml.Name   `xml:"DAV: multistatus"`
+	Responses []response `xml:"DAV: response"`
+}
+
+type response struct {
+	Href      string     `xml:"DAV: href"`
+	PropStats []propstat `xml:"DAV: propstat"`
+}
+
+type propstat struct {
+	Prop   prop   `xml:"DAV: prop"`
+	Status string `xml:"DAV: status"`
+}
+
+type prop struct {
+	CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+}
+
+// writeMultistatus encodes responses as a DAV multistatus document.
+func writeMultistatus(w io.Writer, responses []response) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<D:multistatus xmlns:D="` + nsDAV + `" xmlns:C="` + nsCalDAV + `">`)
+
+	for _, r := range responses {
+		buf.WriteString("<D:response><D:href>")
+		_ = xml.EscapeText(&buf, []byte(r.Href))
+		buf.WriteString("</D:href>")
+
+		for _, ps := range r.PropStats {
+			buf.WriteString("<D:propstat><D:prop><C:calendar-data>")
+			_ = xml.EscapeText(&buf, []byte(ps.Prop.CalendarData))
+			buf.WriteString("</C:calendar-data></D:prop><D:status>")
+			_ = xml.EscapeText(&buf, []byte(ps.Status))
+			buf.WriteString("</D:status></D:propstat>")
+		}
+
+		buf.WriteString("</D:response>")
+	}
+
+	buf.WriteString("</D:multistatus>")
+
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
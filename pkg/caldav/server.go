@@ -0,0 +1,237 @@
+package caldav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tierklinik-dobersberg/cis/pkg/ical"
+)
+
+// Server is a minimal in-memory CalDAV server that serves a single
+// calendar collection. Event resources can be created, read and deleted
+// using PUT, GET and DELETE below the collection path while calendar-query
+// REPORTs on the collection return all events that overlap with the
+// requested time-range. It is meant as a local stand-in for a real
+// CalDAV server during development and tests and does not implement
+// recurrence expansion, sync tokens or authentication.
+type Server struct {
+	collection string
+	location   *time.Location
+
+	l         sync.RWMutex
+	resources map[string][]byte
+}
+
+// NewServer returns a new server for the collection at collectionPath.
+// Floating times are interpreted in loc.
+func NewServer(collectionPath string, loc *time.Location) *Server {
+	return &Server{
+		collection: "/" + strings.Trim(collectionPath, "/") + "/",
+		location:   loc,
+		resources:  make(map[string][]byte),
+	}
+}
+
+// PutEvent stores evt as a new resource named after its UID. An existing
+// resource with the same UID is replaced.
+func (srv *Server) PutEvent(evt ical.Event) error {
+	var buf bytes.Buffer
+
+	cal := ical.Calendar{
+		ProductID: "-//tierklinik-dobersberg//cis caldav//EN",
+		Events:    []ical.Event{evt},
+	}
+	if err := cal.Encode(&buf); err != nil {
+		return err
+	}
+
+	srv.l.Lock()
+	defer srv.l.Unlock()
+
+	srv.resources[srv.collection+evt.UID+".ics"] = buf.Bytes()
+
+	return nil
+}
+
+// DeleteEvent deletes the resource of the event with the given UID.
+func (srv *Server) DeleteEvent(uid string) {
+	srv.l.Lock()
+	defer srv.l.Unlock()
+
+	delete(srv.resources, srv.collection+uid+".ics")
+}
+
+// ServeHTTP implements http.Handler.
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("DAV", "1, calendar-access")
+
+	isCollection := strings.TrimSuffix(r.URL.Path, "/")+"/" == srv.collection
+
+	switch {
+	case r.Method == http.MethodOptions:
+		w.Header().Set("Allow", "OPTIONS, GET, PUT, DELETE, REPORT")
+		w.WriteHeader(http.StatusOK)
+
+	case r.Method == "REPORT" && isCollection:
+		srv.report(w, r)
+
+	case isCollection || path.Dir(r.URL.Path)+"/" != srv.collection:
+		http.Error(w, "not found", http.StatusNotFound)
+
+	case r.Method == http.MethodGet:
+		srv.l.RLock()
+		blob, ok := srv.resources[r.URL.Path]
+		srv.l.RUnlock()
+
+		if !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		_, _ = w.Write(blob)
+
+	case r.Method == http.MethodPut:
+		blob, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		if _, err := ical.Decode(bytes.NewReader(blob), srv.location); err != nil {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+
+			return
+		}
+
+		srv.l.Lock()
+		_, exists := srv.resources[r.URL.Path]
+		srv.resources[r.URL.Path] = blob
+		srv.l.Unlock()
+
+		if exists {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+
+	case r.Method == http.MethodDelete:
+		srv.l.Lock()
+		_, exists := srv.resources[r.URL.Path]
+		delete(srv.resources, r.URL.Path)
+		srv.l.Unlock()
+
+		if !exists {
+			http.Error(w, "not found", http.StatusNotFound)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// calendarQuery is the subset of a calendar-query REPORT body that
+// is evaluated by the server.
+type calendarQuery struct {
+	XMLName   xml.Name `xml:"urn:ietf:params:xml:ns:caldav calendar-query"`
+	TimeRange struct {
+		Start string `xml:"start,attr"`
+		End   string `xml:"end,attr"`
+	} `xml:"urn:ietf:params:xml:ns:caldav filter>comp-filter>comp-filter>time-range"`
+}
+
+func (srv *Server) report(w http.ResponseWriter, r *http.Request) {
+	var query calendarQuery
+	if err := xml.NewDecoder(r.Body).Decode(&query); err != nil {
+		http.Error(w, "invalid calendar-query: "+err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var from, to time.Time
+	if query.TimeRange.Start != "" {
+		var err error
+		if from, err = time.Parse(timeFormat, query.TimeRange.Start); err != nil {
+			http.Error(w, "invalid time-range start: "+err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	}
+	if query.TimeRange.End != "" {
+		var err error
+		if to, err = time.Parse(timeFormat, query.TimeRange.End); err != nil {
+			http.Error(w, "invalid time-range end: "+err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	srv.l.RLock()
+	defer srv.l.RUnlock()
+
+	hrefs := make([]string, 0, len(srv.resources))
+	for href := range srv.resources {
+		hrefs = append(hrefs, href)
+	}
+	sort.Strings(hrefs)
+
+	responses := make([]response, 0, len(hrefs))
+	for _, href := range hrefs {
+		blob := srv.resources[href]
+
+		cal, err := ical.Decode(bytes.NewReader(blob), srv.location)
+		if err != nil {
+			continue
+		}
+
+		if !overlaps(cal.Events, from, to) {
+			continue
+		}
+
+		responses = append(responses, response{
+			Href: href,
+			PropStats: []propstat{
+				{
+					Prop:   prop{CalendarData: string(blob)},
+					Status: "HTTP/1.1 200 OK",
+				},
+			},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+
+	_ = writeMultistatus(w, responses)
+}
+
+// overlaps reports whether any of events overlaps with from - to. A zero
+// from or to means unbounded.
+func overlaps(events []ical.Event, from, to time.Time) bool {
+	for _, evt := range events {
+		if !to.IsZero() && !evt.Start.Before(to) {
+			continue
+		}
+
+		if !from.IsZero() && !evt.End.After(from) {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
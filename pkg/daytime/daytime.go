@@ -33,14 +33,18 @@ func (dt DayTime) AsDuration() time.Duration {
 	return time.Duration(dt.AsMinutes()) * time.Minute
 }
 
-// At returns a new time.Time that represents dt at t.
+// At returns a new time.Time that represents the wall-clock time dt at
+// the date of t in loc. If loc is nil the location of t is used.
+// Note that dt is not added as a duration to midnight because days
+// may be shorter or longer than 24 hours when daylight saving time
+// starts or ends. A wall-clock time that does not exist because it
+// has been skipped is normalized as described by time.Date.
 func (dt DayTime) At(t time.Time, loc *time.Location) time.Time {
 	if loc == nil {
 		loc = t.Location()
 	}
-	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
 
-	return midnight.Add(dt.AsDuration())
+	return time.Date(t.Year(), t.Month(), t.Day(), dt[0], dt[1], 0, 0, loc)
 }
 
 func (dt DayTime) String() string {
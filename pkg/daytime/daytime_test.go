@@ -3,6 +3,8 @@ package daytime_test
 import (
 	"fmt"
 	"testing"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tierklinik-dobersberg/cis/pkg/daytime"
@@ -103,3 +105,146 @@ func TestParseTimeRange(t *testing.T) {
 		assert.Equal(t, c.Out, r, msg)
 	}
 }
+
+func TestDayTimeAtDST(t *testing.T) {
+	t.Parallel()
+
+	vienna, err := time.LoadLocation("Europe/Vienna")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// In 2024 daylight saving time started on March 31 at 02:00 (skipping
+	// to 03:00) and ended on October 27 at 03:00 (repeating 02:00 - 03:00).
+	cases := []struct {
+		Name string
+		Date time.Time
+		In   daytime.DayTime
+		Out  string
+	}{
+		{
+			Name: "regular day",
+			Date: time.Date(2024, 3, 30, 0, 0, 0, 0, vienna),
+			In:   daytime.DayTime{8, 0},
+			Out:  "2024-03-30T08:00:00+01:00",
+		},
+		{
+			Name: "before DST starts",
+			Date: time.Date(2024, 3, 31, 0, 0, 0, 0, vienna),
+			In:   daytime.DayTime{1, 30},
+			Out:  "2024-03-31T01:30:00+01:00",
+		},
+		{
+			Name: "skipped hour is normalized",
+			Date: time.Date(2024, 3, 31, 0, 0, 0, 0, vienna),
+			In:   daytime.DayTime{2, 30},
+			Out:  "2024-03-31T03:30:00+02:00",
+		},
+		{
+			Name: "after DST started",
+			Date: time.Date(2024, 3, 31, 0, 0, 0, 0, vienna),
+			In:   daytime.DayTime{8, 0},
+			Out:  "2024-03-31T08:00:00+02:00",
+		},
+		{
+			Name: "after DST started with date in the afternoon",
+			Date: time.Date(2024, 3, 31, 18, 0, 0, 0, vienna),
+			In:   daytime.DayTime{23, 59},
+			Out:  "2024-03-31T23:59:00+02:00",
+		},
+		{
+			Name: "before DST ends",
+			Date: time.Date(2024, 10, 27, 0, 0, 0, 0, vienna),
+			In:   daytime.DayTime{1, 30},
+			Out:  "2024-10-27T01:30:00+02:00",
+		},
+		{
+			Name: "after DST ended",
+			Date: time.Date(2024, 10, 27, 0, 0, 0, 0, vienna),
+			In:   daytime.DayTime{8, 0},
+			Out:  "2024-10-27T08:00:00+01:00",
+		},
+		{
+			Name: "after DST ended at the end of the day",
+			Date: time.Date(2024, 10, 27, 0, 0, 0, 0, vienna),
+			In:   daytime.DayTime{23, 30},
+			Out:  "2024-10-27T23:30:00+01:00",
+		},
+	}
+
+	for _, c := range cases {
+		res := c.In.At(c.Date, vienna)
+		assert.Equal(t, c.Out, res.Format(time.RFC3339), c.Name)
+	}
+
+	// the repeated hour is ambiguous so we only check the wall-clock time.
+	repeated := daytime.DayTime{2, 30}.At(time.Date(2024, 10, 27, 0, 0, 0, 0, vienna), vienna)
+	assert.Equal(t, "2024-10-27 02:30", repeated.Format("2006-01-02 15:04"))
+}
+
+func TestRangeAtDST(t *testing.T) {
+	t.Parallel()
+
+	vienna, err := time.LoadLocation("Europe/Vienna")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name     string
+		Date     time.Time
+		In       daytime.Range
+		From     string
+		To       string
+		Duration time.Duration
+	}{
+		{
+			Name:     "regular day",
+			Date:     time.Date(2024, 3, 30, 0, 0, 0, 0, vienna),
+			In:       daytime.Range{From: daytime.DayTime{1, 0}, To: daytime.DayTime{4, 0}},
+			From:     "01:00",
+			To:       "04:00",
+			Duration: 3 * time.Hour,
+		},
+		{
+			Name:     "range spans the skipped hour",
+			Date:     time.Date(2024, 3, 31, 0, 0, 0, 0, vienna),
+			In:       daytime.Range{From: daytime.DayTime{1, 0}, To: daytime.DayTime{4, 0}},
+			From:     "01:00",
+			To:       "04:00",
+			Duration: 2 * time.Hour,
+		},
+		{
+			// 02:00 does not exist and is normalized to 03:00
+			Name:     "range ends at the skipped hour",
+			Date:     time.Date(2024, 3, 31, 0, 0, 0, 0, vienna),
+			In:       daytime.Range{From: daytime.DayTime{0, 0}, To: daytime.DayTime{2, 0}},
+			From:     "00:00",
+			To:       "03:00",
+			Duration: 2 * time.Hour,
+		},
+		{
+			Name:     "range spans the repeated hour",
+			Date:     time.Date(2024, 10, 27, 0, 0, 0, 0, vienna),
+			In:       daytime.Range{From: daytime.DayTime{1, 0}, To: daytime.DayTime{4, 0}},
+			From:     "01:00",
+			To:       "04:00",
+			Duration: 4 * time.Hour,
+		},
+		{
+			Name:     "range after the repeated hour",
+			Date:     time.Date(2024, 10, 27, 0, 0, 0, 0, vienna),
+			In:       daytime.Range{From: daytime.DayTime{8, 0}, To: daytime.DayTime{12, 0}},
+			From:     "08:00",
+			To:       "12:00",
+			Duration: 4 * time.Hour,
+		},
+	}
+
+	for _, c := range cases {
+		tr := c.In.At(c.Date, vienna)
+		assert.Equal(t, c.From, tr.From.Format("15:04"), c.Name)
+		assert.Equal(t, c.To, tr.To.Format("15:04"), c.Name)
+		assert.Equal(t, c.Duration, tr.To.Sub(tr.From), c.Name)
+	}
+}
@@ -0,0 +1,237 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Decode parses the iCalendar stream r and returns all VEVENT objects it
+// contains. Floating date-times and dates are interpreted in loc. Only
+// the properties supported by Event are decoded, recurrence rules are
+// ignored so callers that need recurring events should ask the server to
+// expand them.
+func Decode(r io.Reader, loc *time.Location) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		cal   = new(Calendar)
+		stack []string
+		evt   *Event
+		hasTo bool
+	)
+
+	for idx, l := range lines {
+		name, params, value, err := parseLine(l)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", idx+1, err)
+		}
+
+		switch name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(value))
+			if len(stack) == 2 && stack[1] == "VEVENT" {
+				evt = new(Event)
+				hasTo = false
+			}
+
+			continue
+
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", idx+1, value)
+			}
+
+			if len(stack) == 2 && evt != nil {
+				if !hasTo {
+					// RFC 5545 section 3.6.1: without DTEND all-day
+					// events last for one day and all other events
+					// end at DTSTART.
+					evt.End = evt.Start
+					if evt.AllDay {
+						evt.End = evt.Start.AddDate(0, 0, 1)
+					}
+				}
+
+				cal.Events = append(cal.Events, *evt)
+				evt = nil
+			}
+
+			stack = stack[:len(stack)-1]
+
+			continue
+		}
+
+		switch {
+		case len(stack) == 1 && stack[0] == "VCALENDAR":
+			switch name {
+			case "PRODID":
+				cal.ProductID = value
+			case "X-WR-CALNAME":
+				cal.Name = unescape(value)
+			}
+
+		case len(stack) == 2 && evt != nil:
+			switch name {
+			case "UID":
+				evt.UID = value
+			case "SUMMARY":
+				evt.Summary = unescape(value)
+			case "DESCRIPTION":
+				evt.Description = unescape(value)
+			case "CATEGORIES":
+				for _, c := range splitList(value) {
+					evt.Categories = append(evt.Categories, unescape(c))
+				}
+			case "TRANSP":
+				evt.Transparent = strings.EqualFold(value, "TRANSPARENT")
+			case "DTSTART":
+				evt.Start, evt.AllDay, err = parseTime(value, params, loc)
+			case "DTEND":
+				evt.End, _, err = parseTime(value, params, loc)
+				hasTo = true
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", idx+1, name, err)
+			}
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1])
+	}
+
+	return cal, nil
+}
+
+// unfold reads all content lines from r and joins folded lines.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		l := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += l[1:]
+
+			continue
+		}
+
+		if l == "" {
+			continue
+		}
+
+		lines = append(lines, l)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// parseLine splits a content line into its name, parameters and value.
+// Parameter names are upper-cased, quoted parameter values are not
+// supported to contain colons.
+func parseLine(l string) (string, map[string]string, string, error) {
+	colon := indexUnquoted(l, ':')
+	if colon < 0 {
+		return "", nil, "", fmt.Errorf("invalid content line %q", l)
+	}
+
+	parts := strings.Split(l[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+
+	for _, p := range parts[1:] {
+		key, value, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+
+	return strings.ToUpper(parts[0]), params, l[colon+1:], nil
+}
+
+func indexUnquoted(s string, c byte) int {
+	quoted := false
+	for idx := 0; idx < len(s); idx++ {
+		switch s[idx] {
+		case '"':
+			quoted = !quoted
+		case c:
+			if !quoted {
+				return idx
+			}
+		}
+	}
+
+	return -1
+}
+
+// parseTime parses a DATE or DATE-TIME value. The second return value
+// reports whether value is a DATE.
+func parseTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if tzid := params["TZID"]; tzid != "" {
+		l, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("unsupported TZID %q: %w", tzid, err)
+		}
+
+		loc = l
+	}
+
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+
+		return t, false, err
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+
+	return t, false, err
+}
+
+// splitList splits a comma separated list of text values while keeping
+// escaped commas.
+func splitList(value string) []string {
+	var (
+		result []string
+		start  int
+	)
+
+	for idx := 0; idx < len(value); idx++ {
+		switch value[idx] {
+		case '\\':
+			idx++
+		case ',':
+			result = append(result, value[start:idx])
+			start = idx + 1
+		}
+	}
+
+	return append(result, value[start:])
+}
+
+var textUnescaper = strings.NewReplacer(
+	`\\`, `\`,
+	`\;`, ";",
+	`\,`, ",",
+	`\n`, "\n",
+	`\N`, "\n",
+)
+
+func unescape(s string) string {
+	return textUnescaper.Replace(s)
+}
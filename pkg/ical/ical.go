@@ -0,0 +1,208 @@
+// Package ical implements a minimal iCalendar (RFC 5545) encoder that is
+// sufficient to publish read-only calendar feeds.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxLineLength is the maximum number of octets per content line
+// (excluding the line break) as defined in RFC 5545 section 3.1.
+const maxLineLength = 75
+
+// Calendar is a VCALENDAR object holding a set of events.
+type Calendar struct {
+	// ProductID is used as the PRODID property and should identify
+	// the software that generated the calendar.
+	ProductID string
+
+	// Name is published as X-WR-CALNAME and used by most clients as
+	// the display name of a subscribed calendar.
+	Name string
+
+	// RefreshInterval, if set, is published as REFRESH-INTERVAL and
+	// X-PUBLISHED-TTL to hint clients how often they should poll the
+	// feed.
+	RefreshInterval time.Duration
+
+	// Events holds all events of the calendar.
+	Events []Event
+}
+
+// Event is a single VEVENT object.
+type Event struct {
+	// UID must be globally unique and stable across calendar
+	// re-generations.
+	UID string
+
+	// Summary is the title of the event.
+	Summary string
+
+	// Description holds an optional description.
+	Description string
+
+	// Categories holds an optional list of categories.
+	Categories []string
+
+	// Start and End define the time range of the event. If AllDay
+	// is set only the date part is used and End is exclusive.
+	Start time.Time
+	End   time.Time
+
+	// AllDay marks the event as a whole-day event.
+	AllDay bool
+
+	// Transparent marks the event as not blocking time
+	// in free/busy lookups.
+	Transparent bool
+}
+
+// Encode writes cal to w.
+func (cal *Calendar) Encode(w io.Writer) error {
+	enc := &encoder{w: bufio.NewWriter(w)}
+
+	stamp := time.Now()
+
+	enc.line("BEGIN", "VCALENDAR")
+	enc.line("VERSION", "2.0")
+	enc.line("PRODID", cal.ProductID)
+	enc.line("CALSCALE", "GREGORIAN")
+	enc.line("METHOD", "PUBLISH")
+
+	if cal.Name != "" {
+		enc.line("X-WR-CALNAME", escape(cal.Name))
+	}
+
+	if cal.RefreshInterval > 0 {
+		enc.line("REFRESH-INTERVAL;VALUE=DURATION", formatDuration(cal.RefreshInterval))
+		enc.line("X-PUBLISHED-TTL", formatDuration(cal.RefreshInterval))
+	}
+
+	for _, evt := range cal.Events {
+		enc.line("BEGIN", "VEVENT")
+		enc.line("UID", evt.UID)
+		enc.line("DTSTAMP", formatDateTime(stamp))
+
+		if evt.AllDay {
+			enc.line("DTSTART;VALUE=DATE", formatDate(evt.Start))
+			enc.line("DTEND;VALUE=DATE", formatDate(evt.End))
+		} else {
+			enc.line("DTSTART", formatDateTime(evt.Start))
+			enc.line("DTEND", formatDateTime(evt.End))
+		}
+
+		enc.line("SUMMARY", escape(evt.Summary))
+
+		if evt.Description != "" {
+			enc.line("DESCRIPTION", escape(evt.Description))
+		}
+
+		if len(evt.Categories) > 0 {
+			escaped := make([]string, len(evt.Categories))
+			for idx, c := range evt.Categories {
+				escaped[idx] = escape(c)
+			}
+
+			enc.line("CATEGORIES", strings.Join(escaped, ","))
+		}
+
+		if evt.Transparent {
+			enc.line("TRANSP", "TRANSPARENT")
+		} else {
+			enc.line("TRANSP", "OPAQUE")
+		}
+
+		enc.line("END", "VEVENT")
+	}
+
+	enc.line("END", "VCALENDAR")
+
+	if enc.err != nil {
+		return enc.err
+	}
+
+	return enc.w.Flush()
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes a single content line and folds it if it exceeds
+// maxLineLength octets.
+func (enc *encoder) line(name, value string) {
+	if enc.err != nil {
+		return
+	}
+
+	content := name + ":" + value
+
+	limit := maxLineLength
+
+	for first := true; len(content) > 0; first = false {
+		if !first {
+			_, enc.err = enc.w.WriteString("\r\n ")
+			if enc.err != nil {
+				return
+			}
+
+			// continuation lines start with a space that counts
+			// towards the line length.
+			limit = maxLineLength - 1
+		}
+
+		n := len(content)
+		if n > limit {
+			n = limit
+
+			// never split in the middle of a multi-byte UTF-8 sequence
+			for n > 0 && content[n]&0xC0 == 0x80 {
+				n--
+			}
+		}
+
+		_, enc.err = enc.w.WriteString(content[:n])
+		if enc.err != nil {
+			return
+		}
+
+		content = content[n:]
+	}
+
+	_, enc.err = enc.w.WriteString("\r\n")
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+
+	hours := int(d / time.Hour)
+	minutes := int((d % time.Hour) / time.Minute)
+	seconds := int((d % time.Minute) / time.Second)
+
+	return fmt.Sprintf("PT%dH%dM%dS", hours, minutes, seconds)
+}
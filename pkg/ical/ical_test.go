@@ -0,0 +1,156 @@
+package ical_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/cis/pkg/ical"
+)
+
+func encode(t *testing.T, cal *ical.Calendar) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, cal.Encode(&buf))
+
+	return buf.String()
+}
+
+func TestEncodeEscaping(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+
+	out := encode(t, &ical.Calendar{
+		ProductID: "-//test//EN",
+		Name:      "Opening Hours; Vienna, AT",
+		Events: []ical.Event{
+			{
+				UID:         "evt-1@test",
+				Summary:     `Closed: Staff, Meeting; C:\temp`,
+				Description: "line one\nline two\r\nline three",
+				Categories:  []string{"a,b", "c;d"},
+				Start:       start,
+				End:         start.Add(time.Hour),
+			},
+		},
+	})
+
+	assert.Contains(t, out, "X-WR-CALNAME:Opening Hours\\; Vienna\\, AT\r\n")
+	assert.Contains(t, out, "SUMMARY:Closed: Staff\\, Meeting\\; C:\\\\temp\r\n")
+	assert.Contains(t, out, "DESCRIPTION:line one\\nline two\\nline three\r\n")
+	assert.Contains(t, out, "CATEGORIES:a\\,b,c\\;d\r\n")
+
+	// the decoder must return the original values.
+	cal, err := ical.Decode(strings.NewReader(out), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, cal.Events, 1)
+	assert.Equal(t, "Opening Hours; Vienna, AT", cal.Name)
+	assert.Equal(t, `Closed: Staff, Meeting; C:\temp`, cal.Events[0].Summary)
+	assert.Equal(t, "line one\nline two\nline three", cal.Events[0].Description)
+	assert.Equal(t, []string{"a,b", "c;d"}, cal.Events[0].Categories)
+}
+
+func TestEncodeFolding(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+	summary := strings.Repeat("Öffnungszeiten ", 20)
+
+	out := encode(t, &ical.Calendar{
+		ProductID: "-//test//EN",
+		Events: []ical.Event{
+			{
+				UID:     "evt-1@test",
+				Summary: summary,
+				Start:   start,
+				End:     start.Add(time.Hour),
+			},
+		},
+	})
+
+	assert.True(t, strings.HasSuffix(out, "\r\n"))
+
+	lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+	folded := 0
+	for _, l := range lines {
+		assert.LessOrEqual(t, len(l), 75, "line %q exceeds 75 octets", l)
+		assert.NotContains(t, l, "\n")
+
+		if strings.HasPrefix(l, " ") {
+			folded++
+		}
+	}
+	assert.Greater(t, folded, 0)
+
+	// folding must never split multi-byte characters.
+	cal, err := ical.Decode(strings.NewReader(out), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, cal.Events, 1)
+	assert.Equal(t, summary, cal.Events[0].Summary)
+}
+
+func TestEncodeTimeZones(t *testing.T) {
+	t.Parallel()
+
+	vienna, err := time.LoadLocation("Europe/Vienna")
+	require.NoError(t, err)
+
+	// 2024-07-01 08:00 CEST is 06:00 UTC.
+	start := time.Date(2024, 7, 1, 8, 0, 0, 0, vienna)
+
+	out := encode(t, &ical.Calendar{
+		ProductID: "-//test//EN",
+		Events: []ical.Event{
+			{
+				UID:     "timed@test",
+				Summary: "Open",
+				Start:   start,
+				End:     start.Add(4 * time.Hour),
+			},
+			{
+				UID:     "allday@test",
+				Summary: "Closed",
+				Start:   time.Date(2024, 12, 25, 0, 0, 0, 0, vienna),
+				End:     time.Date(2024, 12, 26, 0, 0, 0, 0, vienna),
+				AllDay:  true,
+			},
+		},
+	})
+
+	// date-times are always published in UTC so no TZID parameter and
+	// VTIMEZONE component is required.
+	assert.NotContains(t, out, "TZID")
+	assert.Contains(t, out, "DTSTART:20240701T060000Z\r\n")
+	assert.Contains(t, out, "DTEND:20240701T100000Z\r\n")
+
+	// all-day events use the local date.
+	assert.Contains(t, out, "DTSTART;VALUE=DATE:20241225\r\n")
+	assert.Contains(t, out, "DTEND;VALUE=DATE:20241226\r\n")
+
+	cal, err := ical.Decode(strings.NewReader(out), vienna)
+	require.NoError(t, err)
+	require.Len(t, cal.Events, 2)
+	assert.True(t, start.Equal(cal.Events[0].Start))
+	assert.True(t, start.Add(4*time.Hour).Equal(cal.Events[0].End))
+
+	// date-times with a TZID parameter are decoded in that zone.
+	cal, err = ical.Decode(strings.NewReader(strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:tzid@test",
+		"DTSTART;TZID=Europe/Vienna:20241027T013000",
+		"DTEND;TZID=\"Europe/Vienna\":20241027T040000",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, cal.Events, 1)
+	assert.True(t, time.Date(2024, 10, 26, 23, 30, 0, 0, time.UTC).Equal(cal.Events[0].Start))
+	assert.True(t, time.Date(2024, 10, 27, 3, 0, 0, 0, time.UTC).Equal(cal.Events[0].End))
+}
@@ -25,9 +25,18 @@ type (
 		entries    map[string]Schema
 		validators map[string][]Validator
 		listeners  map[string][]ChangeListener
+		resolvers  map[string]ReferenceResolver
 
 		providerLock sync.RWMutex
 		provider     ConfigProvider
+
+		// watchLock protects known and serializes changes made through
+		// the schema with changes reported by a watching provider.
+		watchLock sync.Mutex
+		known     map[string]knownSection
+
+		revisionLock sync.RWMutex
+		revisions    RevisionStore
 	}
 
 	// ConfigSchemaBuilder collects functions that add configuration
@@ -101,6 +110,16 @@ type (
 		// the REST API.
 		Internal bool `json:"internal"`
 
+		// ReadRoles may hold the IDs or names of roles that are allowed to
+		// read instances of the schema. If empty, all users may read them.
+		// Users with one of WriteRoles are always allowed to read.
+		ReadRoles []string `json:"readRoles,omitempty"`
+
+		// WriteRoles may hold the IDs or names of roles that are allowed to
+		// create, update and delete instances of the schema. If empty, all
+		// users that may read instances are allowed to change them.
+		WriteRoles []string `json:"writeRoles,omitempty"`
+
 		// Annotations may hold additional annotations about the configuration schema. Those
 		// annotations may be, for example, used by user interfaces to determine how to
 		// best display the configuration setting.
@@ -109,6 +128,15 @@ type (
 		// Tests may hold one or more configuration test that a user may perform in order to
 		// be certain that a configuration schema works as expected.
 		Tests []ConfigTest `json:"tests,omitempty"`
+
+		// Version is the current version of the schema and stored with each
+		// configuration instance. It defaults to the highest version of
+		// Migrations.
+		Version int `json:"version,omitempty"`
+
+		// Migrations may hold option migrations that are applied to stored
+		// configuration instances with a lower version during startup.
+		Migrations []OptionMigration `json:"-"`
 	}
 
 	Validator interface {
@@ -197,6 +225,10 @@ func (schema *ConfigSchema) Register(regs ...Schema) error {
 			return fmt.Errorf("%s: %w", reg.Name, ErrMissingSpec)
 		}
 
+		if err := prepareMigrations(&reg); err != nil {
+			return fmt.Errorf("%s: %w", reg.Name, err)
+		}
+
 		if _, ok := schema.entries[lowerName]; ok {
 			return fmt.Errorf("%s: %w", reg.Name, ErrNameTaken)
 		}
@@ -210,6 +242,27 @@ func (schema *ConfigSchema) Register(regs ...Schema) error {
 	return nil
 }
 
+// SetRoles replaces the ReadRoles and WriteRoles of the registered schema
+// name. It allows restricting access to schemas based on configuration
+// that is loaded after the schema has been registered.
+func (schema *ConfigSchema) SetRoles(name string, readRoles, writeRoles []string) error {
+	schema.rw.Lock()
+	defer schema.rw.Unlock()
+
+	lowerName := strings.ToLower(name)
+
+	reg, ok := schema.entries[lowerName]
+	if !ok {
+		return fmt.Errorf("%s: %w", name, ErrUnknownType)
+	}
+
+	reg.ReadRoles = readRoles
+	reg.WriteRoles = writeRoles
+	schema.entries[lowerName] = reg
+
+	return nil
+}
+
 func (schema *ConfigSchema) AddNotifier(listener ChangeListener, types ...string) {
 	schema.rw.Lock()
 	defer schema.rw.Unlock()
@@ -277,6 +330,8 @@ func (schema *ConfigSchema) SchemaByName(name string) (Schema, error) {
 	return Schema{}, ErrUnknownType
 }
 
+// SchemaAsMap returns all instances of the schema name decoded as map
+// indexed by instance ID. Values of secret options are redacted.
 func (schema *ConfigSchema) SchemaAsMap(ctx context.Context, name string, withDefaults bool) (map[string]map[string]interface{}, error) {
 	lower := strings.ToLower(name)
 	spec, ok := schema.entries[lower]
@@ -323,7 +378,14 @@ func (schema *ConfigSchema) SchemaAsMap(ctx context.Context, name string, withDe
 	decoder := conf.NewSectionDecoder(spec.Spec.All())
 	result := make(map[string]map[string]interface{}, len(configs))
 	for _, sec := range configs {
-		result[sec.ID] = decoder.AsMap(sec.Section)
+		sec.Options = RedactSecrets(spec.Spec, sec.Options)
+
+		m := decoder.AsMap(sec.Section)
+		if sec.IsReadonly() {
+			m[ReadonlyRef] = true
+		}
+
+		result[sec.ID] = m
 	}
 
 	return result, nil
@@ -444,6 +506,13 @@ func (schema *ConfigSchema) Create(ctx context.Context, secType string, options
 	)
 	defer sp.End()
 
+	return schema.create(ctx, "", secType, options)
+}
+
+// create creates a new configuration section. If id is set the provider
+// must implement IDConfigProvider and the section is created using id.
+// Otherwise ErrIDNotSupported is returned.
+func (schema *ConfigSchema) create(ctx context.Context, id, secType string, options []conf.Option) (string, error) {
 	schema.rw.RLock()
 	defer schema.rw.RUnlock()
 
@@ -459,29 +528,45 @@ func (schema *ConfigSchema) Create(ctx context.Context, secType string, options
 		return "", ErrCfgSectionNotFound
 	}
 
-	// ensure spec compliance
-	if err := conf.ValidateOptions(options, reg.Spec); err != nil {
-		return "", httperr.BadRequest(err.Error())
+	sec := conf.Section{
+		Name:    secType,
+		Options: restoreSecrets(reg.Spec, nil, options),
 	}
 
-	if err := schema.ensureUniquness(ctx, reg, options, ""); err != nil {
+	if err := schema.validate(ctx, reg, Section{Section: sec}); err != nil {
 		return "", err
 	}
 
-	sec := conf.Section{
-		Name:    secType,
-		Options: options,
+	var idProvider IDConfigProvider
+	if id != "" {
+		var ok bool
+		if idProvider, ok = schema.provider.(IDConfigProvider); !ok {
+			return "", ErrIDNotSupported
+		}
 	}
 
-	if err := schema.runValidators(ctx, Section{Section: sec}); err != nil {
-		return "", err
+	schema.watchLock.Lock()
+	var (
+		instanceID string
+		err        error
+	)
+	if idProvider != nil {
+		instanceID = id
+		err = idProvider.CreateWithID(ctx, id, sec)
+	} else {
+		instanceID, err = schema.provider.Create(ctx, sec)
 	}
+	if err == nil {
+		schema.trackChange(ChangeTypeCreate, instanceID, &sec)
+	}
+	schema.watchLock.Unlock()
 
-	instanceID, err := schema.provider.Create(ctx, sec)
 	if err != nil {
 		return "", err
 	}
 
+	schema.recordRevision(ctx, ChangeTypeCreate, instanceID, sec.Name, sec.Options)
+
 	err = schema.notifyChangeListeners(ctx, ChangeTypeCreate, instanceID, sec.Name, &sec)
 
 	return instanceID, err
@@ -511,14 +596,12 @@ func (schema *ConfigSchema) Update(ctx context.Context, id, secType string, opts
 		return ErrCfgSectionNotFound
 	}
 
-	opts = filterPrivateID(opts)
-
-	// ensure spec compliance
-	if err := conf.ValidateOptions(opts, reg.Spec); err != nil {
-		return httperr.BadRequest(err.Error())
-	}
-
-	if err := schema.ensureUniquness(ctx, reg, opts, id); err != nil {
+	// keep the stored values of secret options that are sent as
+	// placeholder.
+	var existing []conf.Option
+	if current, err := schema.provider.GetID(ctx, id); err == nil {
+		existing = current.Options
+	} else if !errors.Is(err, ErrCfgSectionNotFound) {
 		return err
 	}
 
@@ -526,18 +609,28 @@ func (schema *ConfigSchema) Update(ctx context.Context, id, secType string, opts
 		ID: id,
 		Section: conf.Section{
 			Name:    secType,
-			Options: opts,
+			Options: restoreSecrets(reg.Spec, existing, filterPrivateID(opts)),
 		},
 	}
+	opts = sec.Options
 
-	if err := schema.runValidators(ctx, sec); err != nil {
+	if err := schema.validate(ctx, reg, sec); err != nil {
 		return err
 	}
 
-	if err := schema.provider.Update(ctx, id, secType, opts); err != nil {
+	schema.watchLock.Lock()
+	err := schema.provider.Update(ctx, id, secType, opts)
+	if err == nil {
+		schema.trackChange(ChangeTypeUpdate, id, &sec.Section)
+	}
+	schema.watchLock.Unlock()
+
+	if err != nil {
 		return err
 	}
 
+	schema.recordRevision(ctx, ChangeTypeUpdate, id, secType, opts)
+
 	if err := schema.notifyChangeListeners(ctx, ChangeTypeUpdate, id, secType, &sec.Section); err != nil {
 		return err
 	}
@@ -545,6 +638,69 @@ func (schema *ConfigSchema) Update(ctx context.Context, id, secType string, opts
 	return nil
 }
 
+// Validate checks if opts would be accepted by Create or Update without
+// storing them. id must be empty for new configuration instances.
+func (schema *ConfigSchema) Validate(ctx context.Context, id, secType string, opts []conf.Option) error {
+	schema.providerLock.RLock()
+	defer schema.providerLock.RUnlock()
+
+	schema.rw.RLock()
+	defer schema.rw.RUnlock()
+
+	if schema.provider == nil {
+		return ErrNoProvider
+	}
+
+	reg, ok := schema.entries[strings.ToLower(secType)]
+	if !ok {
+		return ErrCfgSectionNotFound
+	}
+
+	return schema.validate(ctx, reg, Section{
+		ID: id,
+		Section: conf.Section{
+			Name:    secType,
+			Options: filterPrivateID(opts),
+		},
+	})
+}
+
+// validate ensures sec complies with the spec of reg, does not violate
+// unique fields or references and passes all validators.
+func (schema *ConfigSchema) validate(ctx context.Context, reg Schema, sec Section) error {
+	if err := conf.ValidateOptions(sec.Options, reg.Spec); err != nil {
+		return httperr.BadRequest(err.Error())
+	}
+
+	if err := schema.ensureUniquness(ctx, reg, sec.Options, sec.ID); err != nil {
+		return err
+	}
+
+	change := ConfigChange{
+		Type:    ChangeTypeCreate,
+		Section: sec,
+	}
+
+	if sec.ID != "" {
+		change.Type = ChangeTypeUpdate
+
+		existing, err := schema.provider.GetID(ctx, sec.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := schema.ensureNotReferenced(ctx, []Section{existing}, []ConfigChange{change}); err != nil {
+			return err
+		}
+	}
+
+	if err := schema.validateReferences(ctx, reg, sec, []ConfigChange{change}); err != nil {
+		return err
+	}
+
+	return schema.runValidators(ctx, sec)
+}
+
 func (schema *ConfigSchema) Delete(ctx context.Context, id string) error {
 	ctx, sp := otel.Tracer("").Start(ctx, "runtime.ConfigSchema.Delete",
 		trace.WithAttributes(
@@ -568,10 +724,27 @@ func (schema *ConfigSchema) Delete(ctx context.Context, id string) error {
 	schema.rw.RLock()
 	defer schema.rw.RUnlock()
 
-	if err := schema.provider.Delete(ctx, id); err != nil {
+	err = schema.ensureNotReferenced(ctx, []Section{value}, []ConfigChange{{
+		Type:    ChangeTypeDelete,
+		Section: value,
+	}})
+	if err != nil {
+		return err
+	}
+
+	schema.watchLock.Lock()
+	err = schema.provider.Delete(ctx, id)
+	if err == nil {
+		schema.trackChange(ChangeTypeDelete, id, nil)
+	}
+	schema.watchLock.Unlock()
+
+	if err != nil {
 		return err
 	}
 
+	schema.recordRevision(ctx, ChangeTypeDelete, id, value.Name, nil)
+
 	if err := schema.notifyChangeListeners(ctx, ChangeTypeDelete, id, value.Name, nil); err != nil {
 		return err
 	}
@@ -583,12 +756,29 @@ func (schema *ConfigSchema) Delete(ctx context.Context, id string) error {
 // values required for the test as defined in ConfigTest.Spec.
 // Test automatically applies defaults for configSpec and testSpec and validates them against the
 // respective configuration specification.
-func (schema *ConfigSchema) Test(ctx context.Context, schemaType, testID string, configSpec, testSpec []conf.Option) (*TestResult, error) {
+// If instanceID is set, SecretPlaceholder values of secret options in configSpec are replaced with
+// the values stored for the configuration instance instanceID.
+func (schema *ConfigSchema) Test(ctx context.Context, schemaType, testID, instanceID string, configSpec, testSpec []conf.Option) (*TestResult, error) {
 	s, tester, err := schema.getTest(ctx, schemaType, testID)
 	if err != nil {
 		return nil, err
 	}
 
+	var existing []conf.Option
+	if instanceID != "" {
+		sec, err := schema.GetID(ctx, instanceID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.EqualFold(sec.Name, s.Name) {
+			return nil, ErrCfgSectionNotFound
+		}
+
+		existing = sec.Options
+	}
+	configSpec = restoreSecrets(s.Spec, existing, configSpec)
+
 	// TODO(ppacher): should we call schema.ValidateFunc here as wel?
 	confSec, err := conf.Prepare(conf.Section{
 		Name:    schemaType,
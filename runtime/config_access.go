@@ -0,0 +1,31 @@
+package runtime
+
+// CanRead reports whether a user with roles may read instances of reg.
+// roles may hold role IDs and names.
+func (reg Schema) CanRead(roles []string) bool {
+	if len(reg.ReadRoles) == 0 {
+		return true
+	}
+
+	return hasAnyRole(reg.ReadRoles, roles) || hasAnyRole(reg.WriteRoles, roles)
+}
+
+// CanWrite reports whether a user with roles may create, update and
+// delete instances of reg. roles may hold role IDs and names.
+func (reg Schema) CanWrite(roles []string) bool {
+	if len(reg.WriteRoles) == 0 {
+		return reg.CanRead(roles)
+	}
+
+	return hasAnyRole(reg.WriteRoles, roles)
+}
+
+func hasAnyRole(allowed, roles []string) bool {
+	for _, role := range roles {
+		if containsFold(allowed, role) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,43 @@
+package runtime_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+func TestSchemaAccess(t *testing.T) {
+	open := runtime.Schema{Name: "Open"}
+	assert.True(t, open.CanRead(nil))
+	assert.True(t, open.CanWrite(nil))
+
+	restricted := runtime.Schema{
+		Name:       "Restricted",
+		ReadRoles:  []string{"staff"},
+		WriteRoles: []string{"admin-id"},
+	}
+	assert.False(t, restricted.CanRead(nil))
+	assert.True(t, restricted.CanRead([]string{"Staff"}))
+	assert.False(t, restricted.CanWrite([]string{"staff"}))
+
+	// writers are always allowed to read.
+	assert.True(t, restricted.CanRead([]string{"admin-id", "admin"}))
+	assert.True(t, restricted.CanWrite([]string{"admin-id", "admin"}))
+
+	// without write roles everybody who may read may also write.
+	readOnly := runtime.Schema{
+		Name:      "ReadOnly",
+		ReadRoles: []string{"staff"},
+	}
+	assert.True(t, readOnly.CanWrite([]string{"staff"}))
+	assert.False(t, readOnly.CanWrite([]string{"other"}))
+
+	// without read roles everybody may read but only writers may write.
+	writeOnly := runtime.Schema{
+		Name:       "WriteOnly",
+		WriteRoles: []string{"admin"},
+	}
+	assert.True(t, writeOnly.CanRead(nil))
+	assert.False(t, writeOnly.CanWrite([]string{"staff"}))
+}
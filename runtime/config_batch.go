@@ -0,0 +1,393 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrBatchNotSupported is returned by ConfigSchema.Batch if the
+// configuration provider cannot apply multiple changes atomically.
+var ErrBatchNotSupported = errors.New("config-provider: provider does not support batch changes")
+
+// BatchConfigProvider is implemented by configuration providers that can
+// apply multiple changes atomically.
+type BatchConfigProvider interface {
+	ConfigProvider
+
+	// ApplyBatch applies all changes at once. Either all changes are
+	// persisted or none of them. Created sections use Section.ID if it
+	// is set. Otherwise a new ID is assigned to the Section.ID field of
+	// the respective change.
+	ApplyBatch(ctx context.Context, changes []ConfigChange) error
+}
+
+// BatchValidator may be implemented by a Validator that needs to see all
+// changes of a batch at once, e.g. because intermediate states would be
+// invalid. Validators that don't implement BatchValidator are called for
+// each created or updated section of the batch.
+type BatchValidator interface {
+	ValidateBatch(ctx context.Context, changes []ConfigChange) error
+}
+
+// BatchChangeListener may be implemented by a ChangeListener that wants
+// to be notified once about all changes of a batch. Listeners that don't
+// implement BatchChangeListener are notified about each change.
+type BatchChangeListener interface {
+	NotifyBatch(ctx context.Context, changes []ConfigChange) error
+}
+
+// Batch validates changes as a whole, persists them atomically and
+// notifies all change listeners once the batch has been applied.
+// Validators and listeners receive the changes grouped by schema type.
+// The returned changes have the IDs of created sections set.
+func (schema *ConfigSchema) Batch(ctx context.Context, changes []ConfigChange) ([]ConfigChange, error) {
+	ctx, sp := otel.Tracer("").Start(ctx, "runtime.ConfigSchema.Batch",
+		trace.WithAttributes(
+			attribute.Int("batch_size", len(changes)),
+		),
+	)
+	defer sp.End()
+
+	schema.providerLock.RLock()
+	defer schema.providerLock.RUnlock()
+
+	schema.rw.RLock()
+	defer schema.rw.RUnlock()
+
+	if schema.provider == nil {
+		return nil, ErrNoProvider
+	}
+
+	provider, ok := schema.provider.(BatchConfigProvider)
+	if !ok {
+		return nil, ErrBatchNotSupported
+	}
+
+	prepared, err := schema.prepareBatch(ctx, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(prepared) == 0 {
+		return prepared, nil
+	}
+
+	groups := groupChanges(prepared)
+
+	for _, group := range groups {
+		if err := schema.ensureBatchUniqueness(ctx, schema.entries[group.name], group.changes); err != nil {
+			return nil, httperr.BadRequest(err.Error())
+		}
+	}
+
+	if err := schema.ensureBatchReferences(ctx, prepared); err != nil {
+		return nil, err
+	}
+
+	if err := schema.runBatchValidators(ctx, groups); err != nil {
+		return nil, err
+	}
+
+	schema.watchLock.Lock()
+	err = provider.ApplyBatch(ctx, prepared)
+	if err == nil {
+		for _, change := range prepared {
+			sec := change.Section.Section
+			schema.trackChange(change.Type, change.Section.ID, &sec)
+		}
+	}
+	schema.watchLock.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range prepared {
+		schema.recordRevision(ctx, change.Type, change.Section.ID, change.Section.Name, change.Section.Options)
+	}
+
+	// group again so listeners see the IDs of created sections.
+	return prepared, schema.notifyBatchListeners(ctx, groupChanges(prepared))
+}
+
+// prepareBatch validates each change on its own and loads the current
+// section for updates and deletes.
+func (schema *ConfigSchema) prepareBatch(ctx context.Context, changes []ConfigChange) ([]ConfigChange, error) {
+	prepared := make([]ConfigChange, len(changes))
+	seen := make(map[string]struct{}, len(changes))
+
+	for idx, change := range changes {
+		id := change.Section.ID
+		prefix := "change " + strconv.Itoa(idx)
+
+		if change.Type != ChangeTypeCreate {
+			if id == "" {
+				return nil, httperr.BadRequest(prefix + ": missing id")
+			}
+
+			if _, ok := seen[id]; ok {
+				return nil, httperr.BadRequest(fmt.Sprintf("%s: section %s is changed more than once", prefix, id))
+			}
+			seen[id] = struct{}{}
+		}
+
+		switch change.Type {
+		case ChangeTypeCreate, ChangeTypeUpdate:
+			reg, ok := schema.entries[strings.ToLower(change.Section.Name)]
+			if !ok {
+				return nil, httperr.NotFound("schema-type", change.Section.Name).SetInternal(ErrCfgSectionNotFound)
+			}
+
+			var existing []conf.Option
+			if change.Type == ChangeTypeUpdate {
+				sec, err := schema.provider.GetID(ctx, id)
+				if err != nil {
+					return nil, batchLookupError(id, err)
+				}
+
+				if !strings.EqualFold(sec.Name, reg.Name) {
+					return nil, batchLookupError(id, ErrCfgSectionNotFound)
+				}
+
+				existing = sec.Options
+			} else {
+				id = ""
+			}
+
+			opts := restoreSecrets(reg.Spec, existing, filterPrivateID(change.Section.Options))
+			if err := conf.ValidateOptions(opts, reg.Spec); err != nil {
+				return nil, httperr.BadRequest(prefix + ": " + err.Error())
+			}
+
+			prepared[idx] = ConfigChange{
+				Type: change.Type,
+				Section: Section{
+					ID: id,
+					Section: conf.Section{
+						Name:    reg.Name,
+						Options: opts,
+					},
+				},
+			}
+
+		case ChangeTypeDelete:
+			existing, err := schema.provider.GetID(ctx, id)
+			if err != nil {
+				return nil, batchLookupError(id, err)
+			}
+
+			prepared[idx] = ConfigChange{
+				Type: ChangeTypeDelete,
+				Section: Section{
+					ID: id,
+					Section: conf.Section{
+						Name: existing.Name,
+					},
+				},
+			}
+
+		default:
+			return nil, httperr.BadRequest(fmt.Sprintf("%s: unsupported change type %q", prefix, change.Type))
+		}
+	}
+
+	return prepared, nil
+}
+
+// batchLookupError converts ErrCfgSectionNotFound into a not-found error
+// for the section id.
+func batchLookupError(id string, err error) error {
+	if errors.Is(err, ErrCfgSectionNotFound) {
+		return httperr.NotFound("config", id).SetInternal(err)
+	}
+
+	return err
+}
+
+// changeGroup holds all changes of a batch for the same schema type.
+type changeGroup struct {
+	name    string
+	changes []ConfigChange
+}
+
+// groupChanges groups changes by schema type in the order the types
+// first appear in changes.
+func groupChanges(changes []ConfigChange) []changeGroup {
+	var groups []changeGroup
+
+	index := make(map[string]int)
+	for _, change := range changes {
+		name := strings.ToLower(change.Section.Name)
+
+		idx, ok := index[name]
+		if !ok {
+			idx = len(groups)
+			index[name] = idx
+			groups = append(groups, changeGroup{name: name})
+		}
+
+		groups[idx].changes = append(groups[idx].changes, change)
+	}
+
+	return groups
+}
+
+// ensureBatchUniqueness checks unique fields of reg against the state
+// after applying changes.
+func (schema *ConfigSchema) ensureBatchUniqueness(ctx context.Context, reg Schema, changes []ConfigChange) error {
+	uniqueFields, ok := reg.Annotations.Get("vet.dobersberg.cis:schema/unqiueFields").([]string)
+	if !ok {
+		return nil
+	}
+
+	all, err := schema.provider.Get(ctx, reg.Name)
+	if err != nil {
+		return err
+	}
+
+	final := make(map[string]conf.Options, len(all)+len(changes))
+	for _, sec := range all {
+		final[sec.ID] = sec.Options
+	}
+
+	var touched []string
+	for idx, change := range changes {
+		key := change.Section.ID
+		if change.Type == ChangeTypeCreate {
+			key = "new-" + strconv.Itoa(idx)
+		}
+
+		if change.Type == ChangeTypeDelete {
+			delete(final, key)
+
+			continue
+		}
+
+		final[key] = change.Section.Options
+		touched = append(touched, key)
+	}
+
+	for _, key := range touched {
+		for _, f := range uniqueFields {
+			values := final[key].GetStringSlice(f)
+
+			for other, opts := range final {
+				if other == key {
+					continue
+				}
+
+				if match, ok := optionIncludesValues(opts, f, values); ok {
+					return fmt.Errorf("field %q=%q used more than once", f, match)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// ensureBatchReferences validates references of all created and updated
+// sections and ensures no referenced value is removed, both against the
+// state after applying changes.
+func (schema *ConfigSchema) ensureBatchReferences(ctx context.Context, changes []ConfigChange) error {
+	var removed []Section
+
+	for _, change := range changes {
+		if change.Type != ChangeTypeDelete {
+			reg := schema.entries[strings.ToLower(change.Section.Name)]
+			if err := schema.validateReferences(ctx, reg, change.Section, changes); err != nil {
+				return err
+			}
+		}
+
+		if change.Type != ChangeTypeCreate {
+			existing, err := schema.provider.GetID(ctx, change.Section.ID)
+			if err != nil {
+				return batchLookupError(change.Section.ID, err)
+			}
+
+			removed = append(removed, existing)
+		}
+	}
+
+	return schema.ensureNotReferenced(ctx, removed, changes)
+}
+
+func (schema *ConfigSchema) runBatchValidators(ctx context.Context, groups []changeGroup) error {
+	var errs []error
+
+	for _, group := range groups {
+		validators := append(append([]Validator{}, schema.validators[""]...), schema.validators[group.name]...)
+
+		for _, validator := range validators {
+			if bv, ok := validator.(BatchValidator); ok {
+				if err := bv.ValidateBatch(ctx, group.changes); err != nil {
+					errs = append(errs, err)
+				}
+
+				continue
+			}
+
+			for _, change := range group.changes {
+				if change.Type == ChangeTypeDelete {
+					continue
+				}
+
+				if err := validator.Validate(ctx, change.Section); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return httperr.BadRequest(err.Error())
+	}
+
+	return nil
+}
+
+func (schema *ConfigSchema) notifyBatchListeners(ctx context.Context, groups []changeGroup) error {
+	var errs []error
+
+	for _, group := range groups {
+		listeners := append(append([]ChangeListener{}, schema.listeners[""]...), schema.listeners[group.name]...)
+
+		for _, listener := range listeners {
+			if bl, ok := listener.(BatchChangeListener); ok {
+				if err := bl.NotifyBatch(ctx, group.changes); err != nil {
+					errs = append(errs, err)
+				}
+
+				continue
+			}
+
+			for _, change := range group.changes {
+				var sec *conf.Section
+				if change.Type != ChangeTypeDelete {
+					section := change.Section.Section
+					sec = &section
+				}
+
+				if err := listener.NotifyChange(ctx, change.Type, change.Section.ID, sec); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return &NotificationError{Wrapped: err}
+	}
+
+	return nil
+}
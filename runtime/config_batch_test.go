@@ -0,0 +1,87 @@
+package runtime_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/dirprovider"
+)
+
+type batchRecorder struct {
+	batches [][]runtime.ConfigChange
+}
+
+func (r *batchRecorder) NotifyChange(context.Context, string, string, *conf.Section) error {
+	panic("NotifyChange must not be called for batches")
+}
+
+func (r *batchRecorder) NotifyBatch(_ context.Context, changes []runtime.ConfigChange) error {
+	r.batches = append(r.batches, changes)
+
+	return nil
+}
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+
+	provider, err := dirprovider.New(t.TempDir())
+	require.NoError(t, err)
+
+	schema := new(runtime.ConfigSchema)
+	require.NoError(t, schema.Register(runtime.Schema{
+		Name:  "Test",
+		Multi: true,
+		Spec: conf.SectionSpec{
+			{Name: "Name", Type: conf.StringType},
+		},
+		Annotations: new(conf.Annotation).With(runtime.Unique("Name")),
+	}))
+	schema.SetProvider(provider)
+
+	name := func(v string) []conf.Option {
+		return []conf.Option{{Name: "Name", Value: v}}
+	}
+
+	a, err := schema.Create(ctx, "Test", name("a"))
+	require.NoError(t, err)
+	b, err := schema.Create(ctx, "Test", name("b"))
+	require.NoError(t, err)
+
+	rec := new(batchRecorder)
+	schema.AddNotifier(rec, "Test")
+
+	// swapping unique values is only possible in a batch
+	require.Error(t, schema.Update(ctx, a, "Test", name("b")))
+
+	applied, err := schema.Batch(ctx, []runtime.ConfigChange{
+		{Type: runtime.ChangeTypeUpdate, Section: runtime.Section{ID: a, Section: conf.Section{Name: "Test", Options: name("b")}}},
+		{Type: runtime.ChangeTypeUpdate, Section: runtime.Section{ID: b, Section: conf.Section{Name: "Test", Options: name("a")}}},
+		{Type: runtime.ChangeTypeCreate, Section: runtime.Section{Section: conf.Section{Name: "Test", Options: name("c")}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, applied, 3)
+	require.NotEmpty(t, applied[2].Section.ID)
+
+	require.Len(t, rec.batches, 1)
+	assert.Equal(t, applied[2].Section.ID, rec.batches[0][2].Section.ID)
+
+	sec, err := schema.GetID(ctx, a)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, sec.Options.GetStringSlice("Name"))
+
+	// an invalid change rejects the whole batch
+	_, err = schema.Batch(ctx, []runtime.ConfigChange{
+		{Type: runtime.ChangeTypeDelete, Section: runtime.Section{ID: a}},
+		{Type: runtime.ChangeTypeUpdate, Section: runtime.Section{ID: b, Section: conf.Section{Name: "Test", Options: name("c")}}},
+	})
+	require.Error(t, err)
+
+	all, err := schema.All(ctx, "Test")
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+	assert.Len(t, rec.batches, 1)
+}
@@ -0,0 +1,363 @@
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ppacher/system-conf/conf"
+)
+
+// Supported formats for configuration bundles.
+const (
+	// BundleFormatConf encodes a bundle as systemd-style .conf file. The
+	// ID of each instance is stored in the _id option.
+	BundleFormatConf = "conf"
+
+	// BundleFormatJSON encodes a bundle as JSON.
+	BundleFormatJSON = "json"
+)
+
+// Actions reported in ImportResult.
+const (
+	ImportActionCreate    = "create"
+	ImportActionUpdate    = "update"
+	ImportActionUnchanged = "unchanged"
+)
+
+// ErrUnsupportedFormat is returned when encoding or decoding a bundle
+// using an unknown format.
+var ErrUnsupportedFormat = errors.New("config: unsupported bundle format")
+
+// BundleSection is a single configuration instance in a Bundle.
+type BundleSection struct {
+	ID      string        `json:"id,omitempty"`
+	Schema  string        `json:"schema"`
+	Options []conf.Option `json:"options"`
+}
+
+// Bundle holds configuration instances for export and import.
+type Bundle struct {
+	Sections []BundleSection `json:"sections"`
+}
+
+// ImportResult describes the outcome of importing a single section of
+// a Bundle.
+type ImportResult struct {
+	// Index is the position of the section in the bundle.
+	Index  int    `json:"index"`
+	Schema string `json:"schema"`
+
+	// ID is the ID of the section as found in the bundle.
+	ID string `json:"id,omitempty"`
+
+	// TargetID is the ID of the configuration instance that has been
+	// created or updated. It's empty for creates during a dry-run.
+	TargetID string `json:"targetId,omitempty"`
+
+	// Action is one of the ImportAction constants.
+	Action  string `json:"action"`
+	Error   string `json:"error,omitempty"`
+	Warning string `json:"warning,omitempty"`
+}
+
+// Export returns all configuration instances of the given schemas. If no
+// schemas are given all non-internal schemas are exported. Read-only
+// instances are skipped as they cannot be imported again. Values of
+// secret options are redacted. Import keeps the values already stored in
+// the target and warns about secrets that must be set again.
+func (schema *ConfigSchema) Export(ctx context.Context, schemas ...string) (*Bundle, error) {
+	var regs []Schema
+	if len(schemas) == 0 {
+		for _, reg := range schema.Schemas() {
+			if !reg.Internal {
+				regs = append(regs, reg)
+			}
+		}
+	} else {
+		for _, name := range schemas {
+			reg, err := schema.SchemaByName(name)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+
+			regs = append(regs, reg)
+		}
+	}
+
+	bundle := &Bundle{
+		Sections: []BundleSection{},
+	}
+
+	for _, reg := range regs {
+		all, err := schema.All(ctx, reg.Name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", reg.Name, err)
+		}
+
+		for _, sec := range all {
+			if sec.IsReadonly() {
+				continue
+			}
+
+			bundle.Sections = append(bundle.Sections, BundleSection{
+				ID:      sec.ID,
+				Schema:  reg.Name,
+				Options: RedactSecrets(reg.Spec, filterPrivateID(sec.Options)),
+			})
+		}
+	}
+
+	return bundle, nil
+}
+
+// Import creates or updates all sections of bundle. Sections with an ID
+// that exists for the same schema are updated. Instances of schemas that
+// do not support multiple instances are updated regardless of their ID.
+// All other sections are created using the ID of the bundle if the
+// provider supports it so importing the same bundle again does not create
+// duplicates. Each section runs through the same validation as
+// Create and Update. Failed sections are reported in the result and do
+// not stop the import. If dryRun is set sections are only validated.
+// Note that a dry-run validates each section against the current
+// configuration without the other sections of the bundle.
+func (schema *ConfigSchema) Import(ctx context.Context, bundle *Bundle, dryRun bool) []ImportResult {
+	results := make([]ImportResult, len(bundle.Sections))
+
+	for idx, sec := range bundle.Sections {
+		results[idx] = schema.importSection(ctx, idx, sec, dryRun)
+	}
+
+	return results
+}
+
+func (schema *ConfigSchema) importSection(ctx context.Context, idx int, sec BundleSection, dryRun bool) ImportResult {
+	result := ImportResult{
+		Index:  idx,
+		Schema: sec.Schema,
+		ID:     sec.ID,
+		Action: ImportActionCreate,
+	}
+
+	fail := func(err error) ImportResult {
+		result.Error = err.Error()
+
+		return result
+	}
+
+	reg, err := schema.SchemaByName(sec.Schema)
+	if err != nil {
+		return fail(err)
+	}
+	result.Schema = reg.Name
+
+	existing, err := schema.importTarget(ctx, reg, sec.ID)
+	if err != nil {
+		return fail(err)
+	}
+
+	var existingOpts []conf.Option
+	if existing != nil {
+		if existing.IsReadonly() {
+			return fail(fmt.Errorf("%s: %w", existing.ID, ErrReadOnly))
+		}
+
+		result.TargetID = existing.ID
+		result.Action = ImportActionUpdate
+		existingOpts = existing.Options
+	}
+
+	// exported bundles do not contain secret values so they are lost
+	// unless the target already has a value.
+	opts := restoreSecrets(reg.Spec, existingOpts, filterPrivateID(sec.Options))
+	if missing := missingSecrets(reg.Spec, existingOpts, sec.Options); len(missing) > 0 {
+		result.Warning = fmt.Sprintf("redacted secret options must be set again: %s", strings.Join(missing, ", "))
+	}
+
+	if existing != nil {
+		if len(DiffOptions(filterPrivateID(existing.Options), opts)) == 0 {
+			result.Action = ImportActionUnchanged
+
+			return result
+		}
+	}
+
+	if dryRun {
+		if err := schema.Validate(ctx, result.TargetID, reg.Name, opts); err != nil {
+			return fail(err)
+		}
+
+		return result
+	}
+
+	switch {
+	case result.Action == ImportActionUpdate:
+		err = schema.Update(ctx, result.TargetID, reg.Name, opts)
+
+	case sec.ID != "":
+		// keep the ID of the bundle so importing it again updates
+		// the section instead of creating a new one.
+		result.TargetID, err = schema.create(ctx, sec.ID, reg.Name, opts)
+		if errors.Is(err, ErrIDNotSupported) || errors.Is(err, ErrInvalidID) {
+			result.Warning = joinWarnings(result.Warning, fmt.Sprintf("id %s cannot be preserved: %s", sec.ID, err))
+			result.TargetID, err = schema.Create(ctx, reg.Name, opts)
+		}
+
+	default:
+		result.TargetID, err = schema.Create(ctx, reg.Name, opts)
+	}
+
+	var notifyErr *NotificationError
+	switch {
+	case errors.As(err, &notifyErr):
+		result.Warning = joinWarnings(result.Warning, notifyErr.Wrapped.Error())
+	case err != nil:
+		result.TargetID = ""
+
+		return fail(err)
+	}
+
+	return result
+}
+
+// importTarget returns the existing instance of reg that is updated by a
+// bundle section with the given id. Single-instance schemas are matched
+// by name if no instance with id exists. It returns nil if a new instance
+// must be created.
+func (schema *ConfigSchema) importTarget(ctx context.Context, reg Schema, id string) (*Section, error) {
+	if id != "" {
+		existing, err := schema.GetID(ctx, id)
+		switch {
+		case errors.Is(err, ErrCfgSectionNotFound):
+		case err != nil:
+			return nil, err
+		case !strings.EqualFold(existing.Name, reg.Name):
+			return nil, fmt.Errorf("id %s belongs to a %s instance", id, existing.Name)
+		default:
+			return &existing, nil
+		}
+	}
+
+	if reg.Multi {
+		return nil, nil
+	}
+
+	all, err := schema.All(ctx, reg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(all) == 0 {
+		return nil, nil
+	}
+
+	return &all[0], nil
+}
+
+func joinWarnings(warnings ...string) string {
+	var result []string
+	for _, w := range warnings {
+		if w != "" {
+			result = append(result, w)
+		}
+	}
+
+	return strings.Join(result, "; ")
+}
+
+// EncodeBundle writes bundle to w using format.
+func EncodeBundle(w io.Writer, bundle *Bundle, format string) error {
+	switch format {
+	case BundleFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(bundle)
+
+	case BundleFormatConf:
+		sections := make(conf.Sections, 0, len(bundle.Sections))
+		for _, sec := range bundle.Sections {
+			opts := make([]conf.Option, 0, len(sec.Options)+1)
+			if sec.ID != "" {
+				opts = append(opts, conf.Option{Name: IDRef, Value: sec.ID})
+			}
+
+			for _, opt := range sec.Options {
+				// the .conf format does not preserve line breaks.
+				if strings.ContainsAny(opt.Value, "\r\n") {
+					return fmt.Errorf("%s %s: option %s: multi-line values are not supported, use %s instead", sec.Schema, sec.ID, opt.Name, BundleFormatJSON)
+				}
+
+				opts = append(opts, opt)
+			}
+
+			sections = append(sections, conf.Section{
+				Name:    sec.Schema,
+				Options: opts,
+			})
+		}
+
+		return conf.WriteSectionsTo(sections, w)
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// DecodeBundle reads a bundle from r. If format is empty it's detected
+// from the content: JSON bundles start with '{', everything else is read
+// as .conf file.
+func DecodeBundle(r io.Reader, format string) (*Bundle, error) {
+	br := bufio.NewReader(r)
+
+	if format == "" {
+		format = BundleFormatConf
+
+		peek, _ := br.Peek(512)
+		if bytes.HasPrefix(bytes.TrimSpace(peek), []byte("{")) {
+			format = BundleFormatJSON
+		}
+	}
+
+	switch format {
+	case BundleFormatJSON:
+		var bundle Bundle
+		if err := json.NewDecoder(br).Decode(&bundle); err != nil {
+			return nil, err
+		}
+
+		return &bundle, nil
+
+	case BundleFormatConf:
+		file, err := conf.Deserialize("bundle", br)
+		if err != nil {
+			return nil, err
+		}
+
+		bundle := &Bundle{
+			Sections: make([]BundleSection, 0, len(file.Sections)),
+		}
+		for _, sec := range file.Sections {
+			var id string
+			for _, opt := range sec.Options {
+				if opt.Name == IDRef {
+					id = opt.Value
+				}
+			}
+
+			bundle.Sections = append(bundle.Sections, BundleSection{
+				ID:      id,
+				Schema:  sec.Name,
+				Options: filterPrivateID(sec.Options),
+			})
+		}
+
+		return bundle, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
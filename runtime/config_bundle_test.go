@@ -0,0 +1,126 @@
+package runtime_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/dirprovider"
+)
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+
+	newSchema := func() *runtime.ConfigSchema {
+		provider, err := dirprovider.New(t.TempDir())
+		require.NoError(t, err)
+
+		schema := new(runtime.ConfigSchema)
+		require.NoError(t, schema.Register(runtime.Schema{
+			Name:  "Test",
+			Multi: true,
+			Spec: conf.SectionSpec{
+				{Name: "Value", Type: conf.StringType},
+			},
+			Annotations: new(conf.Annotation).With(runtime.Unique("Value")),
+		}, runtime.Schema{
+			Name: "Single",
+			Spec: conf.SectionSpec{
+				{Name: "Value", Type: conf.StringType},
+			},
+		}, runtime.Schema{
+			Name:     "Internal",
+			Internal: true,
+			Spec: conf.SectionSpec{
+				{Name: "Value", Type: conf.StringType},
+			},
+		}))
+		schema.SetProvider(provider)
+
+		return schema
+	}
+
+	source := newSchema()
+	id, err := source.Create(ctx, "Test", []conf.Option{{Name: "Value", Value: "a"}})
+	require.NoError(t, err)
+	_, err = source.Create(ctx, "Internal", []conf.Option{{Name: "Value", Value: "secret"}})
+	require.NoError(t, err)
+
+	bundle, err := source.Export(ctx, "Test")
+	require.NoError(t, err)
+	require.Len(t, bundle.Sections, 1)
+
+	for _, format := range []string{runtime.BundleFormatConf, runtime.BundleFormatJSON} {
+		var buf bytes.Buffer
+		require.NoError(t, runtime.EncodeBundle(&buf, bundle, format))
+
+		decoded, err := runtime.DecodeBundle(&buf, "")
+		require.NoError(t, err, format)
+		assert.Equal(t, bundle, decoded, format)
+	}
+
+	// importing into the source does not change anything.
+	results := source.Import(ctx, bundle, false)
+	require.Len(t, results, 1)
+	assert.Equal(t, runtime.ImportActionUnchanged, results[0].Action)
+	assert.Equal(t, id, results[0].TargetID)
+
+	target := newSchema()
+	bundle.Sections = append(bundle.Sections, runtime.BundleSection{
+		Schema:  "Test",
+		Options: []conf.Option{{Name: "Value", Value: "a"}},
+	})
+
+	results = target.Import(ctx, bundle, true)
+	require.Len(t, results, 2)
+	assert.Empty(t, results[0].Error)
+	assert.Empty(t, results[0].TargetID)
+	assert.Empty(t, results[1].Error, "dry-run does not see other sections of the bundle")
+
+	all, err := target.All(ctx, "Test")
+	require.NoError(t, err)
+	assert.Empty(t, all)
+
+	results = target.Import(ctx, bundle, false)
+	require.Len(t, results, 2)
+	assert.Equal(t, runtime.ImportActionCreate, results[0].Action)
+	assert.Equal(t, id, results[0].TargetID, "the ID of the bundle is preserved")
+	assert.NotEmpty(t, results[1].Error)
+
+	all, err = target.All(ctx, "Test")
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	// importing the bundle again does not create duplicates.
+	results = target.Import(ctx, &runtime.Bundle{Sections: bundle.Sections[:1]}, false)
+	require.Len(t, results, 1)
+	assert.Equal(t, runtime.ImportActionUnchanged, results[0].Action)
+	assert.Equal(t, id, results[0].TargetID)
+
+	// instances of single-instance schemas are matched by schema.
+	_, err = source.Create(ctx, "Single", []conf.Option{{Name: "Value", Value: "source"}})
+	require.NoError(t, err)
+	existing, err := target.Create(ctx, "Single", []conf.Option{{Name: "Value", Value: "target"}})
+	require.NoError(t, err)
+
+	single, err := source.Export(ctx, "Single")
+	require.NoError(t, err)
+	require.Len(t, single.Sections, 1)
+
+	for _, action := range []string{runtime.ImportActionUpdate, runtime.ImportActionUnchanged} {
+		results = target.Import(ctx, single, false)
+		require.Len(t, results, 1)
+		assert.Empty(t, results[0].Error)
+		assert.Equal(t, action, results[0].Action)
+		assert.Equal(t, existing, results[0].TargetID)
+	}
+
+	all, err = target.All(ctx, "Single")
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, []string{"source"}, all[0].Options.GetStringSlice("Value"))
+}
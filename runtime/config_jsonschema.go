@@ -0,0 +1,201 @@
+package runtime
+
+import (
+	"reflect"
+
+	"github.com/ppacher/system-conf/conf"
+)
+
+// JSONSchemaDialect is the JSON Schema dialect used by JSONSchema.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches durations as accepted by time.ParseDuration.
+const durationPattern = `^[-+]?(0|([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
+
+// JSONSchema is the subset of JSON Schema used to describe configuration
+// schemas.
+type JSONSchema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type    string `json:"type,omitempty"`
+	Format  string `json:"format,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+
+	// Default is only omitted if it's nil. Zero values like false or 0
+	// are encoded as they are stored in the interface.
+	Default interface{} `json:"default,omitempty"`
+
+	Enum      []interface{} `json:"enum,omitempty"`
+	Examples  []interface{} `json:"examples,omitempty"`
+	ReadOnly  bool          `json:"readOnly,omitempty"`
+	WriteOnly bool          `json:"writeOnly,omitempty"`
+
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+
+	// Reference holds the OneOfRef annotation of the option. JSON Schema
+	// cannot express references to other configuration instances.
+	Reference *OneOfReference `json:"x-cis-reference,omitempty"`
+}
+
+// JSONSchema returns the JSON Schema for instances of the configuration
+// schema name. See SchemaToJSONSchema for details.
+func (schema *ConfigSchema) JSONSchema(name string) (*JSONSchema, error) {
+	reg, err := schema.SchemaByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return SchemaToJSONSchema(reg), nil
+}
+
+// SchemaToJSONSchema renders reg as JSON Schema describing the JSON
+// representation of a configuration instance as accepted by the API.
+// Options that are required but have a default value are not marked as
+// required as defaults are applied when an instance is created.
+func SchemaToJSONSchema(reg Schema) *JSONSchema {
+	noAdditional := false
+
+	result := &JSONSchema{
+		Schema:      JSONSchemaDialect,
+		Title:       reg.DisplayName,
+		Description: reg.Description,
+		Type:        "object",
+		Properties: map[string]*JSONSchema{
+			IDRef: {
+				Type:        "string",
+				Description: "The ID of the configuration instance",
+			},
+			ReadonlyRef: {
+				Type:        "boolean",
+				Description: "Set if the configuration instance cannot be modified",
+				ReadOnly:    true,
+			},
+		},
+		AdditionalProperties: &noAdditional,
+	}
+
+	for _, spec := range reg.Spec.All() {
+		result.Properties[spec.Name] = OptionToJSONSchema(spec)
+
+		if spec.Required && spec.Default == "" {
+			result.Required = append(result.Required, spec.Name)
+		}
+	}
+
+	return result
+}
+
+// OptionToJSONSchema renders spec as JSON Schema. Values of OneOf
+// annotations are used as enum or, if custom values are allowed, as
+// examples.
+func OptionToJSONSchema(spec conf.OptionSpec) *JSONSchema {
+	value := &JSONSchema{}
+
+	switch spec.Type {
+	case conf.BoolType:
+		value.Type = "boolean"
+	case conf.IntType, conf.IntSliceType:
+		value.Type = "integer"
+	case conf.FloatType, conf.FloatSliceType:
+		value.Type = "number"
+	case conf.DurationType, conf.DurationSliceType:
+		value.Type = "string"
+		value.Pattern = durationPattern
+	default:
+		value.Type = "string"
+	}
+
+	if format, ok := spec.Annotations.Get(stringFormatKey).(StringFormatAnnotation); ok && value.Type == "string" {
+		value.Format = format.Format
+	}
+
+	// secret values are redacted when reading configuration instances.
+	if IsSecret(spec) {
+		value.WriteOnly = true
+		if value.Format == "" {
+			value.Format = "password"
+		}
+	}
+
+	switch oneOf := spec.Annotations.Get(oneOfKey).(type) {
+	case OneOfAnnotation:
+		values := make([]interface{}, 0, len(oneOf.Values))
+		for _, possible := range oneOf.Values {
+			values = append(values, possible.Value)
+		}
+
+		if oneOf.AllowCustomValue {
+			value.Examples = values
+		} else {
+			// aliases are accepted as well but not suggested.
+			for _, possible := range oneOf.Values {
+				for _, alias := range possible.Aliases {
+					values = append(values, alias)
+				}
+			}
+
+			value.Enum = values
+		}
+
+	case OneOfReference:
+		ref := oneOf
+		value.Reference = &ref
+	}
+
+	var defaultValue interface{}
+	if spec.Default != "" {
+		defaultValue = decodeDefault(spec)
+	}
+
+	if spec.Type != nil && spec.Type.IsSliceType() {
+		result := &JSONSchema{
+			Type:        "array",
+			Description: spec.Description,
+			Items:       value,
+		}
+
+		if defaultValue != nil {
+			result.Default = []interface{}{defaultValue}
+		}
+
+		return result
+	}
+
+	value.Description = spec.Description
+	value.Default = defaultValue
+
+	return value
+}
+
+// decodeDefault returns the default value of spec using the JSON type
+// of the option.
+func decodeDefault(spec conf.OptionSpec) interface{} {
+	var (
+		target   interface{}
+		specType conf.OptionType
+	)
+
+	switch spec.Type {
+	case conf.BoolType:
+		target, specType = new(bool), conf.BoolType
+	case conf.IntType, conf.IntSliceType:
+		target, specType = new(int), conf.IntType
+	case conf.FloatType, conf.FloatSliceType:
+		target, specType = new(float64), conf.FloatType
+	default:
+		return spec.Default
+	}
+
+	// keep invalid defaults visible rather than hiding them.
+	if err := conf.DecodeValues([]string{spec.Default}, specType, target); err != nil {
+		return spec.Default
+	}
+
+	return reflect.ValueOf(target).Elem().Interface()
+}
@@ -0,0 +1,63 @@
+package runtime_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+func TestSchemaToJSONSchema(t *testing.T) {
+	schema := runtime.SchemaToJSONSchema(runtime.Schema{
+		Name:        "Test",
+		DisplayName: "Test",
+		Spec: conf.SectionSpec{
+			{Name: "Name", Type: conf.StringType, Required: true},
+			{Name: "Enabled", Type: conf.BoolType, Required: true, Default: "yes"},
+			{Name: "Closed", Type: conf.BoolType, Default: "no"},
+			{Name: "Retries", Type: conf.IntType, Default: "0"},
+			{Name: "Timeout", Type: conf.DurationType},
+			{
+				Name: "Kinds",
+				Type: conf.StringSliceType,
+				Annotations: new(conf.Annotation).With(
+					runtime.OneOf(runtime.PossibleValue{Value: "a"}, runtime.PossibleValue{Value: "b"}),
+				),
+			},
+			{
+				Name:        "Text",
+				Type:        conf.StringType,
+				Annotations: new(conf.Annotation).With(runtime.StringFormat("text/markdown")),
+			},
+		},
+	})
+
+	assert.Equal(t, runtime.JSONSchemaDialect, schema.Schema)
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, []string{"Name"}, schema.Required)
+
+	assert.Equal(t, "boolean", schema.Properties["Enabled"].Type)
+	assert.Equal(t, true, schema.Properties["Enabled"].Default)
+
+	// zero values are valid defaults and must be encoded.
+	for name, expected := range map[string]string{
+		"Closed":  `{"type":"boolean","default":false}`,
+		"Retries": `{"type":"integer","default":0}`,
+	} {
+		blob, err := json.Marshal(schema.Properties[name])
+		require.NoError(t, err)
+		assert.JSONEq(t, expected, string(blob), name)
+	}
+
+	assert.Equal(t, "string", schema.Properties["Timeout"].Type)
+	assert.Regexp(t, schema.Properties["Timeout"].Pattern, "1h30m")
+
+	kinds := schema.Properties["Kinds"]
+	assert.Equal(t, "array", kinds.Type)
+	assert.Equal(t, []interface{}{"a", "b"}, kinds.Items.Enum)
+
+	assert.Equal(t, "text/markdown", schema.Properties["Text"].Format)
+}
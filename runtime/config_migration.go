@@ -0,0 +1,258 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ppacher/system-conf/conf"
+)
+
+// ErrInvalidMigration is returned when registering a schema with invalid
+// option migrations.
+var ErrInvalidMigration = errors.New("config-schema: invalid option migration")
+
+// MigrationFunc transforms the options of a stored configuration
+// instance. Steps must not fail if the options they operate on are
+// missing as records that have been stored before versioning was
+// introduced run through all steps. Records of providers that don't
+// store a version are migrated each time they are read (see Migrator)
+// so steps must keep options that have already been migrated as is.
+type MigrationFunc func(opts []conf.Option) ([]conf.Option, error)
+
+// MigrationStep is a single step of an OptionMigration. Steps are
+// applied to stored records so values of secret options are still
+// encrypted and may only be moved between options but not modified.
+type MigrationStep struct {
+	apply MigrationFunc
+
+	// splits holds the options whose values are rewritten by the
+	// step.
+	splits []string
+}
+
+// Step returns a MigrationStep that applies fn. fn must not modify the
+// values of secret options.
+func Step(fn MigrationFunc) MigrationStep {
+	return MigrationStep{apply: fn}
+}
+
+// OptionMigration migrates stored configuration instances of a schema to
+// Version.
+type OptionMigration struct {
+	// Version is the schema version after the migration has been
+	// applied. It must be greater than zero.
+	Version int
+
+	// Description provides a short description of the migration.
+	Description string
+
+	// Steps are applied in order.
+	Steps []MigrationStep
+}
+
+// RenameOption renames all values of the option from to to. Existing
+// values of to are kept.
+func RenameOption(from, to string) MigrationStep {
+	return Step(func(opts []conf.Option) ([]conf.Option, error) {
+		result := make([]conf.Option, len(opts))
+		for idx, opt := range opts {
+			if strings.EqualFold(opt.Name, from) {
+				opt.Name = to
+			}
+
+			result[idx] = opt
+		}
+
+		return result, nil
+	})
+}
+
+// SplitValue splits each value of the option name at sep and assigns the
+// parts to the options targets in order. The option name is removed
+// unless it's part of targets. It's an error if a value has more parts
+// than there are targets. Values of secret options cannot be split.
+func SplitValue(name, sep string, targets ...string) MigrationStep {
+	split := func(opts []conf.Option) ([]conf.Option, error) {
+		result := make([]conf.Option, 0, len(opts))
+		for _, opt := range opts {
+			if !strings.EqualFold(opt.Name, name) {
+				result = append(result, opt)
+
+				continue
+			}
+
+			parts := strings.Split(opt.Value, sep)
+			if len(parts) > len(targets) {
+				return nil, fmt.Errorf("option %s: %q has more than %d parts", name, opt.Value, len(targets))
+			}
+
+			for idx, part := range parts {
+				result = append(result, conf.Option{
+					Name:  targets[idx],
+					Value: strings.TrimSpace(part),
+				})
+			}
+		}
+
+		return result, nil
+	}
+
+	return MigrationStep{
+		apply:  split,
+		splits: append([]string{name}, targets...),
+	}
+}
+
+// SetDefault sets the option name to value if it does not have a value
+// yet.
+func SetDefault(name, value string) MigrationStep {
+	return Step(func(opts []conf.Option) ([]conf.Option, error) {
+		for _, opt := range opts {
+			if strings.EqualFold(opt.Name, name) {
+				return opts, nil
+			}
+		}
+
+		result := make([]conf.Option, len(opts), len(opts)+1)
+		copy(result, opts)
+
+		return append(result, conf.Option{Name: name, Value: value}), nil
+	})
+}
+
+// DropOption removes all values of the option name.
+func DropOption(name string) MigrationStep {
+	return Step(func(opts []conf.Option) ([]conf.Option, error) {
+		result := make([]conf.Option, 0, len(opts))
+		for _, opt := range opts {
+			if !strings.EqualFold(opt.Name, name) {
+				result = append(result, opt)
+			}
+		}
+
+		return result, nil
+	})
+}
+
+// Apply applies all steps of m to opts.
+func (m OptionMigration) Apply(opts []conf.Option) ([]conf.Option, error) {
+	var err error
+	for _, step := range m.Steps {
+		opts, err = step.apply(opts)
+		if err != nil {
+			return nil, fmt.Errorf("version %d: %w", m.Version, err)
+		}
+	}
+
+	return opts, nil
+}
+
+// MigrateOptions applies all migrations of reg with a version greater
+// than from to opts.
+func (reg Schema) MigrateOptions(from int, opts []conf.Option) ([]conf.Option, error) {
+	var err error
+	for _, m := range reg.Migrations {
+		if m.Version <= from {
+			continue
+		}
+
+		opts, err = m.Apply(opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return opts, nil
+}
+
+// Migrator applies option migrations to records of providers that don't
+// store the schema version, like configuration files. Those records run
+// through all migrations of their schema each time they are read.
+// Providers that store a version migrate their records using the
+// schema package instead (see mongoprovider.Migrations).
+type Migrator map[string]Schema
+
+// NewMigrator returns a Migrator for the migrations of regs. The
+// migrations are captured when NewMigrator is called so all schemas must
+// already be registered.
+func NewMigrator(regs ...Schema) Migrator {
+	m := make(Migrator)
+	for _, reg := range regs {
+		if len(reg.Migrations) > 0 {
+			m[strings.ToLower(reg.Name)] = reg
+		}
+	}
+
+	return m
+}
+
+// Migrate applies all migrations of the schema secType to opts.
+func (m Migrator) Migrate(secType string, opts []conf.Option) ([]conf.Option, error) {
+	reg, ok := m[strings.ToLower(secType)]
+	if !ok {
+		return opts, nil
+	}
+
+	return reg.MigrateOptions(0, opts)
+}
+
+// SchemaVersion returns the current version of the schema name. It
+// returns zero for unknown schemas.
+func (schema *ConfigSchema) SchemaVersion(name string) int {
+	reg, err := schema.SchemaByName(name)
+	if err != nil {
+		return 0
+	}
+
+	return reg.Version
+}
+
+// prepareMigrations sorts the migrations of reg by version and ensures
+// reg.Version is not lower than the last migration.
+func prepareMigrations(reg *Schema) error {
+	if len(reg.Migrations) == 0 {
+		return nil
+	}
+
+	migrations := make([]OptionMigration, len(reg.Migrations))
+	copy(migrations, reg.Migrations)
+	sort.SliceStable(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for idx, m := range migrations {
+		if m.Version <= 0 {
+			return fmt.Errorf("%w: version must be greater than zero", ErrInvalidMigration)
+		}
+
+		if idx > 0 && migrations[idx-1].Version == m.Version {
+			return fmt.Errorf("%w: duplicate version %d", ErrInvalidMigration, m.Version)
+		}
+
+		for _, step := range m.Steps {
+			if step.apply == nil {
+				return fmt.Errorf("%w: version %d: empty step", ErrInvalidMigration, m.Version)
+			}
+
+			for _, name := range step.splits {
+				if spec, ok := reg.Spec.GetOption(strings.ToLower(name)); ok && IsSecret(spec) {
+					return fmt.Errorf("%w: version %d: secret option %s cannot be split", ErrInvalidMigration, m.Version, name)
+				}
+			}
+		}
+	}
+
+	last := migrations[len(migrations)-1].Version
+	switch {
+	case reg.Version == 0:
+		reg.Version = last
+	case reg.Version < last:
+		return fmt.Errorf("%w: version %d is lower than migration %d", ErrInvalidMigration, reg.Version, last)
+	}
+
+	reg.Migrations = migrations
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package runtime_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+func TestOptionMigrations(t *testing.T) {
+	schema := new(runtime.ConfigSchema)
+
+	err := schema.Register(runtime.Schema{
+		Name: "Door",
+		Spec: conf.SectionSpec{
+			{Name: "ScriptURL", Type: conf.StringType},
+			{Name: "Username", Type: conf.StringType},
+			{Name: "Password", Type: conf.StringType},
+			{Name: "Type", Type: conf.StringType},
+		},
+		Migrations: []runtime.OptionMigration{
+			{
+				Version: 2,
+				Steps: []runtime.MigrationStep{
+					runtime.SplitValue("Credentials", ":", "Username", "Password"),
+					runtime.DropOption("Legacy"),
+				},
+			},
+			{
+				Version: 1,
+				Steps: []runtime.MigrationStep{
+					runtime.RenameOption("ShellyScriptURL", "ScriptURL"),
+					runtime.SetDefault("Type", "shelly"),
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	reg, err := schema.SchemaByName("door")
+	require.NoError(t, err)
+	assert.Equal(t, 2, reg.Version)
+	assert.Equal(t, 2, schema.SchemaVersion("door"))
+
+	stored := []conf.Option{
+		{Name: "ShellyScriptURL", Value: "http://door"},
+		{Name: "Credentials", Value: "user:secret"},
+		{Name: "Legacy", Value: "yes"},
+	}
+
+	migrated, err := reg.MigrateOptions(0, stored)
+	require.NoError(t, err)
+	assert.Equal(t, []conf.Option{
+		{Name: "ScriptURL", Value: "http://door"},
+		{Name: "Username", Value: "user"},
+		{Name: "Password", Value: "secret"},
+		{Name: "Type", Value: "shelly"},
+	}, migrated)
+
+	// records already at version 1 only run the second migration.
+	migrated, err = reg.MigrateOptions(1, stored)
+	require.NoError(t, err)
+	assert.Equal(t, "ShellyScriptURL", migrated[0].Name)
+	assert.Len(t, migrated, 3)
+
+	_, err = reg.MigrateOptions(1, []conf.Option{{Name: "Credentials", Value: "a:b:c"}})
+	assert.Error(t, err)
+
+	err = schema.Register(runtime.Schema{
+		Name:       "Invalid",
+		Spec:       conf.SectionSpec{},
+		Version:    1,
+		Migrations: []runtime.OptionMigration{{Version: 2}},
+	})
+	assert.True(t, errors.Is(err, runtime.ErrInvalidMigration))
+
+	// secret values are encrypted at rest and cannot be split.
+	err = schema.Register(runtime.Schema{
+		Name: "Secret",
+		Spec: conf.SectionSpec{
+			{Name: "Username", Type: conf.StringType},
+			{Name: "Password", Type: conf.StringType, Annotations: new(conf.Annotation).With(runtime.Secret())},
+		},
+		Migrations: []runtime.OptionMigration{
+			{
+				Version: 1,
+				Steps: []runtime.MigrationStep{
+					runtime.SplitValue("Credentials", ":", "Username", "Password"),
+				},
+			},
+		},
+	})
+	assert.True(t, errors.Is(err, runtime.ErrInvalidMigration))
+}
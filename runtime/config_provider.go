@@ -14,6 +14,9 @@ var (
 	ErrReadOnly           = errors.New("config-provider: provider is read-only")
 	ErrUnknownConfigTest  = errors.New("config: unknown configuration tests identifier")
 	ErrUnknownType        = errors.New("config: unknown-type")
+	ErrWatchNotSupported  = errors.New("config-provider: provider does not support watching for changes")
+	ErrIDNotSupported     = errors.New("config-provider: provider does not support creating sections with a given id")
+	ErrInvalidID          = errors.New("config-provider: invalid section id")
 )
 
 type NotificationError struct {
@@ -31,6 +34,17 @@ func (nf *NotificationError) Unwrap() error {
 type Section struct {
 	ID string
 	conf.Section
+
+	// Annotations may hold additional annotations about this
+	// configuration instance, like Readonly().
+	Annotations conf.Annotation
+}
+
+// IsReadonly returns true if sec is annotated as read-only.
+func (sec Section) IsReadonly() bool {
+	readonly, _ := sec.Annotations.Get(readonlyKey).(bool)
+
+	return readonly
 }
 
 // Decode is a shortcut for using conf.DecodeSections with sec only.
@@ -59,3 +73,41 @@ type ConfigProvider interface {
 	// GetID returns the section by ID.
 	GetID(ctx context.Context, id string) (Section, error)
 }
+
+// IDConfigProvider is implemented by configuration providers that can
+// create sections using a given ID. It's used to keep the IDs of imported
+// sections so importing the same bundle again updates them.
+type IDConfigProvider interface {
+	ConfigProvider
+
+	// CreateWithID stores a new configuration section using id. It
+	// should return an error wrapping ErrInvalidID if id cannot be used
+	// by the provider.
+	CreateWithID(ctx context.Context, id string, sec conf.Section) error
+}
+
+// ConfigChange describes a change of a configuration section that has
+// been detected by a WatchableConfigProvider.
+type ConfigChange struct {
+	// Type is one of ChangeTypeCreate, ChangeTypeUpdate or
+	// ChangeTypeDelete. Providers that cannot distinguish between
+	// creates and updates may always use ChangeTypeUpdate.
+	Type string
+
+	// Section holds the configuration section after the change. For
+	// deletes only Section.ID is guaranteed to be set.
+	Section Section
+}
+
+// WatchableConfigProvider is implemented by configuration providers
+// that can detect changes made outside of ConfigSchema, e.g. by other
+// cisd instances or by editing the configuration storage directly.
+type WatchableConfigProvider interface {
+	ConfigProvider
+
+	// Watch starts watching for configuration changes and publishes them
+	// on the returned channel until ctx is cancelled. The channel is closed
+	// when watching stops. Providers may also report changes that have been
+	// made through the provider itself.
+	Watch(ctx context.Context) (<-chan ConfigChange, error)
+}
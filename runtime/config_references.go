@@ -0,0 +1,270 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
+)
+
+// ErrStillReferenced is returned when a configuration instance cannot be
+// deleted or changed because other instances still reference it.
+var ErrStillReferenced = errors.New("config: instance is still referenced")
+
+// ReferenceResolver resolves the possible values of a OneOfReference that
+// does not refer to a configuration schema, like RolesRef or UsersRef.
+type ReferenceResolver interface {
+	// ResolveReference returns all possible values of valueField.
+	ResolveReference(ctx context.Context, valueField string) ([]string, error)
+}
+
+// ReferenceResolverFunc is a convenience type for implementing
+// ReferenceResolver.
+type ReferenceResolverFunc func(ctx context.Context, valueField string) ([]string, error)
+
+// ResolveReference implements ReferenceResolver.
+func (fn ReferenceResolverFunc) ResolveReference(ctx context.Context, valueField string) ([]string, error) {
+	return fn(ctx, valueField)
+}
+
+// AddReferenceResolver registers resolver for all OneOfReference
+// annotations that refer to schemaType. Resolvers take precedence over
+// configuration schemas with the same name.
+func (schema *ConfigSchema) AddReferenceResolver(schemaType string, resolver ReferenceResolver) {
+	schema.rw.Lock()
+	defer schema.rw.Unlock()
+
+	if schema.resolvers == nil {
+		schema.resolvers = make(map[string]ReferenceResolver)
+	}
+
+	schema.resolvers[strings.ToLower(schemaType)] = resolver
+}
+
+// validateReferences ensures all options of sec that are annotated with
+// OneOf or OneOfRef hold allowed values. overlay holds changes that
+// should be considered when looking up referenced instances.
+func (schema *ConfigSchema) validateReferences(ctx context.Context, reg Schema, sec Section, overlay []ConfigChange) error {
+	var errs []error
+
+	for _, spec := range reg.Spec.All() {
+		values := conf.Options(sec.Options).GetStringSlice(spec.Name)
+		if len(values) == 0 {
+			continue
+		}
+
+		allowed, ok, err := schema.allowedValues(ctx, spec, overlay)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			continue
+		}
+
+		for _, value := range values {
+			if !containsFold(allowed, value) {
+				errs = append(errs, fmt.Errorf("option %s: %q is not an allowed value", spec.Name, value))
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return httperr.BadRequest(err.Error())
+	}
+
+	return nil
+}
+
+// allowedValues returns all allowed values for spec. The boolean return
+// value is false if spec does not restrict its values.
+func (schema *ConfigSchema) allowedValues(ctx context.Context, spec conf.OptionSpec, overlay []ConfigChange) ([]string, bool, error) {
+	switch oneOf := spec.Annotations.Get(oneOfKey).(type) {
+	case OneOfAnnotation:
+		if oneOf.AllowCustomValue {
+			return nil, false, nil
+		}
+
+		values := make([]string, 0, len(oneOf.Values))
+		for _, value := range oneOf.Values {
+			values = append(values, fmt.Sprint(value.Value))
+			values = append(values, value.Aliases...)
+		}
+
+		return values, true, nil
+
+	case OneOfReference:
+		if oneOf.AllowCustomValue {
+			return nil, false, nil
+		}
+
+		key := strings.ToLower(oneOf.SchemaType)
+
+		if resolver, ok := schema.resolvers[key]; ok {
+			values, err := resolver.ResolveReference(ctx, oneOf.ValueField)
+			if err != nil {
+				// don't block configuration changes while an external
+				// service is unavailable.
+				log.From(ctx).Errorf("option %s: failed to resolve %s, skipping validation: %s", spec.Name, oneOf.SchemaType, err)
+
+				return nil, false, nil
+			}
+
+			return values, true, nil
+		}
+
+		// references to unknown schemas cannot be enforced.
+		if _, ok := schema.entries[key]; !ok {
+			return nil, false, nil
+		}
+
+		instances, err := schema.instances(ctx, oneOf.SchemaType, overlay)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var values []string
+		for _, instance := range instances {
+			values = append(values, conf.Options(instance.Options).GetStringSlice(oneOf.ValueField)...)
+		}
+
+		return values, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// ensureNotReferenced returns ErrStillReferenced if any instance refers
+// to a value of the removed sections that is no longer available after
+// applying overlay. removed holds the sections as currently stored.
+func (schema *ConfigSchema) ensureNotReferenced(ctx context.Context, removed []Section, overlay []ConfigChange) error {
+	// sort schemas so errors are reported in a stable order.
+	regs := make([]Schema, 0, len(schema.entries))
+	for _, reg := range schema.entries {
+		regs = append(regs, reg)
+	}
+	sort.Sort(schemaByName(regs))
+
+	var errs []error
+
+	for _, sec := range removed {
+		for _, reg := range regs {
+			for _, spec := range reg.Spec.All() {
+				ref, ok := spec.Annotations.Get(oneOfKey).(OneOfReference)
+				if !ok || !strings.EqualFold(ref.SchemaType, sec.Name) {
+					continue
+				}
+
+				if _, ok := schema.resolvers[strings.ToLower(ref.SchemaType)]; ok {
+					continue
+				}
+
+				lost, err := schema.lostValues(ctx, sec, ref, overlay)
+				if err != nil {
+					return err
+				}
+
+				if len(lost) == 0 {
+					continue
+				}
+
+				referrers, err := schema.instances(ctx, reg.Name, overlay)
+				if err != nil {
+					return err
+				}
+
+				for _, referrer := range referrers {
+					for _, value := range conf.Options(referrer.Options).GetStringSlice(spec.Name) {
+						if containsFold(lost, value) {
+							errs = append(errs, fmt.Errorf("%s %s: %q is still used by %s %s (option %s)", sec.Name, sec.ID, value, reg.Name, referrer.ID, spec.Name))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return httperr.Conflict(err.Error()).SetInternal(ErrStillReferenced)
+	}
+
+	return nil
+}
+
+// lostValues returns the values of ref.ValueField provided by sec that
+// are not provided by any instance after applying overlay.
+func (schema *ConfigSchema) lostValues(ctx context.Context, sec Section, ref OneOfReference, overlay []ConfigChange) ([]string, error) {
+	values := conf.Options(sec.Options).GetStringSlice(ref.ValueField)
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	instances, err := schema.instances(ctx, sec.Name, overlay)
+	if err != nil {
+		return nil, err
+	}
+
+	var remaining []string
+	for _, instance := range instances {
+		remaining = append(remaining, conf.Options(instance.Options).GetStringSlice(ref.ValueField)...)
+	}
+
+	var lost []string
+	for _, value := range values {
+		if !containsFold(remaining, value) {
+			lost = append(lost, value)
+		}
+	}
+
+	return lost, nil
+}
+
+// instances returns all instances of secType after applying overlay.
+func (schema *ConfigSchema) instances(ctx context.Context, secType string, overlay []ConfigChange) ([]Section, error) {
+	all, err := schema.provider.Get(ctx, secType)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]ConfigChange, len(overlay))
+	for _, change := range overlay {
+		if change.Type != ChangeTypeCreate && change.Section.ID != "" {
+			changes[change.Section.ID] = change
+		}
+	}
+
+	result := make([]Section, 0, len(all)+len(overlay))
+	for _, sec := range all {
+		if change, ok := changes[sec.ID]; ok {
+			if change.Type == ChangeTypeDelete {
+				continue
+			}
+
+			sec.Options = change.Section.Options
+		}
+
+		result = append(result, sec)
+	}
+
+	for _, change := range overlay {
+		if change.Type == ChangeTypeCreate && strings.EqualFold(change.Section.Name, secType) {
+			result = append(result, change.Section)
+		}
+	}
+
+	return result, nil
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
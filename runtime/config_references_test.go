@@ -0,0 +1,110 @@
+package runtime_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/dirprovider"
+)
+
+func TestReferences(t *testing.T) {
+	ctx := context.Background()
+
+	provider, err := dirprovider.New(t.TempDir())
+	require.NoError(t, err)
+
+	schema := new(runtime.ConfigSchema)
+	require.NoError(t, schema.Register(runtime.Schema{
+		Name:  "Target",
+		Multi: true,
+		Spec: conf.SectionSpec{
+			{Name: "Name", Type: conf.StringType},
+		},
+	}, runtime.Schema{
+		Name:  "Referrer",
+		Multi: true,
+		Spec: conf.SectionSpec{
+			{
+				Name: "Target",
+				Type: conf.StringSliceType,
+				Annotations: new(conf.Annotation).With(
+					runtime.OneOfRef("Target", "Name", ""),
+				),
+			},
+			{
+				Name: "Kind",
+				Type: conf.StringType,
+				Annotations: new(conf.Annotation).With(
+					runtime.OneOf(runtime.PossibleValue{Value: "a"}, runtime.PossibleValue{Value: "b"}),
+				),
+			},
+			{
+				Name: "Role",
+				Type: conf.StringType,
+				Annotations: new(conf.Annotation).With(
+					runtime.OneOfRoles,
+				),
+			},
+		},
+	}))
+	schema.SetProvider(provider)
+	schema.AddReferenceResolver(runtime.RolesRef, runtime.ReferenceResolverFunc(func(_ context.Context, field string) ([]string, error) {
+		assert.Equal(t, "name", field)
+
+		return []string{"admin"}, nil
+	}))
+
+	targetID, err := schema.Create(ctx, "Target", []conf.Option{{Name: "Name", Value: "first"}})
+	require.NoError(t, err)
+
+	for _, opts := range [][]conf.Option{
+		{{Name: "Target", Value: "missing"}},
+		{{Name: "Kind", Value: "c"}},
+		{{Name: "Role", Value: "user"}},
+	} {
+		_, err := schema.Create(ctx, "Referrer", opts)
+		assert.Error(t, err, opts)
+	}
+
+	referrerID, err := schema.Create(ctx, "Referrer", []conf.Option{
+		{Name: "Target", Value: "first"},
+		{Name: "Kind", Value: "a"},
+		{Name: "Role", Value: "admin"},
+	})
+	require.NoError(t, err)
+
+	// referenced values can neither be deleted nor renamed.
+	err = schema.Delete(ctx, targetID)
+	assert.True(t, errors.Is(err, runtime.ErrStillReferenced), err)
+
+	err = schema.Update(ctx, targetID, "Target", []conf.Option{{Name: "Name", Value: "renamed"}})
+	assert.True(t, errors.Is(err, runtime.ErrStillReferenced), err)
+
+	// a batch may move the reference and delete the target at once.
+	_, err = schema.Batch(ctx, []runtime.ConfigChange{
+		{
+			Type: runtime.ChangeTypeCreate,
+			Section: runtime.Section{Section: conf.Section{
+				Name:    "Target",
+				Options: []conf.Option{{Name: "Name", Value: "second"}},
+			}},
+		},
+		{
+			Type: runtime.ChangeTypeUpdate,
+			Section: runtime.Section{ID: referrerID, Section: conf.Section{
+				Name:    "Referrer",
+				Options: []conf.Option{{Name: "Target", Value: "second"}},
+			}},
+		},
+		{
+			Type:    runtime.ChangeTypeDelete,
+			Section: runtime.Section{ID: targetID},
+		},
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,285 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/runtime/session"
+)
+
+// Common errors when working with configuration revisions.
+var (
+	ErrNoRevision       = errors.New("config: no revision found")
+	ErrRevisionDisabled = errors.New("config: revisions are disabled")
+)
+
+// RevisionRef references a single revision of a configuration instance.
+type RevisionRef struct {
+	InstanceID string `json:"instanceId" bson:"instanceId"`
+	Version    int    `json:"version" bson:"version"`
+}
+
+// ConfigRevision describes the state of a configuration instance after
+// a create, update or delete.
+type ConfigRevision struct {
+	RevisionRef `bson:",inline"`
+
+	// Schema is the name of the configuration schema.
+	Schema string `json:"schema" bson:"schema"`
+
+	// Type is one of ChangeTypeCreate, ChangeTypeUpdate or
+	// ChangeTypeDelete.
+	Type string    `json:"type" bson:"type"`
+	Time time.Time `json:"time" bson:"time"`
+
+	// Author holds the name of the user that performed the change,
+	// if known.
+	Author string `json:"author,omitempty" bson:"author,omitempty"`
+
+	// RollbackOf is set if the revision has been created by
+	// ConfigSchema.Rollback.
+	RollbackOf *RevisionRef `json:"rollbackOf,omitempty" bson:"rollbackOf,omitempty"`
+
+	// Options holds all options of the instance after the change. It's
+	// empty for deletes.
+	Options []conf.Option `json:"options,omitempty" bson:"options,omitempty"`
+}
+
+// OptionDiff describes the values of an option in two revisions.
+type OptionDiff struct {
+	Name   string   `json:"name"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// RevisionStore stores configuration revisions.
+type RevisionStore interface {
+	// AppendRevision stores rev and assigns the next version of
+	// rev.InstanceID to rev.Version.
+	AppendRevision(ctx context.Context, rev *ConfigRevision) error
+
+	// ListRevisions returns all revisions of instanceID, newest first.
+	ListRevisions(ctx context.Context, instanceID string) ([]ConfigRevision, error)
+
+	// GetRevision returns a revision of instanceID or ErrNoRevision.
+	GetRevision(ctx context.Context, instanceID string, version int) (*ConfigRevision, error)
+}
+
+type rollbackContextKey struct{}
+
+// SetRevisionStore configures schema to record a revision for each
+// configuration change in store.
+func (schema *ConfigSchema) SetRevisionStore(store RevisionStore) {
+	schema.revisionLock.Lock()
+	defer schema.revisionLock.Unlock()
+
+	schema.revisions = store
+}
+
+func (schema *ConfigSchema) revisionStore() (RevisionStore, error) {
+	schema.revisionLock.RLock()
+	defer schema.revisionLock.RUnlock()
+
+	if schema.revisions == nil {
+		return nil, ErrRevisionDisabled
+	}
+
+	return schema.revisions, nil
+}
+
+// Revisions returns all revisions of the configuration instance id,
+// newest first.
+func (schema *ConfigSchema) Revisions(ctx context.Context, id string) ([]ConfigRevision, error) {
+	store, err := schema.revisionStore()
+	if err != nil {
+		return nil, err
+	}
+
+	return store.ListRevisions(ctx, id)
+}
+
+// Revision returns a single revision of the configuration instance id.
+func (schema *ConfigSchema) Revision(ctx context.Context, id string, version int) (*ConfigRevision, error) {
+	store, err := schema.revisionStore()
+	if err != nil {
+		return nil, err
+	}
+
+	return store.GetRevision(ctx, id, version)
+}
+
+// DiffRevisions returns the options of instance id that differ between
+// the revisions from and to. If to is zero the current configuration of
+// id is used instead. Values of secret options are redacted.
+func (schema *ConfigSchema) DiffRevisions(ctx context.Context, id string, from, to int) ([]OptionDiff, error) {
+	before, err := schema.Revision(ctx, id, from)
+	if err != nil {
+		return nil, err
+	}
+
+	var after []conf.Option
+	if to == 0 {
+		current, err := schema.GetID(ctx, id)
+		switch {
+		case errors.Is(err, ErrCfgSectionNotFound):
+		case err != nil:
+			return nil, err
+		default:
+			// revisions are stored redacted so the current secrets must
+			// not be revealed either.
+			after = current.Options
+			if reg, err := schema.SchemaByName(current.Name); err == nil {
+				after = RedactSecrets(reg.Spec, after)
+			}
+		}
+	} else {
+		rev, err := schema.Revision(ctx, id, to)
+		if err != nil {
+			return nil, err
+		}
+
+		after = rev.Options
+	}
+
+	return DiffOptions(before.Options, after), nil
+}
+
+// Rollback restores the configuration instance id to the state recorded
+// in revision version. The change goes through the same validation and
+// change listeners as any other change. If the instance has been deleted
+// in the meantime it's created again using a new ID. The ID of the
+// restored instance is returned.
+func (schema *ConfigSchema) Rollback(ctx context.Context, id string, version int) (string, error) {
+	rev, err := schema.Revision(ctx, id, version)
+	if err != nil {
+		return "", err
+	}
+
+	exists := true
+	if _, err := schema.GetID(ctx, id); err != nil {
+		if !errors.Is(err, ErrCfgSectionNotFound) {
+			return "", err
+		}
+
+		exists = false
+	}
+
+	ctx = context.WithValue(ctx, rollbackContextKey{}, &rev.RevisionRef)
+
+	switch {
+	case rev.Type == ChangeTypeDelete && exists:
+		return id, schema.Delete(ctx, id)
+
+	case rev.Type == ChangeTypeDelete:
+		return id, nil
+
+	case exists:
+		return id, schema.Update(ctx, id, rev.Schema, rev.Options)
+
+	default:
+		return schema.Create(ctx, rev.Schema, rev.Options)
+	}
+}
+
+// recordRevision stores a new revision for a change made through schema.
+// Errors are only logged as the change itself has already been applied.
+// Values of secret options are redacted so a rollback keeps the current
+// secrets.
+func (schema *ConfigSchema) recordRevision(ctx context.Context, changeType, id, secType string, opts []conf.Option) {
+	store, err := schema.revisionStore()
+	if err != nil {
+		return
+	}
+
+	if reg, ok := schema.entries[strings.ToLower(secType)]; ok {
+		opts = RedactSecrets(reg.Spec, opts)
+	}
+
+	rev := &ConfigRevision{
+		RevisionRef: RevisionRef{
+			InstanceID: id,
+		},
+		Schema:  secType,
+		Type:    changeType,
+		Time:    time.Now(),
+		Options: opts,
+	}
+
+	if ref, ok := ctx.Value(rollbackContextKey{}).(*RevisionRef); ok {
+		rev.RollbackOf = ref
+	}
+
+	if user := session.UserFromCtx(ctx).GetUser(); user != nil {
+		rev.Author = user.GetUsername()
+		if rev.Author == "" {
+			rev.Author = user.GetId()
+		}
+	}
+
+	if err := store.AppendRevision(ctx, rev); err != nil {
+		log.From(ctx).Errorf("failed to record revision of %s %s: %s", secType, id, err)
+	}
+}
+
+// DiffOptions returns the options that differ between before and after
+// sorted by name.
+func DiffOptions(before, after []conf.Option) []OptionDiff {
+	group := func(opts []conf.Option) map[string][]string {
+		m := make(map[string][]string)
+		for _, opt := range opts {
+			name := strings.ToLower(opt.Name)
+			m[name] = append(m[name], opt.Value)
+		}
+
+		return m
+	}
+
+	b, a := group(before), group(after)
+
+	// keep the spelling of option names as used in the options.
+	names := make(map[string]string)
+	for _, opts := range [][]conf.Option{after, before} {
+		for _, opt := range opts {
+			names[strings.ToLower(opt.Name)] = opt.Name
+		}
+	}
+
+	keys := make([]string, 0, len(names))
+	for key := range names {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result []OptionDiff
+	for _, key := range keys {
+		if equalValues(b[key], a[key]) {
+			continue
+		}
+
+		result = append(result, OptionDiff{
+			Name:   names[key],
+			Before: b[key],
+			After:  a[key],
+		})
+	}
+
+	return result
+}
+
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+
+	return true
+}
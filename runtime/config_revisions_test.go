@@ -0,0 +1,125 @@
+package runtime_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/dirprovider"
+)
+
+type memoryRevisions struct {
+	l         sync.Mutex
+	revisions []runtime.ConfigRevision
+}
+
+func (m *memoryRevisions) AppendRevision(_ context.Context, rev *runtime.ConfigRevision) error {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	rev.Version = 1
+	for _, r := range m.revisions {
+		if r.InstanceID == rev.InstanceID && r.Version >= rev.Version {
+			rev.Version = r.Version + 1
+		}
+	}
+
+	m.revisions = append(m.revisions, *rev)
+
+	return nil
+}
+
+func (m *memoryRevisions) ListRevisions(_ context.Context, id string) ([]runtime.ConfigRevision, error) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	var result []runtime.ConfigRevision
+	for _, r := range m.revisions {
+		if r.InstanceID == id {
+			result = append(result, r)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Version > result[j].Version })
+
+	return result, nil
+}
+
+func (m *memoryRevisions) GetRevision(_ context.Context, id string, version int) (*runtime.ConfigRevision, error) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	for _, r := range m.revisions {
+		if r.InstanceID == id && r.Version == version {
+			return &r, nil
+		}
+	}
+
+	return nil, runtime.ErrNoRevision
+}
+
+func TestRevisions(t *testing.T) {
+	ctx := context.Background()
+
+	provider, err := dirprovider.New(t.TempDir())
+	require.NoError(t, err)
+
+	schema := new(runtime.ConfigSchema)
+	require.NoError(t, schema.Register(runtime.Schema{
+		Name:  "Test",
+		Multi: true,
+		Spec: conf.SectionSpec{
+			{Name: "Value", Type: conf.StringType},
+		},
+	}))
+	schema.SetProvider(provider)
+
+	_, err = schema.Revisions(ctx, "unknown")
+	require.ErrorIs(t, err, runtime.ErrRevisionDisabled)
+
+	schema.SetRevisionStore(new(memoryRevisions))
+
+	value := func(v string) []conf.Option {
+		return []conf.Option{{Name: "Value", Value: v}}
+	}
+
+	id, err := schema.Create(ctx, "Test", value("a"))
+	require.NoError(t, err)
+	require.NoError(t, schema.Update(ctx, id, "Test", value("b")))
+
+	diff, err := schema.DiffRevisions(ctx, id, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []runtime.OptionDiff{
+		{Name: "Value", Before: []string{"a"}, After: []string{"b"}},
+	}, diff)
+
+	// rollback to the first revision
+	restored, err := schema.Rollback(ctx, id, 1)
+	require.NoError(t, err)
+	assert.Equal(t, id, restored)
+
+	sec, err := schema.GetID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, sec.Options.GetStringSlice("Value"))
+
+	revisions, err := schema.Revisions(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	assert.Equal(t, runtime.ChangeTypeUpdate, revisions[0].Type)
+	assert.Equal(t, &runtime.RevisionRef{InstanceID: id, Version: 1}, revisions[0].RollbackOf)
+
+	// restoring a deleted instance creates a new one
+	require.NoError(t, schema.Delete(ctx, id))
+	restored, err = schema.Rollback(ctx, id, 2)
+	require.NoError(t, err)
+	assert.NotEqual(t, id, restored)
+
+	sec, err = schema.GetID(ctx, restored)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, sec.Options.GetStringSlice("Value"))
+}
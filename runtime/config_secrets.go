@@ -0,0 +1,121 @@
+package runtime
+
+import (
+	"strings"
+
+	"github.com/ppacher/system-conf/conf"
+)
+
+// SecretPlaceholder replaces the values of secret options in redacted
+// configuration data. Creates and updates that use SecretPlaceholder as
+// the value of a secret option keep the currently stored value.
+const SecretPlaceholder = "<unchanged>"
+
+// RedactSecrets returns a copy of opts with all values of secret options
+// in spec replaced by SecretPlaceholder.
+func RedactSecrets(spec conf.OptionRegistry, opts []conf.Option) []conf.Option {
+	if spec == nil {
+		return opts
+	}
+
+	result := make([]conf.Option, len(opts))
+	for idx, opt := range opts {
+		if opt.Value != "" {
+			if optSpec, ok := spec.GetOption(strings.ToLower(opt.Name)); ok && IsSecret(optSpec) {
+				opt.Value = SecretPlaceholder
+			}
+		}
+
+		result[idx] = opt
+	}
+
+	return result
+}
+
+// restoreSecrets replaces SecretPlaceholder values of secret options in
+// opts with the values of existing. Placeholders of options that do not
+// have an existing value are dropped.
+func restoreSecrets(spec conf.OptionRegistry, existing, opts []conf.Option) []conf.Option {
+	if spec == nil {
+		return opts
+	}
+
+	result := make([]conf.Option, 0, len(opts))
+	restored := make(map[string]bool)
+
+	for _, opt := range opts {
+		if opt.Value != SecretPlaceholder {
+			result = append(result, opt)
+
+			continue
+		}
+
+		optSpec, ok := spec.GetOption(strings.ToLower(opt.Name))
+		if !ok || !IsSecret(optSpec) {
+			result = append(result, opt)
+
+			continue
+		}
+
+		// multiple placeholders of a slice option restore all values
+		// once.
+		lower := strings.ToLower(opt.Name)
+		if restored[lower] {
+			continue
+		}
+		restored[lower] = true
+
+		for _, value := range existing {
+			if strings.EqualFold(value.Name, opt.Name) {
+				result = append(result, conf.Option{
+					Name:  opt.Name,
+					Value: value.Value,
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+// missingSecrets returns the names of secret options that use
+// SecretPlaceholder in opts but do not have a value in existing.
+// restoreSecrets drops those options.
+func missingSecrets(spec conf.OptionRegistry, existing, opts []conf.Option) []string {
+	if spec == nil {
+		return nil
+	}
+
+	var (
+		result []string
+		seen   = make(map[string]bool)
+	)
+
+	for _, opt := range opts {
+		lower := strings.ToLower(opt.Name)
+		if opt.Value != SecretPlaceholder || seen[lower] {
+			continue
+		}
+		seen[lower] = true
+
+		optSpec, ok := spec.GetOption(lower)
+		if !ok || !IsSecret(optSpec) {
+			continue
+		}
+
+		found := false
+		for _, value := range existing {
+			if strings.EqualFold(value.Name, opt.Name) {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			result = append(result, opt.Name)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,152 @@
+package runtime_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/dirprovider"
+)
+
+func newSecretSchema(t *testing.T, tests ...runtime.ConfigTest) *runtime.ConfigSchema {
+	t.Helper()
+
+	provider, err := dirprovider.New(t.TempDir())
+	require.NoError(t, err)
+
+	schema := new(runtime.ConfigSchema)
+	require.NoError(t, schema.Register(runtime.Schema{
+		Name:  "Device",
+		Multi: true,
+		Spec: conf.SectionSpec{
+			{Name: "Name", Type: conf.StringType},
+			{Name: "Password", Type: conf.StringType, Annotations: new(conf.Annotation).With(runtime.Secret())},
+		},
+		Tests: tests,
+	}))
+	schema.SetProvider(provider)
+
+	return schema
+}
+
+func TestConfigTestSecrets(t *testing.T) {
+	ctx := context.Background()
+
+	var tested []conf.Option
+	schema := newSecretSchema(t, runtime.ConfigTest{
+		ID:   "connect",
+		Spec: conf.SectionSpec{},
+		TestFunc: func(_ context.Context, config, _ []conf.Option) (*runtime.TestResult, error) {
+			tested = config
+
+			return nil, nil
+		},
+	})
+
+	id, err := schema.Create(ctx, "Device", []conf.Option{
+		{Name: "Name", Value: "door"},
+		{Name: "Password", Value: "s3cr3t"},
+	})
+	require.NoError(t, err)
+
+	redacted := []conf.Option{
+		{Name: "Name", Value: "door"},
+		{Name: "Password", Value: runtime.SecretPlaceholder},
+	}
+
+	// the stored secret is used for redacted values of the instance.
+	_, err = schema.Test(ctx, "Device", "connect", id, redacted, nil)
+	require.NoError(t, err)
+	password, _ := conf.Options(tested).GetString("Password")
+	assert.Equal(t, "s3cr3t", password)
+
+	// new values are tested as is.
+	_, err = schema.Test(ctx, "Device", "connect", id, []conf.Option{
+		{Name: "Name", Value: "door"},
+		{Name: "Password", Value: "changed"},
+	}, nil)
+	require.NoError(t, err)
+	password, _ = conf.Options(tested).GetString("Password")
+	assert.Equal(t, "changed", password)
+
+	// placeholders are never passed to the test.
+	_, err = schema.Test(ctx, "Device", "connect", "", redacted, nil)
+	require.NoError(t, err)
+	password, _ = conf.Options(tested).GetString("Password")
+	assert.Empty(t, password)
+
+	_, err = schema.Test(ctx, "Device", "connect", "unknown", redacted, nil)
+	assert.ErrorIs(t, err, runtime.ErrCfgSectionNotFound)
+}
+
+func TestImportSecrets(t *testing.T) {
+	ctx := context.Background()
+
+	source := newSecretSchema(t)
+	id, err := source.Create(ctx, "Device", []conf.Option{
+		{Name: "Name", Value: "door"},
+		{Name: "Password", Value: "s3cr3t"},
+	})
+	require.NoError(t, err)
+
+	bundle, err := source.Export(ctx, "Device")
+	require.NoError(t, err)
+	require.Len(t, bundle.Sections, 1)
+
+	// the source keeps the stored secret.
+	results := source.Import(ctx, bundle, false)
+	require.Len(t, results, 1)
+	assert.Equal(t, runtime.ImportActionUnchanged, results[0].Action)
+	assert.Empty(t, results[0].Warning)
+
+	// other targets report the secret that must be set again.
+	target := newSecretSchema(t)
+	results = target.Import(ctx, bundle, false)
+	require.Len(t, results, 1)
+	assert.Empty(t, results[0].Error)
+	assert.Equal(t, runtime.ImportActionCreate, results[0].Action)
+	assert.Contains(t, results[0].Warning, "Password")
+
+	sec, err := target.GetID(ctx, id)
+	require.NoError(t, err)
+	password, _ := conf.Options(sec.Options).GetString("Password")
+	assert.Empty(t, password)
+}
+
+func TestRevisionSecrets(t *testing.T) {
+	ctx := context.Background()
+
+	schema := newSecretSchema(t)
+	schema.SetRevisionStore(new(memoryRevisions))
+
+	id, err := schema.Create(ctx, "Device", []conf.Option{
+		{Name: "Name", Value: "door"},
+		{Name: "Password", Value: "s3cr3t"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, schema.Update(ctx, id, "Device", []conf.Option{
+		{Name: "Name", Value: "door"},
+		{Name: "Password", Value: "hunter2"},
+	}))
+
+	for _, to := range []int{2, 0} {
+		diff, err := schema.DiffRevisions(ctx, id, 1, to)
+		require.NoError(t, err)
+		assert.Empty(t, diff, "to=%d", to)
+	}
+
+	require.NoError(t, schema.Update(ctx, id, "Device", []conf.Option{
+		{Name: "Name", Value: "front door"},
+		{Name: "Password", Value: runtime.SecretPlaceholder},
+	}))
+
+	// the current configuration is redacted as well.
+	diff, err := schema.DiffRevisions(ctx, id, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []runtime.OptionDiff{
+		{Name: "Name", Before: []string{"door"}, After: []string{"front door"}},
+	}, diff)
+}
@@ -0,0 +1,483 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ppacher/system-conf/conf"
+)
+
+// ErrSpecMismatch is returned if a configuration spec does not match the
+// struct used to decode configuration instances.
+var ErrSpecMismatch = errors.New("config: spec does not match struct")
+
+// TypedSection is a configuration instance decoded into T.
+type TypedSection[T any] struct {
+	ID    string
+	Value T
+
+	// Options holds the options Value has been decoded from.
+	Options []conf.Option
+}
+
+// TypedChangeFunc is called when an instance of a TypedSchema is created,
+// updated or deleted. value is nil for deletes.
+type TypedChangeFunc[T any] func(ctx context.Context, changeType, id string, value *T) error
+
+// TypedValidateFunc is called before an instance of a TypedSchema is
+// created or updated. id is empty for new instances.
+type TypedValidateFunc[T any] func(ctx context.Context, id string, value T) error
+
+// TypedChange describes a change of an instance of a TypedSchema.
+type TypedChange[T any] struct {
+	// Type is the type of change (create, update or delete).
+	Type string
+
+	// ID is the ID of the instance. It is empty when validating new
+	// instances.
+	ID string
+
+	// Value holds the decoded instance and is nil for deletes.
+	Value *T
+
+	// Options holds the options Value has been decoded from.
+	Options []conf.Option
+}
+
+// TypedBatchFunc is called with all changes of a TypedSchema that are
+// validated or applied together. Changes that are not part of a batch
+// are passed one at a time.
+type TypedBatchFunc[T any] func(ctx context.Context, changes []TypedChange[T]) error
+
+// TypedSchema provides typed access to the instances of a configuration
+// schema registered at a ConfigSchema. T must be a struct that can be
+// decoded using conf.DecodeSections.
+type TypedSchema[T any] struct {
+	schema *ConfigSchema
+	reg    Schema
+}
+
+// RegisterTyped registers reg at schema and returns a TypedSchema for it.
+// If reg.Spec is nil the spec is derived from the struct tags of T using
+// SpecFor. Otherwise reg.Spec is verified against T using VerifySpec.
+func RegisterTyped[T any](schema *ConfigSchema, reg Schema) (*TypedSchema[T], error) {
+	if reg.Spec == nil {
+		spec, err := SpecFor[T]()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", reg.Name, err)
+		}
+
+		reg.Spec = spec
+	} else if err := VerifySpec[T](reg.Spec); err != nil {
+		return nil, fmt.Errorf("%s: %w", reg.Name, err)
+	}
+
+	if err := schema.Register(reg); err != nil {
+		return nil, err
+	}
+
+	return Typed[T](schema, reg.Name)
+}
+
+// Typed returns a TypedSchema for the configuration schema name that has
+// already been registered at schema. The spec of the schema is verified
+// against T.
+func Typed[T any](schema *ConfigSchema, name string) (*TypedSchema[T], error) {
+	reg, err := schema.SchemaByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if err := VerifySpec[T](reg.Spec); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return &TypedSchema[T]{
+		schema: schema,
+		reg:    reg,
+	}, nil
+}
+
+// Name returns the name of the configuration schema.
+func (ts *TypedSchema[T]) Name() string {
+	return ts.reg.Name
+}
+
+// Spec returns the option spec of the configuration schema.
+func (ts *TypedSchema[T]) Spec() conf.OptionRegistry {
+	return ts.reg.Spec
+}
+
+// Decode decodes opts into a new T.
+func (ts *TypedSchema[T]) Decode(opts []conf.Option) (T, error) {
+	return DecodeOptions[T](ts.reg.Spec, opts)
+}
+
+// All returns all instances of the schema.
+func (ts *TypedSchema[T]) All(ctx context.Context) ([]TypedSection[T], error) {
+	all, err := ts.schema.All(ctx, ts.reg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TypedSection[T], len(all))
+	for idx, sec := range all {
+		value, err := ts.Decode(sec.Options)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", ts.reg.Name, sec.ID, err)
+		}
+
+		result[idx] = TypedSection[T]{
+			ID:      sec.ID,
+			Value:   value,
+			Options: sec.Options,
+		}
+	}
+
+	return result, nil
+}
+
+// Get returns the instance id. ErrCfgSectionNotFound is returned if id
+// belongs to a different schema.
+func (ts *TypedSchema[T]) Get(ctx context.Context, id string) (TypedSection[T], error) {
+	sec, err := ts.schema.GetID(ctx, id)
+	if err != nil {
+		return TypedSection[T]{}, err
+	}
+
+	if !strings.EqualFold(sec.Name, ts.reg.Name) {
+		return TypedSection[T]{}, ErrCfgSectionNotFound
+	}
+
+	value, err := ts.Decode(sec.Options)
+	if err != nil {
+		return TypedSection[T]{}, fmt.Errorf("%s %s: %w", ts.reg.Name, id, err)
+	}
+
+	return TypedSection[T]{
+		ID:      id,
+		Value:   value,
+		Options: sec.Options,
+	}, nil
+}
+
+// OnChange registers fn to be called whenever an instance of the schema
+// is created, updated or deleted.
+func (ts *TypedSchema[T]) OnChange(fn TypedChangeFunc[T]) {
+	ts.schema.AddNotifier(typedListener[T]{ts: ts, fn: fn}, ts.reg.Name)
+}
+
+// AddValidator registers fn to validate instances of the schema before
+// they are created or updated.
+func (ts *TypedSchema[T]) AddValidator(fn TypedValidateFunc[T]) {
+	ts.schema.AddValidator(typedValidator[T]{ts: ts, fn: fn}, ts.reg.Name)
+}
+
+// OnBatchChange is like OnChange but fn is called once with all changes
+// of a batch. See BatchChangeListener.
+func (ts *TypedSchema[T]) OnBatchChange(fn TypedBatchFunc[T]) {
+	ts.schema.AddNotifier(typedBatch[T]{ts: ts, fn: fn}, ts.reg.Name)
+}
+
+// AddBatchValidator is like AddValidator but fn is called once with all
+// changes of a batch, including deletes. See BatchValidator.
+func (ts *TypedSchema[T]) AddBatchValidator(fn TypedBatchFunc[T]) {
+	ts.schema.AddValidator(typedBatch[T]{ts: ts, fn: fn}, ts.reg.Name)
+}
+
+// change decodes opts into a TypedChange. opts are ignored for deletes.
+func (ts *TypedSchema[T]) change(changeType, id string, opts []conf.Option) (TypedChange[T], error) {
+	change := TypedChange[T]{
+		Type: changeType,
+		ID:   id,
+	}
+
+	if changeType == ChangeTypeDelete {
+		return change, nil
+	}
+
+	value, err := ts.Decode(opts)
+	if err != nil {
+		return change, err
+	}
+
+	change.Value = &value
+	change.Options = opts
+
+	return change, nil
+}
+
+type typedListener[T any] struct {
+	ts *TypedSchema[T]
+	fn TypedChangeFunc[T]
+}
+
+func (l typedListener[T]) NotifyChange(ctx context.Context, changeType, id string, sec *conf.Section) error {
+	if changeType == ChangeTypeDelete || sec == nil {
+		return l.fn(ctx, changeType, id, nil)
+	}
+
+	value, err := l.ts.Decode(sec.Options)
+	if err != nil {
+		return err
+	}
+
+	return l.fn(ctx, changeType, id, &value)
+}
+
+type typedValidator[T any] struct {
+	ts *TypedSchema[T]
+	fn TypedValidateFunc[T]
+}
+
+func (v typedValidator[T]) Validate(ctx context.Context, sec Section) error {
+	value, err := v.ts.Decode(sec.Options)
+	if err != nil {
+		return err
+	}
+
+	return v.fn(ctx, sec.ID, value)
+}
+
+// typedBatch implements Validator, ChangeListener and their batch
+// variants for a TypedBatchFunc.
+type typedBatch[T any] struct {
+	ts *TypedSchema[T]
+	fn TypedBatchFunc[T]
+}
+
+func (b typedBatch[T]) Validate(ctx context.Context, sec Section) error {
+	changeType := ChangeTypeUpdate
+	if sec.ID == "" {
+		changeType = ChangeTypeCreate
+	}
+
+	change, err := b.ts.change(changeType, sec.ID, sec.Options)
+	if err != nil {
+		return err
+	}
+
+	return b.fn(ctx, []TypedChange[T]{change})
+}
+
+func (b typedBatch[T]) NotifyChange(ctx context.Context, changeType, id string, sec *conf.Section) error {
+	var opts []conf.Option
+	if sec != nil {
+		opts = sec.Options
+	} else {
+		changeType = ChangeTypeDelete
+	}
+
+	change, err := b.ts.change(changeType, id, opts)
+	if err != nil {
+		return err
+	}
+
+	return b.fn(ctx, []TypedChange[T]{change})
+}
+
+func (b typedBatch[T]) ValidateBatch(ctx context.Context, changes []ConfigChange) error {
+	return b.batch(ctx, changes)
+}
+
+func (b typedBatch[T]) NotifyBatch(ctx context.Context, changes []ConfigChange) error {
+	return b.batch(ctx, changes)
+}
+
+func (b typedBatch[T]) batch(ctx context.Context, changes []ConfigChange) error {
+	typed := make([]TypedChange[T], len(changes))
+
+	for idx, change := range changes {
+		var err error
+		typed[idx], err = b.ts.change(change.Type, change.Section.ID, change.Section.Options)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", b.ts.reg.Name, change.Section.ID, err)
+		}
+	}
+
+	return b.fn(ctx, typed)
+}
+
+// DecodeOptions decodes opts into a new T using spec.
+func DecodeOptions[T any](spec conf.OptionRegistry, opts []conf.Option) (T, error) {
+	var value T
+
+	err := conf.DecodeSections(conf.Sections{{Options: opts}}, spec, &value)
+
+	return value, err
+}
+
+// SpecFor derives the option spec from the exported fields of the struct
+// T. The option name defaults to the field name and may be changed using
+// the `option` tag. Fields tagged with `option:"-"` are skipped. The
+// `description`, `default` and `required:"true"` tags are copied to the
+// respective fields of conf.OptionSpec. Annotations are not supported.
+func SpecFor[T any]() (conf.SectionSpec, error) {
+	var spec conf.SectionSpec
+
+	err := walkFields(reflect.TypeOf((*T)(nil)).Elem(), func(name string, field reflect.StructField) error {
+		optType, ok := optionTypeFor(field.Type)
+		if !ok {
+			return fmt.Errorf("field %s: unsupported type %s", field.Name, field.Type)
+		}
+
+		required, _ := strconv.ParseBool(field.Tag.Get("required"))
+
+		spec = append(spec, conf.OptionSpec{
+			Name:        name,
+			Description: field.Tag.Get("description"),
+			Default:     field.Tag.Get("default"),
+			Required:    required,
+			Type:        optType,
+		})
+
+		return nil
+	})
+
+	return spec, err
+}
+
+// VerifySpec ensures that each option of spec is decoded into a field of
+// the struct T using a compatible type and that each field of T has a
+// matching option.
+func VerifySpec[T any](spec conf.OptionRegistry) error {
+	var (
+		errs   []error
+		fields = make(map[string]struct{})
+	)
+
+	err := walkFields(reflect.TypeOf((*T)(nil)).Elem(), func(name string, field reflect.StructField) error {
+		fields[strings.ToLower(name)] = struct{}{}
+
+		opt, ok := spec.GetOption(strings.ToLower(name))
+		if !ok {
+			errs = append(errs, fmt.Errorf("field %s: no option %s", field.Name, name))
+
+			return nil
+		}
+
+		// interface fields accept any option type.
+		if field.Type.Kind() == reflect.Interface {
+			return nil
+		}
+
+		if optType, ok := optionTypeFor(field.Type); !ok || optType != opt.Type {
+			errs = append(errs, fmt.Errorf("field %s: type %s does not match option type %s", field.Name, field.Type, opt.Type))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, opt := range spec.All() {
+		if _, ok := fields[strings.ToLower(opt.Name)]; !ok {
+			errs = append(errs, fmt.Errorf("option %s: no matching field", opt.Name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %s", ErrSpecMismatch, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// walkFields calls fn for each exported field of the struct t that is
+// decoded by conf.DecodeSections. Anonymous struct fields are walked
+// recursively.
+func walkFields(t reflect.Type, fn func(name string, field reflect.StructField) error) error {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("expected a struct but got %s", t)
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if !unicode.IsUpper([]rune(field.Name)[0]) {
+			continue
+		}
+
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				if err := walkFields(ft, fn); err != nil {
+					return err
+				}
+
+				continue
+			}
+		}
+
+		name := field.Name
+		if tag := field.Tag.Get("option"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+
+			name = tag
+		}
+
+		if err := fn(name, field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// optionTypeFor returns the option type used to decode into t.
+func optionTypeFor(t reflect.Type) (conf.OptionType, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Slice {
+		switch elem, _ := optionTypeFor(t.Elem()); elem {
+		case conf.StringType:
+			return conf.StringSliceType, true
+		case conf.IntType:
+			return conf.IntSliceType, true
+		case conf.FloatType:
+			return conf.FloatSliceType, true
+		case conf.DurationType:
+			return conf.DurationSliceType, true
+		}
+
+		return nil, false
+	}
+
+	if t == durationType {
+		return conf.DurationType, true
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return conf.StringType, true
+	case reflect.Bool:
+		return conf.BoolType, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return conf.IntType, true
+	case reflect.Float32, reflect.Float64:
+		return conf.FloatType, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,149 @@
+package runtime_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/dirprovider"
+)
+
+type typedConfig struct {
+	Name     string        `required:"true" description:"The name"`
+	Interval time.Duration `default:"1m"`
+	Tags     []string      `option:"Tag"`
+}
+
+func TestTypedSchema(t *testing.T) {
+	ctx := context.Background()
+
+	provider, err := dirprovider.New(t.TempDir())
+	require.NoError(t, err)
+
+	schema := new(runtime.ConfigSchema)
+	schema.SetProvider(provider)
+
+	typed, err := runtime.RegisterTyped[typedConfig](schema, runtime.Schema{
+		Name:  "Typed",
+		Multi: true,
+	})
+	require.NoError(t, err)
+
+	opt, ok := typed.Spec().GetOption("interval")
+	require.True(t, ok)
+	assert.Equal(t, conf.DurationType, opt.Type)
+	assert.Equal(t, "1m", opt.Default)
+
+	var changes []*typedConfig
+	typed.OnChange(func(_ context.Context, _, _ string, value *typedConfig) error {
+		changes = append(changes, value)
+
+		return nil
+	})
+	typed.AddValidator(func(_ context.Context, _ string, value typedConfig) error {
+		if value.Name == "invalid" {
+			return errors.New("invalid name")
+		}
+
+		return nil
+	})
+
+	_, err = schema.Create(ctx, "Typed", []conf.Option{{Name: "Name", Value: "invalid"}})
+	assert.Error(t, err)
+
+	id, err := schema.Create(ctx, "Typed", []conf.Option{
+		{Name: "Name", Value: "test"},
+		{Name: "Interval", Value: "5m"},
+		{Name: "Tag", Value: "a"},
+		{Name: "Tag", Value: "b"},
+	})
+	require.NoError(t, err)
+
+	instance, err := typed.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, typedConfig{Name: "test", Interval: 5 * time.Minute, Tags: []string{"a", "b"}}, instance.Value)
+
+	all, err := typed.All(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	require.NoError(t, schema.Delete(ctx, id))
+	require.Len(t, changes, 2)
+	assert.Equal(t, "test", changes[0].Name)
+	assert.Nil(t, changes[1])
+
+	// specs that drifted from the struct are rejected.
+	err = runtime.VerifySpec[typedConfig](conf.SectionSpec{
+		{Name: "Name", Type: conf.StringType},
+		{Name: "Interval", Type: conf.IntType},
+		{Name: "Unknown", Type: conf.StringType},
+	})
+	assert.ErrorIs(t, err, runtime.ErrSpecMismatch)
+	assert.ErrorContains(t, err, "Interval")
+	assert.ErrorContains(t, err, "Tag")
+	assert.ErrorContains(t, err, "Unknown")
+}
+
+func TestTypedSchemaBatch(t *testing.T) {
+	ctx := context.Background()
+
+	provider, err := dirprovider.New(t.TempDir())
+	require.NoError(t, err)
+
+	schema := new(runtime.ConfigSchema)
+	schema.SetProvider(provider)
+
+	typed, err := runtime.RegisterTyped[typedConfig](schema, runtime.Schema{
+		Name:  "Typed",
+		Multi: true,
+	})
+	require.NoError(t, err)
+
+	var (
+		validated [][]runtime.TypedChange[typedConfig]
+		notified  [][]runtime.TypedChange[typedConfig]
+	)
+	typed.AddBatchValidator(func(_ context.Context, changes []runtime.TypedChange[typedConfig]) error {
+		validated = append(validated, changes)
+
+		return nil
+	})
+	typed.OnBatchChange(func(_ context.Context, changes []runtime.TypedChange[typedConfig]) error {
+		notified = append(notified, changes)
+
+		return nil
+	})
+
+	// single changes are passed one at a time.
+	id, err := schema.Create(ctx, "Typed", []conf.Option{{Name: "Name", Value: "a"}})
+	require.NoError(t, err)
+
+	require.Len(t, validated, 1)
+	assert.Equal(t, runtime.ChangeTypeCreate, validated[0][0].Type)
+	assert.Empty(t, validated[0][0].ID)
+	require.Len(t, notified, 1)
+	assert.Equal(t, id, notified[0][0].ID)
+	assert.Equal(t, "a", notified[0][0].Value.Name)
+	assert.Equal(t, []conf.Option{{Name: "Name", Value: "a"}}, notified[0][0].Options)
+
+	// batches are passed at once, including deletes.
+	_, err = schema.Batch(ctx, []runtime.ConfigChange{
+		{Type: runtime.ChangeTypeDelete, Section: runtime.Section{ID: id, Section: conf.Section{Name: "Typed"}}},
+		{Type: runtime.ChangeTypeCreate, Section: runtime.Section{Section: conf.Section{Name: "Typed", Options: conf.Options{{Name: "Name", Value: "b"}}}}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, validated, 2)
+	assert.Len(t, validated[1], 2)
+	require.Len(t, notified, 2)
+	require.Len(t, notified[1], 2)
+	assert.Equal(t, runtime.ChangeTypeDelete, notified[1][0].Type)
+	assert.Nil(t, notified[1][0].Value)
+	assert.Equal(t, "b", notified[1][1].Value.Name)
+	assert.NotEmpty(t, notified[1][1].ID)
+}
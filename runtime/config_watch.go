@@ -0,0 +1,214 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/pkg/pkglog"
+)
+
+var log = pkglog.New("runtime")
+
+// externalChangeContextKey marks the context passed to change listeners
+// for changes that have not been made through the ConfigSchema.
+type externalChangeContextKey struct{}
+
+// IsExternalChange reports whether ctx belongs to the notification of a
+// change that has been made outside of the ConfigSchema, e.g. by another
+// cisd instance. Listeners may use it to skip work that has already been
+// done by the instance that made the change.
+func IsExternalChange(ctx context.Context) bool {
+	external, _ := ctx.Value(externalChangeContextKey{}).(bool)
+
+	return external
+}
+
+// knownSection is the last known state of a configuration section
+// and used to detect changes made outside of ConfigSchema.
+type knownSection struct {
+	name    string
+	options []conf.Option
+}
+
+// Watch starts watching the configuration provider for changes that have
+// not been made through schema, like changes by other cisd instances or
+// edits of the underlying storage, and notifies all change listeners
+// about them. Changes are processed in the background until ctx is
+// cancelled. Watch returns ErrWatchNotSupported if the provider does
+// not implement WatchableConfigProvider.
+func (schema *ConfigSchema) Watch(ctx context.Context) error {
+	schema.providerLock.RLock()
+	provider := schema.provider
+	schema.providerLock.RUnlock()
+
+	if provider == nil {
+		return ErrNoProvider
+	}
+
+	watchable, ok := provider.(WatchableConfigProvider)
+	if !ok {
+		return ErrWatchNotSupported
+	}
+
+	// start watching before loading the current configuration so we
+	// don't miss changes in between. Changes that are already part
+	// of the loaded configuration are ignored later on.
+	changes, err := watchable.Watch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to watch configuration: %w", err)
+	}
+
+	schema.rw.RLock()
+	names := make([]string, 0, len(schema.entries))
+	for name := range schema.entries {
+		names = append(names, name)
+	}
+	schema.rw.RUnlock()
+
+	known := make(map[string]knownSection)
+	for _, name := range names {
+		sections, err := provider.Get(ctx, name)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", name, err)
+		}
+
+		for _, sec := range sections {
+			known[sec.ID] = knownSection{
+				name:    name,
+				options: sec.Options,
+			}
+		}
+	}
+
+	schema.watchLock.Lock()
+	schema.known = known
+	schema.watchLock.Unlock()
+
+	go func() {
+		for change := range changes {
+			schema.applyExternalChange(ctx, change)
+		}
+
+		schema.watchLock.Lock()
+		schema.known = nil
+		schema.watchLock.Unlock()
+	}()
+
+	return nil
+}
+
+// trackChange records a change made through schema so it is not reported
+// a second time by the watching provider. It must be called with
+// schema.watchLock held.
+func (schema *ConfigSchema) trackChange(changeType, id string, sec *conf.Section) {
+	if schema.known == nil {
+		return
+	}
+
+	if changeType == ChangeTypeDelete || sec == nil {
+		delete(schema.known, id)
+
+		return
+	}
+
+	schema.known[id] = knownSection{
+		name:    strings.ToLower(sec.Name),
+		options: sec.Options,
+	}
+}
+
+// applyExternalChange notifies all change listeners about change unless
+// it is already known.
+func (schema *ConfigSchema) applyExternalChange(ctx context.Context, change ConfigChange) {
+	id := change.Section.ID
+
+	var (
+		changeType string
+		name       string
+		sec        *conf.Section
+	)
+
+	schema.watchLock.Lock()
+
+	if schema.known == nil {
+		schema.watchLock.Unlock()
+
+		return
+	}
+
+	prev, exists := schema.known[id]
+
+	if change.Type == ChangeTypeDelete {
+		if !exists {
+			schema.watchLock.Unlock()
+
+			return
+		}
+
+		delete(schema.known, id)
+		changeType = ChangeTypeDelete
+		name = prev.name
+	} else {
+		name = strings.ToLower(change.Section.Name)
+
+		if exists && prev.name == name && EqualOptions(prev.options, change.Section.Options) {
+			schema.watchLock.Unlock()
+
+			return
+		}
+
+		changeType = ChangeTypeCreate
+		if exists {
+			changeType = ChangeTypeUpdate
+		}
+
+		schema.known[id] = knownSection{
+			name:    name,
+			options: change.Section.Options,
+		}
+
+		section := change.Section.Section
+		sec = &section
+	}
+
+	schema.watchLock.Unlock()
+
+	schema.rw.RLock()
+	defer schema.rw.RUnlock()
+
+	if _, ok := schema.entries[name]; !ok {
+		return
+	}
+
+	log.From(ctx).Infof("configuration %s %s changed externally (%s)", name, id, changeType)
+
+	ctx = context.WithValue(ctx, externalChangeContextKey{}, true)
+
+	if err := schema.notifyChangeListeners(ctx, changeType, id, name, sec); err != nil {
+		log.From(ctx).Errorf("failed to apply external change of %s %s: %s", name, id, err)
+	}
+}
+
+// EqualOptions reports whether a and b hold the same options in the same
+// order. Option names are compared case-insensitively and surrounding
+// whitespace of values is ignored as most providers trim values when
+// storing them.
+func EqualOptions(a, b []conf.Option) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for idx := range a {
+		if !strings.EqualFold(a[idx].Name, b[idx].Name) {
+			return false
+		}
+
+		if strings.TrimSpace(a[idx].Value) != strings.TrimSpace(b[idx].Value) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,133 @@
+package dirprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+// batchStep describes the files involved in a single change of a batch.
+type batchStep struct {
+	path   string
+	staged string
+	backup string
+}
+
+// ApplyBatch implements runtime.BatchConfigProvider. All new files are
+// written before any existing file is replaced. Replaced and deleted files
+// are kept as hard-linked backups until the batch is complete so they can
+// be restored if a later step fails.
+func (pr *DirProvider) ApplyBatch(ctx context.Context, changes []runtime.ConfigChange) error {
+	return pr.withLock(true, func() error {
+		steps := make([]batchStep, len(changes))
+		ids := make([]string, len(changes))
+
+		defer func() {
+			for _, step := range steps {
+				if step.staged != "" {
+					os.Remove(step.staged)
+				}
+				if step.backup != "" {
+					os.Remove(step.backup)
+				}
+			}
+		}()
+
+		for idx, change := range changes {
+			id := change.Section.ID
+
+			if change.Type == runtime.ChangeTypeCreate {
+				var err error
+				if id == "" {
+					if id, err = newID(); err != nil {
+						return err
+					}
+				} else if _, err = pr.read(id); err == nil {
+					return fmt.Errorf("%w: %q is already in use", runtime.ErrInvalidID, id)
+				} else if !errors.Is(err, runtime.ErrCfgSectionNotFound) {
+					return err
+				}
+			} else {
+				existing, err := pr.read(id)
+				if err != nil {
+					return err
+				}
+
+				if change.Type == runtime.ChangeTypeUpdate && !strings.EqualFold(existing.Name, change.Section.Name) {
+					return runtime.ErrCfgSectionNotFound
+				}
+			}
+
+			path, err := pr.path(id)
+			if err != nil {
+				return err
+			}
+
+			ids[idx] = id
+			steps[idx].path = path
+
+			if change.Type != runtime.ChangeTypeDelete {
+				if steps[idx].staged, err = pr.stage(id, change.Section.Section); err != nil {
+					return err
+				}
+			}
+
+			if change.Type != runtime.ChangeTypeCreate {
+				backup := filepath.Join(pr.dir, "."+id+".bak")
+
+				_ = os.Remove(backup)
+				if err := os.Link(path, backup); err != nil {
+					return err
+				}
+
+				steps[idx].backup = backup
+			}
+		}
+
+		for idx, step := range steps {
+			var err error
+			if changes[idx].Type == runtime.ChangeTypeDelete {
+				err = os.Remove(step.path)
+			} else {
+				err = os.Rename(step.staged, step.path)
+			}
+
+			if err != nil {
+				pr.restore(ctx, steps[:idx])
+
+				return err
+			}
+		}
+
+		for idx := range changes {
+			changes[idx].Section.ID = ids[idx]
+		}
+
+		return syncDir(pr.dir)
+	})
+}
+
+// restore reverts already applied steps of a failed batch.
+func (pr *DirProvider) restore(ctx context.Context, steps []batchStep) {
+	for idx := len(steps) - 1; idx >= 0; idx-- {
+		step := steps[idx]
+
+		var err error
+		if step.backup != "" {
+			err = os.Rename(step.backup, step.path)
+		} else {
+			err = os.Remove(step.path)
+		}
+
+		if err != nil {
+			log.From(ctx).Errorf("failed to restore %s: %s", step.path, err)
+		}
+	}
+}
+
+var _ runtime.BatchConfigProvider = (*DirProvider)(nil)
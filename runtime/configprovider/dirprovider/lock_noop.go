@@ -0,0 +1,16 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package dirprovider
+
+import "os"
+
+// lockFile is a no-op on platforms without flock(2). Concurrent writes
+// from multiple processes are not supported there.
+func lockFile(f *os.File, exclusive bool) error {
+	return nil
+}
+
+// unlockFile is a no-op on platforms without flock(2).
+func unlockFile(f *os.File) error {
+	return nil
+}
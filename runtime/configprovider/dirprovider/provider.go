@@ -0,0 +1,393 @@
+// Package dirprovider implements a writable runtime.ConfigProvider that
+// stores each configuration section in its own file inside a directory.
+package dirprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
+	"github.com/tierklinik-dobersberg/cis/pkg/pkglog"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+var log = pkglog.New("dirprovider")
+
+const (
+	// fileSuffix is the suffix of all configuration files.
+	fileSuffix = ".conf"
+
+	// lockFileName is the name of the file used to serialize access
+	// from multiple processes.
+	lockFileName = ".lock"
+)
+
+// Common errors returned by DirProvider.
+var (
+	ErrInvalidID      = errors.New("dirprovider: invalid section id")
+	ErrMultiLineValue = errors.New("dirprovider: multi-line values are not supported")
+)
+
+// DirProvider is a runtime.ConfigProvider that stores each section as
+// <id>.conf in a directory. IDs are generated when a section is created
+// and do not change afterwards. Files are replaced atomically and all
+// access is serialized using an advisory lock so multiple processes may
+// share the same directory.
+type DirProvider struct {
+	dir string
+
+	// l serializes access within the current process as flock(2)
+	// locks are per open file description.
+	l sync.RWMutex
+
+	migrations runtime.Migrator
+}
+
+// New returns a new provider that stores configuration sections in dir.
+// dir is created if it does not exist.
+func New(dir string) (*DirProvider, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	return &DirProvider{dir: dir}, nil
+}
+
+// SetMigrations configures the provider to apply the option migrations of
+// regs to all sections when they are read. Files do not record a schema
+// version so sections run through all migrations of their schema.
+// Migrated sections are written back on the next update.
+func (pr *DirProvider) SetMigrations(regs ...runtime.Schema) {
+	pr.l.Lock()
+	defer pr.l.Unlock()
+
+	pr.migrations = runtime.NewMigrator(regs...)
+}
+
+// Create stores sec in a new file and returns the generated ID.
+func (pr *DirProvider) Create(ctx context.Context, sec conf.Section) (string, error) {
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+
+	err = pr.withLock(true, func() error {
+		return pr.write(id, sec)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// CreateWithID implements runtime.IDConfigProvider. IDs must not contain
+// path separators or dots.
+func (pr *DirProvider) CreateWithID(ctx context.Context, id string, sec conf.Section) error {
+	if _, err := pr.path(id); err != nil {
+		return fmt.Errorf("%w: %w", runtime.ErrInvalidID, err)
+	}
+
+	return pr.withLock(true, func() error {
+		_, err := pr.read(id)
+		switch {
+		case err == nil:
+			return fmt.Errorf("%w: %q is already in use", runtime.ErrInvalidID, id)
+		case !errors.Is(err, runtime.ErrCfgSectionNotFound):
+			return err
+		}
+
+		return pr.write(id, sec)
+	})
+}
+
+// Update replaces the options of the section id.
+func (pr *DirProvider) Update(ctx context.Context, id, secType string, opts []conf.Option) error {
+	return pr.withLock(true, func() error {
+		existing, err := pr.read(id)
+		if err != nil {
+			return err
+		}
+
+		if !strings.EqualFold(existing.Name, secType) {
+			return runtime.ErrCfgSectionNotFound
+		}
+
+		return pr.write(id, conf.Section{
+			Name:    existing.Name,
+			Options: opts,
+		})
+	})
+}
+
+// Delete removes the section id.
+func (pr *DirProvider) Delete(ctx context.Context, id string) error {
+	return pr.withLock(true, func() error {
+		path, err := pr.path(id)
+		if err != nil {
+			return err
+		}
+
+		if err := os.Remove(path); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return runtime.ErrCfgSectionNotFound
+			}
+
+			return err
+		}
+
+		return syncDir(pr.dir)
+	})
+}
+
+// Get returns all sections of sectionType sorted by ID.
+func (pr *DirProvider) Get(ctx context.Context, sectionType string) ([]runtime.Section, error) {
+	var result []runtime.Section
+
+	err := pr.withLock(false, func() error {
+		all, err := pr.readAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		result = make([]runtime.Section, 0, len(all))
+		for _, sec := range all {
+			if strings.EqualFold(sec.Name, sectionType) {
+				result = append(result, sec)
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// GetID returns the section id.
+func (pr *DirProvider) GetID(ctx context.Context, id string) (runtime.Section, error) {
+	var result runtime.Section
+
+	err := pr.withLock(false, func() error {
+		sec, err := pr.read(id)
+		if err != nil {
+			return err
+		}
+
+		result = runtime.Section{
+			ID:      id,
+			Section: sec,
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// withLock calls fn while holding the process and file lock.
+func (pr *DirProvider) withLock(exclusive bool, fn func() error) error {
+	if exclusive {
+		pr.l.Lock()
+		defer pr.l.Unlock()
+	} else {
+		pr.l.RLock()
+		defer pr.l.RUnlock()
+	}
+
+	f, err := os.OpenFile(filepath.Join(pr.dir, lockFileName), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f, exclusive); err != nil {
+		return fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	defer unlockFile(f) //nolint:errcheck
+
+	return fn()
+}
+
+// path returns the file path for id.
+func (pr *DirProvider) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+
+	return filepath.Join(pr.dir, id+fileSuffix), nil
+}
+
+// read reads the section stored in the file for id.
+func (pr *DirProvider) read(id string) (conf.Section, error) {
+	path, err := pr.path(id)
+	if err != nil {
+		return conf.Section{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return conf.Section{}, runtime.ErrCfgSectionNotFound
+		}
+
+		return conf.Section{}, err
+	}
+	defer f.Close()
+
+	file, err := conf.Deserialize(path, f)
+	if err != nil {
+		return conf.Section{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if len(file.Sections) != 1 {
+		return conf.Section{}, fmt.Errorf("%s: expected exactly one section but found %d", path, len(file.Sections))
+	}
+
+	sec := file.Sections[0]
+	if sec.Options, err = pr.migrations.Migrate(sec.Name, sec.Options); err != nil {
+		return conf.Section{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return sec, nil
+}
+
+// readAll reads all sections from the directory. Invalid files are
+// logged and skipped.
+func (pr *DirProvider) readAll(ctx context.Context) ([]runtime.Section, error) {
+	matches, err := filepath.Glob(filepath.Join(pr.dir, "*"+fileSuffix))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(matches)
+
+	result := make([]runtime.Section, 0, len(matches))
+	for _, path := range matches {
+		id := strings.TrimSuffix(filepath.Base(path), fileSuffix)
+
+		sec, err := pr.read(id)
+		if err != nil {
+			log.From(ctx).Errorf("skipping configuration file: %s", err)
+
+			continue
+		}
+
+		result = append(result, runtime.Section{
+			ID:      id,
+			Section: sec,
+		})
+	}
+
+	return result, nil
+}
+
+// write atomically replaces the file for id with sec.
+func (pr *DirProvider) write(id string, sec conf.Section) error {
+	path, err := pr.path(id)
+	if err != nil {
+		return err
+	}
+
+	staged, err := pr.stage(id, sec)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(staged, path); err != nil {
+		os.Remove(staged)
+
+		return err
+	}
+
+	return syncDir(pr.dir)
+}
+
+// stage writes sec to a new temporary file in the directory and returns
+// its path. The caller is responsible to rename or remove the file.
+func (pr *DirProvider) stage(id string, sec conf.Section) (string, error) {
+	if _, err := pr.path(id); err != nil {
+		return "", err
+	}
+
+	// the configuration file format trims values and does not
+	// preserve line breaks.
+	opts := make([]conf.Option, len(sec.Options))
+	for idx, opt := range sec.Options {
+		if strings.ContainsAny(opt.Value, "\r\n") {
+			return "", httperr.BadRequest(fmt.Sprintf("option %s: multi-line values are not supported", opt.Name)).SetInternal(ErrMultiLineValue)
+		}
+
+		opts[idx] = conf.Option{
+			Name:  opt.Name,
+			Value: strings.TrimSpace(opt.Value),
+		}
+	}
+	sec.Options = opts
+
+	var buf bytes.Buffer
+	if err := conf.WriteSectionsTo(conf.Sections{sec}, &buf); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(pr.dir, "."+id+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tmp.Write(buf.Bytes())
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+
+		return "", err
+	}
+
+	return tmp.Name(), nil
+}
+
+// syncDir flushes the directory entry so renames and removes survive
+// a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	// not all platforms support syncing directories.
+	_ = d.Sync()
+
+	return nil
+}
+
+// newID returns a new random section ID.
+func newID() (string, error) {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b[:]), nil
+}
+
+var (
+	_ runtime.WatchableConfigProvider = (*DirProvider)(nil)
+	_ runtime.IDConfigProvider        = (*DirProvider)(nil)
+)
@@ -0,0 +1,176 @@
+package dirprovider_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/dirprovider"
+)
+
+func TestDirProvider(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	pr, err := dirprovider.New(dir)
+	require.NoError(t, err)
+
+	door, err := pr.Create(ctx, conf.Section{
+		Name: "Door",
+		Options: conf.Options{
+			{Name: "Type", Value: "noop"},
+		},
+	})
+	require.NoError(t, err)
+
+	hours, err := pr.Create(ctx, conf.Section{
+		Name: "OpeningHour",
+		Options: conf.Options{
+			{Name: "OnWeekday", Value: "Mon"},
+			{Name: "TimeRanges", Value: "08:00 - 12:00 "},
+		},
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, door, hours)
+
+	assert.FileExists(t, filepath.Join(dir, door+".conf"))
+
+	// a new provider must see the same sections with the same IDs.
+	pr, err = dirprovider.New(dir)
+	require.NoError(t, err)
+
+	sections, err := pr.Get(ctx, "openinghour")
+	require.NoError(t, err)
+	require.Len(t, sections, 1)
+	assert.Equal(t, hours, sections[0].ID)
+	assert.Equal(t, "08:00 - 12:00", sections[0].Options.GetStringSlice("TimeRanges")[0])
+
+	require.NoError(t, pr.Update(ctx, door, "door", conf.Options{
+		{Name: "Type", Value: "shelly"},
+	}))
+	assert.ErrorIs(t, pr.Update(ctx, door, "OpeningHour", nil), runtime.ErrCfgSectionNotFound)
+	assert.ErrorIs(t, pr.Update(ctx, door, "door", conf.Options{{Name: "Type", Value: "a\nb"}}), dirprovider.ErrMultiLineValue)
+
+	sec, err := pr.GetID(ctx, door)
+	require.NoError(t, err)
+	assert.Equal(t, "Door", sec.Name)
+	assert.Equal(t, []string{"shelly"}, sec.Options.GetStringSlice("Type"))
+
+	_, err = pr.GetID(ctx, "../"+door)
+	assert.ErrorIs(t, err, dirprovider.ErrInvalidID)
+
+	require.NoError(t, pr.Delete(ctx, door))
+	assert.ErrorIs(t, pr.Delete(ctx, door), runtime.ErrCfgSectionNotFound)
+
+	_, err = pr.GetID(ctx, door)
+	assert.ErrorIs(t, err, runtime.ErrCfgSectionNotFound)
+
+	// no temporary files must be left behind.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	names := make([]string, len(entries))
+	for idx, e := range entries {
+		names[idx] = e.Name()
+	}
+	assert.ElementsMatch(t, []string{".lock", hours + ".conf"}, names)
+}
+
+type recorder chan bool
+
+func (r recorder) NotifyChange(ctx context.Context, _, _ string, _ *conf.Section) error {
+	r <- runtime.IsExternalChange(ctx)
+
+	return nil
+}
+
+func TestWatchUntrimmedValues(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pr, err := dirprovider.New(t.TempDir())
+	require.NoError(t, err)
+
+	schema := new(runtime.ConfigSchema)
+	require.NoError(t, schema.Register(runtime.Schema{
+		Name:  "Test",
+		Multi: true,
+		Spec: conf.SectionSpec{
+			{Name: "Value", Type: conf.StringType},
+		},
+	}))
+	schema.SetProvider(pr)
+
+	rec := make(recorder, 10)
+	schema.AddNotifier(rec, "Test")
+
+	require.NoError(t, schema.Watch(ctx))
+
+	_, err = schema.Create(ctx, "Test", []conf.Option{{Name: "Value", Value: " padded "}})
+	require.NoError(t, err)
+	assert.False(t, <-rec)
+
+	// the provider trims the value when writing it which must not be
+	// reported as an external change.
+	select {
+	case external := <-rec:
+		t.Fatalf("unexpected change notification (external=%t)", external)
+	case <-time.After(time.Second):
+	}
+}
+
+func TestDirProviderMigrations(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	pr, err := dirprovider.New(dir)
+	require.NoError(t, err)
+
+	id, err := pr.Create(ctx, conf.Section{
+		Name: "Door",
+		Options: conf.Options{
+			{Name: "ShellyScriptURL", Value: "http://door"},
+		},
+	})
+	require.NoError(t, err)
+
+	pr.SetMigrations(runtime.Schema{
+		Name: "Door",
+		Migrations: []runtime.OptionMigration{
+			{
+				Version: 1,
+				Steps: []runtime.MigrationStep{
+					runtime.RenameOption("ShellyScriptURL", "ScriptURL"),
+					runtime.SetDefault("Type", "shelly"),
+				},
+			},
+		},
+	})
+
+	expected := conf.Options{
+		{Name: "ScriptURL", Value: "http://door"},
+		{Name: "Type", Value: "shelly"},
+	}
+
+	sec, err := pr.GetID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, expected, sec.Options)
+
+	all, err := pr.Get(ctx, "Door")
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, expected, all[0].Options)
+
+	// already migrated sections are kept as is.
+	require.NoError(t, pr.Update(ctx, id, "Door", sec.Options))
+
+	sec, err = pr.GetID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, expected, sec.Options)
+}
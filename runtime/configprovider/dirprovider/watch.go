@@ -0,0 +1,147 @@
+package dirprovider
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+// reloadDelay is used to debounce file system events.
+const reloadDelay = 200 * time.Millisecond
+
+// Watch implements runtime.WatchableConfigProvider. It reports changes
+// of all section files in the directory including changes made through
+// the provider itself.
+func (pr *DirProvider) Watch(ctx context.Context) (<-chan runtime.ConfigChange, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := watcher.Add(pr.dir); err != nil {
+		watcher.Close()
+
+		return nil, err
+	}
+
+	last, err := pr.snapshot(ctx)
+	if err != nil {
+		watcher.Close()
+
+		return nil, err
+	}
+
+	ch := make(chan runtime.ConfigChange)
+
+	go func() {
+		defer close(ch)
+		defer watcher.Close()
+
+		var reload <-chan time.Time
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				log.From(ctx).Errorf("failed to watch %s: %s", pr.dir, err)
+
+			case evt, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if strings.HasSuffix(evt.Name, fileSuffix) && !strings.HasPrefix(filepath.Base(evt.Name), ".") {
+					reload = time.After(reloadDelay)
+				}
+
+			case <-reload:
+				reload = nil
+
+				current, err := pr.snapshot(ctx)
+				if err != nil {
+					log.From(ctx).Errorf("failed to reload %s: %s", pr.dir, err)
+
+					continue
+				}
+
+				for _, change := range diff(last, current) {
+					select {
+					case ch <- change:
+					case <-ctx.Done():
+						return
+					}
+				}
+
+				last = current
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// snapshot returns all sections indexed by ID.
+func (pr *DirProvider) snapshot(ctx context.Context) (map[string]runtime.Section, error) {
+	var all []runtime.Section
+
+	err := pr.withLock(false, func() error {
+		var err error
+		all, err = pr.readAll(ctx)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]runtime.Section, len(all))
+	for _, sec := range all {
+		result[sec.ID] = sec
+	}
+
+	return result, nil
+}
+
+// diff returns the changes required to get from before to after.
+func diff(before, after map[string]runtime.Section) []runtime.ConfigChange {
+	var changes []runtime.ConfigChange
+
+	for id, sec := range after {
+		prev, ok := before[id]
+
+		switch {
+		case !ok:
+			changes = append(changes, runtime.ConfigChange{
+				Type:    runtime.ChangeTypeCreate,
+				Section: sec,
+			})
+
+		case !runtime.EqualOptions(prev.Options, sec.Options):
+			changes = append(changes, runtime.ConfigChange{
+				Type:    runtime.ChangeTypeUpdate,
+				Section: sec,
+			})
+		}
+	}
+
+	for id, sec := range before {
+		if _, ok := after[id]; !ok {
+			changes = append(changes, runtime.ConfigChange{
+				Type:    runtime.ChangeTypeDelete,
+				Section: sec,
+			})
+		}
+	}
+
+	return changes
+}
@@ -2,24 +2,56 @@ package fileprovider
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/ppacher/system-conf/conf"
 	"github.com/tierklinik-dobersberg/cis/runtime"
 )
 
+// IDOption is the name of the option that may be used to assign an
+// explicit, stable ID to a section. The option is removed from the
+// section before it is returned by the provider.
+const IDOption = "_id"
+
 type FileProvider struct {
+	// l protects File which is replaced when the file is
+	// reloaded by Watch.
+	l sync.RWMutex
+
 	File *conf.File
+
+	migrations runtime.Migrator
 }
 
 // New creates a new runtime.ConfigProvider using data stored in
 // cfgFile.
+//
+// Sections are identified by the value of their IDOption. Sections
+// without an explicit ID get an ID derived from the path of cfgFile,
+// the section type and the position of the section among all sections
+// of the same type. Those IDs are stable as long as sections of the same
+// type are not reordered.
 func New(cfgFile *conf.File) *FileProvider {
 	return &FileProvider{File: cfgFile}
 }
 
+// SetMigrations configures the provider to apply the option migrations of
+// regs to all sections when they are read. Configuration files do not
+// record a schema version so sections run through all migrations of
+// their schema.
+func (cfg *FileProvider) SetMigrations(regs ...runtime.Schema) {
+	cfg.l.Lock()
+	defer cfg.l.Unlock()
+
+	cfg.migrations = runtime.NewMigrator(regs...)
+}
+
 func (cfg *FileProvider) Create(ctx context.Context, sec conf.Section) (string, error) {
 	return "", runtime.ErrReadOnly
 }
@@ -33,17 +65,18 @@ func (cfg *FileProvider) Delete(ctx context.Context, id string) error {
 }
 
 func (cfg *FileProvider) Get(ctx context.Context, sectionType string) ([]runtime.Section, error) {
-	sections := cfg.File.GetAll(sectionType)
-	if len(sections) == 0 {
-		return []runtime.Section{}, nil
-	}
+	cfg.l.RLock()
+	defer cfg.l.RUnlock()
 
-	result := make([]runtime.Section, len(sections))
+	all, err := cfg.sections()
+	if err != nil {
+		return nil, err
+	}
 
-	for idx, sec := range sections {
-		result[idx] = runtime.Section{
-			ID:      cfg.makeKey(sectionType, idx),
-			Section: sec,
+	result := make([]runtime.Section, 0, len(all))
+	for _, sec := range all {
+		if strings.EqualFold(sec.Name, sectionType) {
+			result = append(result, sec)
 		}
 	}
 
@@ -51,36 +84,149 @@ func (cfg *FileProvider) Get(ctx context.Context, sectionType string) ([]runtime
 }
 
 func (cfg *FileProvider) GetID(ctx context.Context, instanceID string) (runtime.Section, error) {
-	secType, idx, err := cfg.parseKey(instanceID)
+	cfg.l.RLock()
+	defer cfg.l.RUnlock()
+
+	all, err := cfg.sections()
 	if err != nil {
 		return runtime.Section{}, err
 	}
 
-	sections := cfg.File.GetAll(secType)
-	if len(sections) <= idx {
+	for _, sec := range all {
+		if sec.ID == instanceID {
+			return sec, nil
+		}
+	}
+
+	// fallback to IDs in the legacy <type>-<index> format so existing
+	// references keep working.
+	secType, idx, err := parseLegacyKey(instanceID)
+	if err != nil {
 		return runtime.Section{}, runtime.ErrCfgSectionNotFound
 	}
 
-	return runtime.Section{
-		ID:      instanceID,
-		Section: sections[idx],
-	}, nil
+	for _, sec := range all {
+		if !strings.EqualFold(sec.Name, secType) {
+			continue
+		}
+
+		if idx == 0 {
+			return sec, nil
+		}
+		idx--
+	}
+
+	return runtime.Section{}, runtime.ErrCfgSectionNotFound
 }
 
-func (cfg *FileProvider) makeKey(secType string, idx int) string {
-	return fmt.Sprintf("%s-%d", secType, idx)
+// sections returns all sections of the file together with their IDs.
+// It returns an error if the same ID is assigned to more than one
+// section. It must be called with cfg.l held.
+func (cfg *FileProvider) sections() ([]runtime.Section, error) {
+	var (
+		result    = make([]runtime.Section, len(cfg.File.Sections))
+		positions = make([]int, len(cfg.File.Sections))
+		used      = make(map[string]int, len(cfg.File.Sections))
+		counts    = make(map[string]int)
+		implicit  []int
+	)
+
+	// explicit IDs are assigned first so generated IDs can avoid
+	// collisions with them.
+	for idx, sec := range cfg.File.Sections {
+		secType := strings.ToLower(sec.Name)
+		positions[idx] = counts[secType]
+		counts[secType]++
+
+		opts := make(conf.Options, 0, len(sec.Options))
+		id := ""
+		for _, opt := range sec.Options {
+			if strings.EqualFold(opt.Name, IDOption) {
+				id = strings.TrimSpace(opt.Value)
+
+				continue
+			}
+
+			opts = append(opts, opt)
+		}
+
+		opts, err := cfg.migrations.Migrate(sec.Name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: [%s]: %w", cfg.File.Path, sec.Name, err)
+		}
+
+		result[idx] = runtime.Section{
+			ID: id,
+			Section: conf.Section{
+				Name:    sec.Name,
+				Options: opts,
+			},
+		}
+
+		if id == "" {
+			implicit = append(implicit, idx)
+
+			continue
+		}
+
+		if prev, ok := used[id]; ok {
+			return nil, fmt.Errorf("%s: duplicate section id %q used by [%s] and [%s]", cfg.File.Path, id, cfg.File.Sections[prev].Name, sec.Name)
+		}
+		used[id] = idx
+	}
+
+	for _, idx := range implicit {
+		secType := strings.ToLower(result[idx].Name)
+
+		for salt := 0; ; salt++ {
+			id := cfg.makeKey(secType, positions[idx], salt)
+			if _, ok := used[id]; ok {
+				continue
+			}
+
+			used[id] = idx
+			result[idx].ID = id
+
+			break
+		}
+	}
+
+	return result, nil
 }
 
-func (cfg *FileProvider) parseKey(key string) (secType string, idx int, err error) {
-	parts := strings.Split(key, "-")
-	if len(parts) != 2 {
-		return "", 0, fmt.Errorf("section id: invalid number of segments")
+// makeKey returns the generated ID for the section at position idx
+// among all sections of secType. salt is increased to resolve
+// collisions with other IDs.
+func (cfg *FileProvider) makeKey(secType string, idx int, salt int) string {
+	path := cfg.File.Path
+	if abs, err := filepath.Abs(path); err == nil && path != "" {
+		path = abs
 	}
 
-	idx64, err := strconv.ParseInt(parts[1], 0, 0)
-	if err != nil {
-		return "", 0, fmt.Errorf("section id: invalid index number: %w", err)
+	input := fmt.Sprintf("%s\x00%s\x00%d", path, secType, idx)
+	if salt > 0 {
+		input += fmt.Sprintf("\x00%d", salt)
+	}
+
+	sum := sha256.Sum256([]byte(input))
+
+	return hex.EncodeToString(sum[:12])
+}
+
+// parseLegacyKey parses IDs in the <type>-<index> format. The type itself
+// may contain dashes.
+func parseLegacyKey(key string) (secType string, idx int, err error) {
+	pos := strings.LastIndex(key, "-")
+	if pos <= 0 {
+		return "", 0, fmt.Errorf("section id: missing index")
 	}
 
-	return parts[0], int(idx64), nil
+	idx, err = strconv.Atoi(key[pos+1:])
+	if err != nil || idx < 0 {
+		return "", 0, fmt.Errorf("section id: invalid index number %q", key[pos+1:])
+	}
+
+	return key[:pos], idx, nil
 }
+
+var _ runtime.WatchableConfigProvider = (*FileProvider)(nil)
@@ -0,0 +1,73 @@
+package fileprovider_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/fileprovider"
+)
+
+func load(t *testing.T, content string) *fileprovider.FileProvider {
+	t.Helper()
+
+	file, err := conf.Deserialize("/etc/cis/cis.conf", strings.NewReader(content))
+	require.NoError(t, err)
+
+	return fileprovider.New(file)
+}
+
+func TestFileProviderIDs(t *testing.T) {
+	ctx := context.Background()
+
+	p := load(t, `
+[Door-Schedule]
+Value=a
+
+[Door-Schedule]
+_id=explicit
+Value=b
+
+[Other]
+Value=c
+`)
+
+	sections, err := p.Get(ctx, "door-schedule")
+	require.NoError(t, err)
+	require.Len(t, sections, 2)
+
+	assert.Equal(t, "explicit", sections[1].ID)
+	assert.Len(t, sections[0].ID, 24)
+	assert.Equal(t, []string{"b"}, sections[1].Options.GetStringSlice("Value"))
+	assert.Empty(t, sections[1].Options.GetStringSlice(fileprovider.IDOption), "the ID option must be stripped")
+
+	// generated IDs do not depend on other section types or on
+	// sections with explicit IDs.
+	reordered := load(t, `
+[Other]
+Value=c
+
+[Door-Schedule]
+Value=changed
+`)
+	sec, err := reordered.GetID(ctx, sections[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"changed"}, sec.Options.GetStringSlice("Value"))
+
+	// legacy IDs with dashed type names are still resolved
+	sec, err = p.GetID(ctx, "door-schedule-1")
+	require.NoError(t, err)
+	assert.Equal(t, "explicit", sec.ID)
+
+	_, err = p.GetID(ctx, "door-schedule-2")
+	assert.ErrorIs(t, err, runtime.ErrCfgSectionNotFound)
+
+	// duplicate explicit IDs are rejected
+	dup := load(t, "[A]\n_id=x\n\n[B]\n_id=x\n")
+	_, err = dup.Get(ctx, "A")
+	assert.Error(t, err)
+}
@@ -0,0 +1,177 @@
+package fileprovider
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/pkg/pkglog"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+var log = pkglog.New("fileprovider")
+
+// reloadDelay is used to debounce file system events as editors
+// often write files in multiple steps.
+const reloadDelay = 200 * time.Millisecond
+
+// Watch implements runtime.WatchableConfigProvider. It watches the
+// directory of the configuration file and reloads the file whenever it
+// is changed. Changes are reported for all sections whose options
+// differ after the reload.
+func (cfg *FileProvider) Watch(ctx context.Context) (<-chan runtime.ConfigChange, error) {
+	cfg.l.RLock()
+	path := cfg.File.Path
+	cfg.l.RUnlock()
+
+	if path == "" {
+		return nil, fmt.Errorf("%w: file path unknown", runtime.ErrWatchNotSupported)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	// watch the directory instead of the file itself so we keep
+	// track of the file when it's replaced by a rename.
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+
+		return nil, err
+	}
+
+	ch := make(chan runtime.ConfigChange)
+
+	go func() {
+		defer close(ch)
+		defer watcher.Close()
+
+		var reload <-chan time.Time
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				log.From(ctx).Errorf("failed to watch %s: %s", path, err)
+
+			case evt, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if filepath.Clean(evt.Name) != filepath.Clean(path) {
+					continue
+				}
+
+				if evt.Has(fsnotify.Write) || evt.Has(fsnotify.Create) || evt.Has(fsnotify.Rename) || evt.Has(fsnotify.Remove) {
+					reload = time.After(reloadDelay)
+				}
+
+			case <-reload:
+				reload = nil
+
+				changes, err := cfg.reload()
+				if err != nil {
+					log.From(ctx).Errorf("failed to reload %s: %s", path, err)
+
+					continue
+				}
+
+				for _, change := range changes {
+					select {
+					case ch <- change:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// reload reads the configuration file again and returns all changed
+// sections.
+func (cfg *FileProvider) reload() ([]runtime.ConfigChange, error) {
+	cfg.l.Lock()
+	defer cfg.l.Unlock()
+
+	file, err := conf.LoadFile(cfg.File.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := cfg.sectionsByID()
+	if err != nil {
+		// the previous content was invalid so treat all sections
+		// as new.
+		before = make(map[string]runtime.Section)
+	}
+
+	prevFile := cfg.File
+	cfg.File = file
+
+	after, err := cfg.sectionsByID()
+	if err != nil {
+		cfg.File = prevFile
+
+		return nil, err
+	}
+
+	var changes []runtime.ConfigChange
+	for _, sec := range after {
+		prev, ok := before[sec.ID]
+		delete(before, sec.ID)
+
+		switch {
+		case !ok:
+			changes = append(changes, runtime.ConfigChange{
+				Type:    runtime.ChangeTypeCreate,
+				Section: sec,
+			})
+
+		case !strings.EqualFold(prev.Name, sec.Name) || !runtime.EqualOptions(prev.Options, sec.Options):
+			changes = append(changes, runtime.ConfigChange{
+				Type:    runtime.ChangeTypeUpdate,
+				Section: sec,
+			})
+		}
+	}
+
+	for _, sec := range before {
+		changes = append(changes, runtime.ConfigChange{
+			Type:    runtime.ChangeTypeDelete,
+			Section: sec,
+		})
+	}
+
+	return changes, nil
+}
+
+// sectionsByID returns all sections of the file indexed by ID. It must
+// be called with cfg.l held.
+func (cfg *FileProvider) sectionsByID() (map[string]runtime.Section, error) {
+	all, err := cfg.sections()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]runtime.Section, len(all))
+	for _, sec := range all {
+		result[sec.ID] = sec
+	}
+
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package fileprovider_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/fileprovider"
+)
+
+type change struct {
+	Type     string
+	ID       string
+	Values   []string
+	External bool
+}
+
+type recorder chan change
+
+func (r recorder) NotifyChange(ctx context.Context, changeType, id string, sec *conf.Section) error {
+	c := change{Type: changeType, ID: id, External: runtime.IsExternalChange(ctx)}
+	if sec != nil {
+		c.Values = sec.Options.GetStringSlice("Value")
+	}
+
+	r <- c
+
+	return nil
+}
+
+func (r recorder) next(t *testing.T) change {
+	t.Helper()
+
+	select {
+	case c := <-r:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for change notification")
+	}
+
+	return change{}
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "cis.conf")
+	write := func(content string) {
+		// replace the file like most editors do
+		require.NoError(t, os.WriteFile(path+".tmp", []byte(content), 0o600))
+		require.NoError(t, os.Rename(path+".tmp", path))
+	}
+
+	write("[Test]\n_id=first\nValue=a\n")
+
+	file, err := conf.LoadFile(path)
+	require.NoError(t, err)
+
+	schema := new(runtime.ConfigSchema)
+	require.NoError(t, schema.Register(runtime.Schema{
+		Name:  "Test",
+		Multi: true,
+		Spec: conf.SectionSpec{
+			{Name: "Value", Type: conf.StringType},
+		},
+	}))
+	schema.SetProvider(fileprovider.New(file))
+
+	rec := make(recorder, 10)
+	schema.AddNotifier(rec, "Test")
+
+	require.NoError(t, schema.Watch(ctx))
+
+	write("[Test]\nValue=c\n_id=second\n\n[Test]\n_id=first\nValue=b\n")
+
+	got := []change{rec.next(t), rec.next(t)}
+	assert.ElementsMatch(t, []change{
+		{Type: runtime.ChangeTypeUpdate, ID: "first", Values: []string{"b"}, External: true},
+		{Type: runtime.ChangeTypeCreate, ID: "second", Values: []string{"c"}, External: true},
+	}, got)
+
+	write("[Test]\n_id=first\nValue=b\n")
+	assert.Equal(t, change{Type: runtime.ChangeTypeDelete, ID: "second", External: true}, rec.next(t))
+
+	sections, err := schema.All(ctx, "Test")
+	require.NoError(t, err)
+	require.Len(t, sections, 1)
+	assert.Equal(t, "first", sections[0].ID)
+}
@@ -0,0 +1,251 @@
+// Package layeredprovider implements a runtime.ConfigProvider that
+// combines one or more read-only providers, like configuration files,
+// with a single writable provider.
+package layeredprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+)
+
+// LayeredProvider merges the sections of all read-only layers with the
+// sections of the writable layer. Sections from read-only layers are
+// annotated with runtime.Readonly() and cannot be updated or deleted.
+// New sections are always stored in the writable layer.
+type LayeredProvider struct {
+	writable runtime.ConfigProvider
+	readonly []runtime.ConfigProvider
+}
+
+// New returns a new layered provider that stores all changes in
+// writable. readonly is searched in order before writable.
+func New(writable runtime.ConfigProvider, readonly ...runtime.ConfigProvider) *LayeredProvider {
+	return &LayeredProvider{
+		writable: writable,
+		readonly: readonly,
+	}
+}
+
+// Create stores sec in the writable layer.
+func (lp *LayeredProvider) Create(ctx context.Context, sec conf.Section) (string, error) {
+	return lp.writable.Create(ctx, sec)
+}
+
+// CreateWithID stores sec using id in the writable layer if it supports
+// it. It returns runtime.ErrInvalidID if id belongs to a read-only layer.
+func (lp *LayeredProvider) CreateWithID(ctx context.Context, id string, sec conf.Section) error {
+	idProvider, ok := lp.writable.(runtime.IDConfigProvider)
+	if !ok {
+		return runtime.ErrIDNotSupported
+	}
+
+	if _, found, err := lp.getReadonly(ctx, id); err != nil {
+		return err
+	} else if found {
+		return fmt.Errorf("%w: %q is used in a read-only layer", runtime.ErrInvalidID, id)
+	}
+
+	return idProvider.CreateWithID(ctx, id, sec)
+}
+
+// Update updates the section id in the writable layer. It returns
+// runtime.ErrReadOnly if id belongs to a read-only layer.
+func (lp *LayeredProvider) Update(ctx context.Context, id, secType string, opts []conf.Option) error {
+	if err := lp.ensureWritable(ctx, id); err != nil {
+		return err
+	}
+
+	return lp.writable.Update(ctx, id, secType, opts)
+}
+
+// Delete deletes the section id from the writable layer. It returns
+// runtime.ErrReadOnly if id belongs to a read-only layer.
+func (lp *LayeredProvider) Delete(ctx context.Context, id string) error {
+	if err := lp.ensureWritable(ctx, id); err != nil {
+		return err
+	}
+
+	return lp.writable.Delete(ctx, id)
+}
+
+// ApplyBatch applies changes to the writable layer if it supports batches.
+// It returns runtime.ErrReadOnly if any change modifies a section of a
+// read-only layer.
+func (lp *LayeredProvider) ApplyBatch(ctx context.Context, changes []runtime.ConfigChange) error {
+	writable, ok := lp.writable.(runtime.BatchConfigProvider)
+	if !ok {
+		return runtime.ErrBatchNotSupported
+	}
+
+	for _, change := range changes {
+		if change.Type == runtime.ChangeTypeCreate {
+			continue
+		}
+
+		if err := lp.ensureWritable(ctx, change.Section.ID); err != nil {
+			return err
+		}
+	}
+
+	return writable.ApplyBatch(ctx, changes)
+}
+
+// Get returns the sections of sectionType from all read-only layers
+// followed by the sections of the writable layer.
+func (lp *LayeredProvider) Get(ctx context.Context, sectionType string) ([]runtime.Section, error) {
+	var result []runtime.Section
+
+	for _, layer := range lp.readonly {
+		sections, err := layer.Get(ctx, sectionType)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sec := range sections {
+			result = append(result, markReadonly(sec))
+		}
+	}
+
+	sections, err := lp.writable.Get(ctx, sectionType)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(result, sections...), nil
+}
+
+// GetID returns the section id from the first layer that contains it.
+func (lp *LayeredProvider) GetID(ctx context.Context, id string) (runtime.Section, error) {
+	sec, found, err := lp.getReadonly(ctx, id)
+	if err != nil {
+		return runtime.Section{}, err
+	}
+
+	if found {
+		return sec, nil
+	}
+
+	return lp.writable.GetID(ctx, id)
+}
+
+// Watch implements runtime.WatchableConfigProvider by merging the changes
+// of all layers that support watching. It returns runtime.ErrWatchNotSupported
+// if none of the layers does.
+func (lp *LayeredProvider) Watch(ctx context.Context) (<-chan runtime.ConfigChange, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var (
+		wg      sync.WaitGroup
+		started int
+		out     = make(chan runtime.ConfigChange)
+	)
+
+	layers := append([]runtime.ConfigProvider{lp.writable}, lp.readonly...)
+	for idx, layer := range layers {
+		watchable, ok := layer.(runtime.WatchableConfigProvider)
+		if !ok {
+			continue
+		}
+
+		ch, err := watchable.Watch(ctx)
+		if err != nil {
+			if errors.Is(err, runtime.ErrWatchNotSupported) {
+				continue
+			}
+
+			cancel()
+			wg.Wait()
+
+			return nil, err
+		}
+
+		readonly := idx > 0
+
+		started++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for change := range ch {
+				if readonly {
+					change.Section = markReadonly(change.Section)
+				}
+
+				select {
+				case out <- change:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	if started == 0 {
+		cancel()
+
+		return nil, runtime.ErrWatchNotSupported
+	}
+
+	go func() {
+		defer close(out)
+		defer cancel()
+
+		wg.Wait()
+	}()
+
+	return out, nil
+}
+
+// getReadonly searches all read-only layers for id.
+func (lp *LayeredProvider) getReadonly(ctx context.Context, id string) (runtime.Section, bool, error) {
+	for _, layer := range lp.readonly {
+		sec, err := layer.GetID(ctx, id)
+		if err != nil {
+			if errors.Is(err, runtime.ErrCfgSectionNotFound) {
+				continue
+			}
+
+			return runtime.Section{}, false, err
+		}
+
+		return markReadonly(sec), true, nil
+	}
+
+	return runtime.Section{}, false, nil
+}
+
+func (lp *LayeredProvider) ensureWritable(ctx context.Context, id string) error {
+	_, found, err := lp.getReadonly(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if found {
+		return fmt.Errorf("%w: section %s is defined in a read-only layer", runtime.ErrReadOnly, id)
+	}
+
+	return nil
+}
+
+// markReadonly returns a copy of sec annotated with runtime.Readonly().
+func markReadonly(sec runtime.Section) runtime.Section {
+	annotations := make(conf.Annotation, len(sec.Annotations)+1)
+	for key, value := range sec.Annotations {
+		annotations[key] = value
+	}
+
+	sec.Annotations = annotations.With(runtime.Readonly())
+
+	return sec
+}
+
+var (
+	_ runtime.WatchableConfigProvider = (*LayeredProvider)(nil)
+	_ runtime.BatchConfigProvider     = (*LayeredProvider)(nil)
+	_ runtime.IDConfigProvider        = (*LayeredProvider)(nil)
+)
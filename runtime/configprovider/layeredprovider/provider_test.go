@@ -0,0 +1,60 @@
+package layeredprovider_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/dirprovider"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/fileprovider"
+	"github.com/tierklinik-dobersberg/cis/runtime/configprovider/layeredprovider"
+)
+
+func TestLayeredProvider(t *testing.T) {
+	ctx := context.Background()
+
+	file, err := conf.Deserialize("cis.conf", strings.NewReader("[Test]\n_id=from-file\nValue=a\n"))
+	require.NoError(t, err)
+
+	writable, err := dirprovider.New(t.TempDir())
+	require.NoError(t, err)
+
+	p := layeredprovider.New(writable, fileprovider.New(file))
+
+	id, err := p.Create(ctx, conf.Section{
+		Name:    "Test",
+		Options: conf.Options{{Name: "Value", Value: "b"}},
+	})
+	require.NoError(t, err)
+
+	sections, err := p.Get(ctx, "Test")
+	require.NoError(t, err)
+	require.Len(t, sections, 2)
+
+	assert.Equal(t, "from-file", sections[0].ID)
+	assert.True(t, sections[0].IsReadonly())
+	assert.Equal(t, id, sections[1].ID)
+	assert.False(t, sections[1].IsReadonly())
+
+	sec, err := p.GetID(ctx, "from-file")
+	require.NoError(t, err)
+	assert.True(t, sec.IsReadonly())
+
+	// writes are routed to the writable layer
+	require.NoError(t, p.Update(ctx, id, "Test", conf.Options{{Name: "Value", Value: "c"}}))
+	sec, err = p.GetID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, sec.Options.GetStringSlice("Value"))
+
+	// file sections cannot be modified
+	assert.ErrorIs(t, p.Update(ctx, "from-file", "Test", nil), runtime.ErrReadOnly)
+	assert.ErrorIs(t, p.Delete(ctx, "from-file"), runtime.ErrReadOnly)
+
+	require.NoError(t, p.Delete(ctx, id))
+	_, err = p.GetID(ctx, id)
+	assert.ErrorIs(t, err, runtime.ErrCfgSectionNotFound)
+}
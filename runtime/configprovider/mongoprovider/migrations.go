@@ -0,0 +1,93 @@
+package mongoprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"github.com/tierklinik-dobersberg/cis/runtime/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MigrationKey returns the key used to track option migrations of the
+// configuration schema name in the schema database.
+func MigrationKey(name string) string {
+	return "config:" + strings.ToLower(name)
+}
+
+// Migrations returns a schema migration for each option migration of
+// regs. The migrations rewrite all records of colName that are stored
+// with a lower schema version and are meant to be added to a
+// schema.Registry before calling ApplyMigrations. Records without a
+// version have been stored before versioning and run through all
+// option migrations.
+func Migrations(colName string, regs ...runtime.Schema) []schema.Migration {
+	var result []schema.Migration
+
+	for _, reg := range regs {
+		for _, m := range reg.Migrations {
+			result = append(result, schema.Migration{
+				Description:       m.Description,
+				Key:               MigrationKey(reg.Name),
+				Version:           fmt.Sprintf("%d.0.0", m.Version),
+				MigrateFunc:       migrateRecords(colName, reg.Name, m),
+				BackupCollections: []string{colName},
+			})
+		}
+	}
+
+	return result
+}
+
+func migrateRecords(colName, secType string, m runtime.OptionMigration) schema.MigrateFunc {
+	return func(ctx context.Context, _, _ *version.Version, db *mongo.Database) error {
+		col := db.Collection(colName)
+
+		cursor, err := col.Find(ctx, bson.M{
+			"key": strings.ToLower(secType),
+			"$or": bson.A{
+				bson.M{"version": bson.M{"$lt": m.Version}},
+				bson.M{"version": bson.M{"$exists": false}},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		count := 0
+		for cursor.Next(ctx) {
+			var r record
+			if err := cursor.Decode(&r); err != nil {
+				return err
+			}
+
+			opts, err := m.Apply(r.Options)
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", secType, r.ID.Hex(), err)
+			}
+
+			if _, err := col.UpdateOne(ctx, bson.M{"_id": r.ID}, bson.M{
+				"$set": bson.M{
+					"options": opts,
+					"version": m.Version,
+				},
+			}); err != nil {
+				return fmt.Errorf("%s %s: %w", secType, r.ID.Hex(), err)
+			}
+
+			count++
+		}
+
+		if err := cursor.Err(); err != nil {
+			return err
+		}
+
+		log.From(ctx).Infof("migrated %d %s instances to version %d", count, secType, m.Version)
+
+		return nil
+	}
+}
@@ -3,19 +3,26 @@ package mongoprovider
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/pkg/pkglog"
 	"github.com/tierklinik-dobersberg/cis/runtime"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var log = pkglog.New("mongoprovider")
+
 type record struct {
 	ID primitive.ObjectID `bson:"_id,omitempty"`
 
 	Key     string        `bson:"key"`
+	Version int           `bson:"version,omitempty"`
 	Options []conf.Option `bson:"options"`
 }
 
@@ -23,6 +30,7 @@ type record struct {
 // data in a mongodb collection.
 type MongoProvider struct {
 	collection *mongo.Collection
+	versionOf  func(secType string) int
 }
 
 // New returns a new MongoDB backend runtime.ConfigProvider that stores
@@ -36,11 +44,26 @@ func New(cli *mongo.Client, dbName, colName string) *MongoProvider {
 	}
 }
 
+// SetVersionFunc configures fn to return the current schema version that
+// is stored with each created or updated record. See Migrations.
+func (pr *MongoProvider) SetVersionFunc(fn func(secType string) int) {
+	pr.versionOf = fn
+}
+
+func (pr *MongoProvider) version(secType string) int {
+	if pr.versionOf == nil {
+		return 0
+	}
+
+	return pr.versionOf(secType)
+}
+
 // Create stores a new configuration section in the database collection.
 // It returns the ID of the new record along with any encountered error.
 func (pr *MongoProvider) Create(ctx context.Context, sec conf.Section) (string, error) {
 	res, err := pr.collection.InsertOne(ctx, record{
 		Key:     strings.ToLower(sec.Name),
+		Version: pr.version(sec.Name),
 		Options: sec.Options,
 	})
 	if err != nil {
@@ -51,6 +74,27 @@ func (pr *MongoProvider) Create(ctx context.Context, sec conf.Section) (string,
 	return res.InsertedID.(primitive.ObjectID).Hex(), nil
 }
 
+// CreateWithID implements runtime.IDConfigProvider. id must be the hex
+// representation of an ObjectID.
+func (pr *MongoProvider) CreateWithID(ctx context.Context, id string, sec conf.Section) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", runtime.ErrInvalidID, err)
+	}
+
+	_, err = pr.collection.InsertOne(ctx, record{
+		ID:      oid,
+		Key:     strings.ToLower(sec.Name),
+		Version: pr.version(sec.Name),
+		Options: sec.Options,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: %q is already in use", runtime.ErrInvalidID, id)
+	}
+
+	return err
+}
+
 // Update an existing configuration object in the database collection.
 // The object is identified by id and secType.
 func (pr *MongoProvider) Update(ctx context.Context, id, secType string, opts []conf.Option) error {
@@ -70,6 +114,7 @@ func (pr *MongoProvider) Update(ctx context.Context, id, secType string, opts []
 		record{
 			ID:      oid,
 			Key:     secType,
+			Version: pr.version(secType),
 			Options: opts,
 		},
 	)
@@ -159,3 +204,222 @@ func (pr *MongoProvider) GetID(ctx context.Context, id string) (runtime.Section,
 		},
 	}, nil
 }
+
+// changeEvent is the subset of a mongodb change stream event used by
+// Watch.
+type changeEvent struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *record `bson:"fullDocument"`
+}
+
+// watchRetryDelay is the time Watch waits before re-opening a failed
+// change stream.
+const watchRetryDelay = 5 * time.Second
+
+// Watch implements runtime.WatchableConfigProvider using a mongodb change
+// stream on the configuration collection. Note that change streams
+// require mongodb to run as a replica set. Failed change streams are
+// resumed until ctx is cancelled.
+func (pr *MongoProvider) Watch(ctx context.Context) (<-chan runtime.ConfigChange, error) {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+
+	stream, err := pr.collection.Watch(ctx, mongo.Pipeline{}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan runtime.ConfigChange)
+
+	go func() {
+		defer close(ch)
+
+		for {
+			for stream.Next(ctx) {
+				var evt changeEvent
+				if err := stream.Decode(&evt); err != nil {
+					log.From(ctx).Errorf("failed to decode change event: %s", err)
+
+					continue
+				}
+
+				change, ok := evt.toConfigChange()
+				if !ok {
+					continue
+				}
+
+				select {
+				case ch <- change:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if ctx.Err() != nil {
+				_ = stream.Close(context.Background())
+
+				return
+			}
+
+			log.From(ctx).Errorf("configuration change stream failed: %v", stream.Err())
+
+			token := stream.ResumeToken()
+			_ = stream.Close(ctx)
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(watchRetryDelay):
+				}
+
+				if token != nil {
+					opts.SetResumeAfter(token)
+				}
+
+				stream, err = pr.collection.Watch(ctx, mongo.Pipeline{}, opts)
+				if err == nil {
+					break
+				}
+
+				log.From(ctx).Errorf("failed to re-open configuration change stream: %s", err)
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (evt changeEvent) toConfigChange() (runtime.ConfigChange, bool) {
+	switch evt.OperationType {
+	case "insert", "update", "replace":
+		// the document may already be deleted when the
+		// update is looked up.
+		if evt.FullDocument == nil {
+			return runtime.ConfigChange{}, false
+		}
+
+		changeType := runtime.ChangeTypeUpdate
+		if evt.OperationType == "insert" {
+			changeType = runtime.ChangeTypeCreate
+		}
+
+		return runtime.ConfigChange{
+			Type: changeType,
+			Section: runtime.Section{
+				ID: evt.DocumentKey.ID.Hex(),
+				Section: conf.Section{
+					Name:    evt.FullDocument.Key,
+					Options: evt.FullDocument.Options,
+				},
+			},
+		}, true
+
+	case "delete":
+		return runtime.ConfigChange{
+			Type: runtime.ChangeTypeDelete,
+			Section: runtime.Section{
+				ID: evt.DocumentKey.ID.Hex(),
+			},
+		}, true
+	}
+
+	return runtime.ConfigChange{}, false
+}
+
+// ApplyBatch implements runtime.BatchConfigProvider using a multi-document
+// transaction. Note that transactions require mongodb to run as a replica
+// set.
+func (pr *MongoProvider) ApplyBatch(ctx context.Context, changes []runtime.ConfigChange) error {
+	sess, err := pr.collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer sess.EndSession(ctx)
+
+	ids := make([]string, len(changes))
+
+	_, err = sess.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		for idx, change := range changes {
+			if change.Type == runtime.ChangeTypeCreate {
+				oid := primitive.NewObjectID()
+				if change.Section.ID != "" {
+					var err error
+					if oid, err = primitive.ObjectIDFromHex(change.Section.ID); err != nil {
+						return nil, fmt.Errorf("%w: %w", runtime.ErrInvalidID, err)
+					}
+				}
+
+				_, err := pr.collection.InsertOne(sc, record{
+					ID:      oid,
+					Key:     strings.ToLower(change.Section.Name),
+					Version: pr.version(change.Section.Name),
+					Options: change.Section.Options,
+				})
+				if mongo.IsDuplicateKeyError(err) {
+					return nil, fmt.Errorf("%w: %q is already in use", runtime.ErrInvalidID, change.Section.ID)
+				}
+				if err != nil {
+					return nil, err
+				}
+
+				ids[idx] = oid.Hex()
+
+				continue
+			}
+
+			oid, err := primitive.ObjectIDFromHex(change.Section.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			var matched int64
+			switch change.Type {
+			case runtime.ChangeTypeUpdate:
+				secType := strings.ToLower(change.Section.Name)
+
+				res, err := pr.collection.ReplaceOne(sc, bson.M{"_id": oid, "key": secType}, record{
+					ID:      oid,
+					Key:     secType,
+					Version: pr.version(secType),
+					Options: change.Section.Options,
+				})
+				if err != nil {
+					return nil, err
+				}
+				matched = res.MatchedCount
+
+			case runtime.ChangeTypeDelete:
+				res, err := pr.collection.DeleteOne(sc, bson.M{"_id": oid})
+				if err != nil {
+					return nil, err
+				}
+				matched = res.DeletedCount
+			}
+
+			if matched == 0 {
+				return nil, runtime.ErrCfgSectionNotFound
+			}
+
+			ids[idx] = change.Section.ID
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for idx := range changes {
+		changes[idx].Section.ID = ids[idx]
+	}
+
+	return nil
+}
+
+var (
+	_ runtime.WatchableConfigProvider = (*MongoProvider)(nil)
+	_ runtime.BatchConfigProvider     = (*MongoProvider)(nil)
+)
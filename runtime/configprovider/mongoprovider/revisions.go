@@ -0,0 +1,116 @@
+package mongoprovider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxAppendAttempts limits the number of retries if another process
+// stored a revision with the same version concurrently.
+const maxAppendAttempts = 5
+
+// RevisionStore is a runtime.RevisionStore that keeps configuration
+// revisions in a mongodb collection.
+type RevisionStore struct {
+	collection *mongo.Collection
+}
+
+// NewRevisionStore returns a new revision store using the collection
+// colName inside the database dbName. A unique index on the instance ID
+// and version is created if it does not exist yet.
+func NewRevisionStore(ctx context.Context, cli *mongo.Client, dbName, colName string) (*RevisionStore, error) {
+	col := cli.Database(dbName).Collection(colName)
+
+	_, err := col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "instanceId", Value: 1},
+			{Key: "version", Value: -1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RevisionStore{
+		collection: col,
+	}, nil
+}
+
+// AppendRevision implements runtime.RevisionStore.
+func (store *RevisionStore) AppendRevision(ctx context.Context, rev *runtime.ConfigRevision) error {
+	var err error
+
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		var latest *runtime.ConfigRevision
+
+		latest, err = store.findOne(ctx, bson.M{"instanceId": rev.InstanceID})
+		switch {
+		case errors.Is(err, runtime.ErrNoRevision):
+			rev.Version = 1
+		case err != nil:
+			return err
+		default:
+			rev.Version = latest.Version + 1
+		}
+
+		_, err = store.collection.InsertOne(ctx, rev)
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// ListRevisions implements runtime.RevisionStore.
+func (store *RevisionStore) ListRevisions(ctx context.Context, instanceID string) ([]runtime.ConfigRevision, error) {
+	opts := options.Find().SetSort(bson.M{"version": -1})
+
+	cursor, err := store.collection.Find(ctx, bson.M{"instanceId": instanceID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []runtime.ConfigRevision
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetRevision implements runtime.RevisionStore.
+func (store *RevisionStore) GetRevision(ctx context.Context, instanceID string, version int) (*runtime.ConfigRevision, error) {
+	return store.findOne(ctx, bson.M{
+		"instanceId": instanceID,
+		"version":    version,
+	})
+}
+
+func (store *RevisionStore) findOne(ctx context.Context, filter bson.M) (*runtime.ConfigRevision, error) {
+	opts := options.FindOne().SetSort(bson.M{"version": -1})
+
+	res := store.collection.FindOne(ctx, filter, opts)
+	if res.Err() != nil {
+		if errors.Is(res.Err(), mongo.ErrNoDocuments) {
+			return nil, runtime.ErrNoRevision
+		}
+
+		return nil, res.Err()
+	}
+
+	var rev runtime.ConfigRevision
+	if err := res.Decode(&rev); err != nil {
+		return nil, err
+	}
+
+	return &rev, nil
+}
+
+var _ runtime.RevisionStore = (*RevisionStore)(nil)
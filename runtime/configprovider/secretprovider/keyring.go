@@ -0,0 +1,177 @@
+package secretprovider
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// encryptedPrefix is prepended to all encrypted values.
+const encryptedPrefix = "enc:v1:"
+
+// hkdfInfo binds derived keys to the encryption of configuration values.
+const hkdfInfo = "config-secrets"
+
+// Common errors when encrypting or decrypting values.
+var (
+	ErrUnknownKey    = errors.New("secretprovider: value is encrypted with an unknown key")
+	ErrInvalidValue  = errors.New("secretprovider: invalid encrypted value")
+	ErrMissingSecret = errors.New("secretprovider: secret must not be empty")
+	errNotEncrypted  = errors.New("secretprovider: value is not encrypted")
+)
+
+type key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// Keyring encrypts values using AES-256-GCM with a key derived from the
+// current secret using HKDF. Values encrypted with one of the previous
+// secrets can still be decrypted which allows key rotation.
+type Keyring struct {
+	current key
+	keys    map[string]key
+}
+
+// NewKeyring returns a new keyring that encrypts values using secret and
+// decrypts values encrypted using secret or any of previous.
+func NewKeyring(secret string, previous ...string) (*Keyring, error) {
+	if secret == "" {
+		return nil, ErrMissingSecret
+	}
+
+	kr := &Keyring{
+		keys: make(map[string]key),
+	}
+
+	for idx, s := range append([]string{secret}, previous...) {
+		if s == "" {
+			continue
+		}
+
+		derived := make([]byte, 32)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(s), nil, []byte(hkdfInfo)), derived); err != nil {
+			return nil, err
+		}
+
+		k, err := newKey(derived)
+		if err != nil {
+			return nil, err
+		}
+
+		if idx == 0 {
+			kr.current = k
+		}
+
+		kr.keys[k.id] = k
+	}
+
+	return kr, nil
+}
+
+func newKey(derived []byte) (key, error) {
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return key{}, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return key{}, err
+	}
+
+	// the key id is derived from the key so it does not reveal the
+	// secret.
+	id := sha256.Sum256(derived)
+
+	return key{
+		id:   hex.EncodeToString(id[:4]),
+		aead: aead,
+	}, nil
+}
+
+// IsEncrypted reports whether value has been encrypted by a Keyring.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// Encrypt encrypts value using the current key. The same additional data
+// must be passed to Decrypt.
+func (kr *Keyring) Encrypt(value string, additionalData []byte) (string, error) {
+	nonce := make([]byte, kr.current.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := kr.current.aead.Seal(nonce, nonce, []byte(value), additionalData)
+
+	return encryptedPrefix + kr.current.id + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts value. Values that are not encrypted are returned
+// as is.
+func (kr *Keyring) Decrypt(value string, additionalData []byte) (string, error) {
+	k, sealed, err := kr.parse(value)
+	if errors.Is(err, errNotEncrypted) {
+		return value, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	nonceSize := k.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", ErrInvalidValue
+	}
+
+	plain, err := k.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], additionalData)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidValue, err)
+	}
+
+	return string(plain), nil
+}
+
+// NeedsRotation reports whether value is not encrypted using the current
+// key.
+func (kr *Keyring) NeedsRotation(value string) bool {
+	if !IsEncrypted(value) {
+		return true
+	}
+
+	id, _, _ := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+
+	return id != kr.current.id
+}
+
+func (kr *Keyring) parse(value string) (key, []byte, error) {
+	if !IsEncrypted(value) {
+		return key{}, nil, errNotEncrypted
+	}
+
+	id, data, ok := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if !ok {
+		return key{}, nil, ErrInvalidValue
+	}
+
+	k, ok := kr.keys[id]
+	if !ok {
+		return key{}, nil, fmt.Errorf("%w %s", ErrUnknownKey, id)
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return key{}, nil, fmt.Errorf("%w: %s", ErrInvalidValue, err)
+	}
+
+	return k, sealed, nil
+}
@@ -0,0 +1,329 @@
+// Package secretprovider implements a runtime.ConfigProvider that
+// encrypts the values of secret options before they are stored by
+// another provider.
+package secretprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ppacher/system-conf/conf"
+	"github.com/tierklinik-dobersberg/cis/pkg/pkglog"
+	"github.com/tierklinik-dobersberg/cis/runtime"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var log = pkglog.New("secretprovider")
+
+// SecretProvider wraps a runtime.ConfigProvider and encrypts all values
+// of options annotated with runtime.Secret before they are passed to the
+// wrapped provider. Values are decrypted when read. Values that are
+// stored unencrypted, e.g. before encryption has been enabled, are
+// returned as is. Encrypted values are bound to the schema and instance
+// so they cannot be copied to another instance. They are not bound to
+// the option so option migrations may rename secret options.
+type SecretProvider struct {
+	inner   runtime.ConfigProvider
+	keys    *Keyring
+	secrets map[string]map[string]struct{}
+}
+
+// New returns a new provider that encrypts the secret options of regs
+// before storing them in inner. The options of regs are captured when
+// New is called so all schemas must already be registered. inner must
+// support runtime.IDConfigProvider to create instances of regs that have
+// secret options and must use the IDs of created sections passed to
+// ApplyBatch.
+func New(inner runtime.ConfigProvider, keys *Keyring, regs ...runtime.Schema) *SecretProvider {
+	secrets := make(map[string]map[string]struct{})
+
+	for _, reg := range regs {
+		for _, spec := range reg.Spec.All() {
+			if !runtime.IsSecret(spec) {
+				continue
+			}
+
+			lower := strings.ToLower(reg.Name)
+			if secrets[lower] == nil {
+				secrets[lower] = make(map[string]struct{})
+			}
+
+			secrets[lower][strings.ToLower(spec.Name)] = struct{}{}
+		}
+	}
+
+	return &SecretProvider{
+		inner:   inner,
+		keys:    keys,
+		secrets: secrets,
+	}
+}
+
+// Create implements runtime.ConfigProvider.
+func (sp *SecretProvider) Create(ctx context.Context, sec conf.Section) (string, error) {
+	if len(sp.secrets[strings.ToLower(sec.Name)]) == 0 {
+		return sp.inner.Create(ctx, sec)
+	}
+
+	// encrypted values are bound to the instance so the ID must be
+	// known before the section is stored.
+	id := newID()
+	if err := sp.CreateWithID(ctx, id, sec); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// CreateWithID implements runtime.IDConfigProvider if the wrapped provider
+// supports it.
+func (sp *SecretProvider) CreateWithID(ctx context.Context, id string, sec conf.Section) error {
+	idProvider, ok := sp.inner.(runtime.IDConfigProvider)
+	if !ok {
+		return runtime.ErrIDNotSupported
+	}
+
+	opts, err := sp.encrypt(sec.Name, id, sec.Options)
+	if err != nil {
+		return err
+	}
+
+	return idProvider.CreateWithID(ctx, id, conf.Section{
+		Name:    sec.Name,
+		Options: opts,
+	})
+}
+
+// Update implements runtime.ConfigProvider.
+func (sp *SecretProvider) Update(ctx context.Context, id, secType string, opts []conf.Option) error {
+	opts, err := sp.encrypt(secType, id, opts)
+	if err != nil {
+		return err
+	}
+
+	return sp.inner.Update(ctx, id, secType, opts)
+}
+
+// Delete implements runtime.ConfigProvider.
+func (sp *SecretProvider) Delete(ctx context.Context, id string) error {
+	return sp.inner.Delete(ctx, id)
+}
+
+// Get implements runtime.ConfigProvider.
+func (sp *SecretProvider) Get(ctx context.Context, sectionType string) ([]runtime.Section, error) {
+	sections, err := sp.inner.Get(ctx, sectionType)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range sections {
+		if err := sp.decryptSection(&sections[idx]); err != nil {
+			return nil, err
+		}
+	}
+
+	return sections, nil
+}
+
+// GetID implements runtime.ConfigProvider.
+func (sp *SecretProvider) GetID(ctx context.Context, id string) (runtime.Section, error) {
+	sec, err := sp.inner.GetID(ctx, id)
+	if err != nil {
+		return runtime.Section{}, err
+	}
+
+	if err := sp.decryptSection(&sec); err != nil {
+		return runtime.Section{}, err
+	}
+
+	return sec, nil
+}
+
+// Watch implements runtime.WatchableConfigProvider if the wrapped
+// provider supports watching. Changes that cannot be decrypted are
+// dropped.
+func (sp *SecretProvider) Watch(ctx context.Context) (<-chan runtime.ConfigChange, error) {
+	watchable, ok := sp.inner.(runtime.WatchableConfigProvider)
+	if !ok {
+		return nil, runtime.ErrWatchNotSupported
+	}
+
+	ch, err := watchable.Watch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan runtime.ConfigChange)
+
+	go func() {
+		defer close(out)
+
+		for change := range ch {
+			if err := sp.decryptSection(&change.Section); err != nil {
+				log.From(ctx).Errorf("failed to decrypt change of %s: %s", change.Section.ID, err)
+
+				continue
+			}
+
+			select {
+			case out <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// ApplyBatch implements runtime.BatchConfigProvider if the wrapped
+// provider supports batches.
+func (sp *SecretProvider) ApplyBatch(ctx context.Context, changes []runtime.ConfigChange) error {
+	batcher, ok := sp.inner.(runtime.BatchConfigProvider)
+	if !ok {
+		return runtime.ErrBatchNotSupported
+	}
+
+	encrypted := make([]runtime.ConfigChange, len(changes))
+	for idx, change := range changes {
+		// created sections use the ID their values are bound to.
+		if change.Type == runtime.ChangeTypeCreate && len(sp.secrets[strings.ToLower(change.Section.Name)]) > 0 {
+			change.Section.ID = newID()
+		}
+
+		if change.Type != runtime.ChangeTypeDelete {
+			opts, err := sp.encrypt(change.Section.Name, change.Section.ID, change.Section.Options)
+			if err != nil {
+				return err
+			}
+
+			change.Section.Options = opts
+		}
+
+		encrypted[idx] = change
+	}
+
+	if err := batcher.ApplyBatch(ctx, encrypted); err != nil {
+		return err
+	}
+
+	// ApplyBatch reports the IDs of created sections.
+	for idx := range changes {
+		changes[idx].Section.ID = encrypted[idx].Section.ID
+	}
+
+	return nil
+}
+
+// Rotate re-encrypts all secret values of regs that are stored unencrypted
+// or using a previous key with the current key. The keyring must hold all
+// previous secrets that have been used. It returns the number of updated
+// sections.
+func (sp *SecretProvider) Rotate(ctx context.Context, regs ...runtime.Schema) (int, error) {
+	count := 0
+
+	for _, reg := range regs {
+		secrets := sp.secrets[strings.ToLower(reg.Name)]
+		if len(secrets) == 0 {
+			continue
+		}
+
+		sections, err := sp.inner.Get(ctx, reg.Name)
+		if err != nil {
+			return count, fmt.Errorf("%s: %w", reg.Name, err)
+		}
+
+		for _, sec := range sections {
+			rotate := false
+			for _, opt := range sec.Options {
+				if _, ok := secrets[strings.ToLower(opt.Name)]; ok && opt.Value != "" && sp.keys.NeedsRotation(opt.Value) {
+					rotate = true
+
+					break
+				}
+			}
+
+			if !rotate || sec.IsReadonly() {
+				continue
+			}
+
+			if err := sp.decryptSection(&sec); err != nil {
+				return count, fmt.Errorf("%s %s: %w", reg.Name, sec.ID, err)
+			}
+
+			if err := sp.Update(ctx, sec.ID, reg.Name, sec.Options); err != nil {
+				return count, fmt.Errorf("%s %s: %w", reg.Name, sec.ID, err)
+			}
+
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (sp *SecretProvider) encrypt(secType, id string, opts []conf.Option) ([]conf.Option, error) {
+	secrets := sp.secrets[strings.ToLower(secType)]
+	if len(secrets) == 0 {
+		return opts, nil
+	}
+
+	result := make([]conf.Option, len(opts))
+	for idx, opt := range opts {
+		if _, ok := secrets[strings.ToLower(opt.Name)]; ok && opt.Value != "" {
+			value, err := sp.keys.Encrypt(opt.Value, additionalData(secType, id))
+			if err != nil {
+				return nil, fmt.Errorf("option %s: %w", opt.Name, err)
+			}
+
+			opt.Value = value
+		}
+
+		result[idx] = opt
+	}
+
+	return result, nil
+}
+
+func (sp *SecretProvider) decryptSection(sec *runtime.Section) error {
+	secrets := sp.secrets[strings.ToLower(sec.Name)]
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	result := make([]conf.Option, len(sec.Options))
+	for idx, opt := range sec.Options {
+		if _, ok := secrets[strings.ToLower(opt.Name)]; ok {
+			value, err := sp.keys.Decrypt(opt.Value, additionalData(sec.Name, sec.ID))
+			if err != nil {
+				return fmt.Errorf("%s %s: option %s: %w", sec.Name, sec.ID, opt.Name, err)
+			}
+
+			opt.Value = value
+		}
+
+		result[idx] = opt
+	}
+	sec.Options = result
+
+	return nil
+}
+
+// additionalData returns the data that binds an encrypted value to a
+// configuration instance.
+func additionalData(secType, id string) []byte {
+	return []byte(strings.ToLower(secType) + "\x00" + id)
+}
+
+// newID returns a new ID for a created section. ObjectIDs are accepted by
+// all writable providers.
+func newID() string {
+	return primitive.NewObjectID().Hex()
+}
+
+var (
+	_ runtime.WatchableConfigProvider = (*SecretProvider)(nil)
+	_ runtime.BatchConfigProvider     = (*SecretProvider)(nil)
+	_ runtime.IDConfigProvider        = (*SecretProvider)(nil)
+)
@@ -0,0 +1,248 @@
+package schema
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tierklinik-dobersberg/cis/pkg/svcenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxDumpLine is the maximum size of a single document in a dump file.
+// It matches the maximum size of a BSON document plus some room for the
+// extended JSON encoding.
+const maxDumpLine = 32 * 1024 * 1024
+
+// ErrLegacyDump is returned when restoring a dump that has been created
+// before dumps used canonical extended JSON. Those dumps do not preserve
+// BSON types like ObjectIDs and dates and must be restored manually.
+var ErrLegacyDump = errors.New("schema: dump uses the legacy indented JSON format which does not preserve BSON types and cannot be restored automatically")
+
+// dump is a collection dump created before running a migration.
+type dump struct {
+	collection string
+	path       string
+}
+
+// DumpDirectory returns the directory that holds collection dumps
+// created before running migrations.
+func DumpDirectory() string {
+	return filepath.Join(svcenv.Env().StateDirectory, "dumps")
+}
+
+// collectionStore provides access to the documents of collections
+// when creating and restoring dumps.
+type collectionStore interface {
+	// each calls fn for each document of the collection colName.
+	each(ctx context.Context, colName string, fn func(doc bson.Raw) error) error
+
+	// replace replaces all documents of the collection colName with
+	// docs.
+	replace(ctx context.Context, colName string, docs []bson.Raw) error
+}
+
+// mongoStore implements collectionStore for a MongoDB database.
+type mongoStore struct {
+	db *mongo.Database
+}
+
+func newMongoStore(db *mongo.Database) collectionStore {
+	return mongoStore{db: db}
+}
+
+func (ms mongoStore) each(ctx context.Context, colName string, fn func(doc bson.Raw) error) error {
+	cursor, err := ms.db.Collection(colName).Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		if err := fn(cursor.Current); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// replace deletes and inserts the documents in a single transaction so
+// the collection is never left empty or partially restored. Note that
+// transactions require mongodb to run as a replica set.
+func (ms mongoStore) replace(ctx context.Context, colName string, docs []bson.Raw) error {
+	sess, err := ms.db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer sess.EndSession(ctx)
+
+	col := ms.db.Collection(colName)
+
+	_, err = sess.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		if _, err := col.DeleteMany(sc, bson.M{}); err != nil {
+			return nil, err
+		}
+
+		if len(docs) == 0 {
+			return nil, nil
+		}
+
+		insert := make([]interface{}, len(docs))
+		for idx, doc := range docs {
+			insert[idx] = doc
+		}
+
+		_, err := col.InsertMany(sc, insert)
+
+		return nil, err
+	})
+
+	return err
+}
+
+// dumpCollections creates a dump of all collections inside DumpDirectory
+// using key and t for the filename.
+func dumpCollections(ctx context.Context, store collectionStore, key string, collections []string, t time.Time) ([]dump, error) {
+	if len(collections) == 0 {
+		return nil, nil
+	}
+
+	dumpDir := DumpDirectory()
+	if err := os.MkdirAll(dumpDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create migration dump directory at %s: %q", dumpDir, err)
+	}
+
+	dumps := make([]dump, 0, len(collections))
+	for _, colName := range collections {
+		path := filepath.Join(
+			dumpDir,
+			fmt.Sprintf("%s-%s-%d.json", key, colName, t.UnixNano()),
+		)
+
+		if err := dumpCollection(ctx, store, colName, path); err != nil {
+			return nil, fmt.Errorf("%s: %w", colName, err)
+		}
+
+		dumps = append(dumps, dump{
+			collection: colName,
+			path:       path,
+		})
+	}
+
+	return dumps, nil
+}
+
+// dumpCollection writes all documents of colName to path using one
+// canonical extended JSON document per line so BSON types survive a
+// restore.
+func dumpCollection(ctx context.Context, store collectionStore, colName, path string) error {
+	dumpFile, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer dumpFile.Close()
+
+	w := bufio.NewWriter(dumpFile)
+
+	err = store.each(ctx, colName, func(doc bson.Raw) error {
+		line, err := bson.MarshalExtJSON(doc, true, false)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(append(line, '\n'))
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return dumpFile.Close()
+}
+
+// restoreDumps restores dumps in reverse order so the oldest dump of a
+// collection wins.
+func restoreDumps(ctx context.Context, store collectionStore, dumps []dump) error {
+	for idx := len(dumps) - 1; idx >= 0; idx-- {
+		count, err := restoreDump(ctx, store, dumps[idx].collection, dumps[idx].path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", dumps[idx].path, err)
+		}
+
+		log.From(ctx).Infof("restored %d documents of %s from %s", count, dumps[idx].collection, dumps[idx].path)
+	}
+
+	return nil
+}
+
+// RestoreDump replaces all documents of the collection colName with the
+// documents stored in the dump file at path. The dump is read completely
+// before the collection is modified and the documents are replaced in a
+// single transaction. Indexes of the collection are kept. It returns the
+// number of restored documents. ErrLegacyDump is returned for dumps
+// that have been created before dumps used extended JSON.
+func RestoreDump(ctx context.Context, cli *mongo.Database, colName, path string) (int, error) {
+	return restoreDump(ctx, newMongoStore(cli), colName, path)
+}
+
+func restoreDump(ctx context.Context, store collectionStore, colName, path string) (int, error) {
+	docs, err := readDump(path)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := store.replace(ctx, colName, docs); err != nil {
+		return 0, err
+	}
+
+	return len(docs), nil
+}
+
+// readDump reads all documents of the dump file at path.
+func readDump(path string) ([]bson.Raw, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var docs []bson.Raw
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxDumpLine)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		// legacy dumps hold indented JSON arrays of key-value pairs.
+		if len(docs) == 0 && line[0] == '[' {
+			return nil, ErrLegacyDump
+		}
+
+		var doc bson.Raw
+		if err := bson.UnmarshalExtJSON(line, true, &doc); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		docs = append(docs, doc)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
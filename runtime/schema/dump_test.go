@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMain(m *testing.M) {
+	// dumps are written to the state directory.
+	dir, err := os.MkdirTemp("", "schema-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("STATE_DIRECTORY", dir)
+
+	code := m.Run()
+
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// memoryStore is a collectionStore that keeps all documents in memory.
+type memoryStore map[string][]bson.Raw
+
+func (ms memoryStore) each(_ context.Context, colName string, fn func(bson.Raw) error) error {
+	for _, doc := range ms[colName] {
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ms memoryStore) replace(_ context.Context, colName string, docs []bson.Raw) error {
+	ms[colName] = docs
+
+	return nil
+}
+
+func mustMarshal(t *testing.T, doc interface{}) bson.Raw {
+	t.Helper()
+
+	raw, err := bson.Marshal(doc)
+	require.NoError(t, err)
+
+	return raw
+}
+
+func TestRestoreDump(t *testing.T) {
+	ctx := context.Background()
+
+	oid := primitive.NewObjectID()
+	created := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+
+	store := memoryStore{
+		"config": {
+			mustMarshal(t, bson.D{{Key: "_id", Value: oid}, {Key: "created", Value: created}, {Key: "count", Value: int64(3)}}),
+			mustMarshal(t, bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "name", Value: "second"}}),
+		},
+	}
+	original := store["config"]
+
+	dumps, err := dumpCollections(ctx, store, "test", []string{"config"}, time.Now())
+	require.NoError(t, err)
+	require.Len(t, dumps, 1)
+
+	store["config"] = nil
+
+	count, err := restoreDump(ctx, store, "config", dumps[0].path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.Len(t, store["config"], 2)
+
+	// BSON types are preserved.
+	for idx, doc := range store["config"] {
+		assert.Equal(t, original[idx], doc)
+	}
+
+	var restored struct {
+		ID      primitive.ObjectID `bson:"_id"`
+		Created time.Time          `bson:"created"`
+		Count   int64              `bson:"count"`
+	}
+	require.NoError(t, bson.Unmarshal(store["config"][0], &restored))
+	assert.Equal(t, oid, restored.ID)
+	assert.True(t, created.Equal(restored.Created))
+	assert.Equal(t, int64(3), restored.Count)
+}
+
+func TestRestoreLegacyDump(t *testing.T) {
+	ctx := context.Background()
+
+	// dumps of previous versions hold indented JSON arrays of
+	// key-value pairs.
+	path := filepath.Join(t.TempDir(), "legacy.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+  {
+    "Key": "_id",
+    "Value": "65e5f0a1c2b3d4e5f6a7b8c9"
+  }
+]
+`), 0o600))
+
+	store := memoryStore{
+		"config": {mustMarshal(t, bson.D{{Key: "name", Value: "keep"}})},
+	}
+
+	_, err := restoreDump(ctx, store, "config", path)
+	assert.ErrorIs(t, err, ErrLegacyDump)
+
+	// the collection is not modified.
+	assert.Len(t, store["config"], 1)
+}
+
+func TestRollbackFailedMigration(t *testing.T) {
+	ctx := context.Background()
+	db := memoryDatabase{}
+
+	store := memoryStore{
+		"config": {mustMarshal(t, bson.D{{Key: "name", Value: "before"}})},
+	}
+	original := store["config"]
+
+	reg := NewRegistry()
+	reg.collections = func(*mongo.Database) collectionStore { return store }
+
+	reg.Add(Migration{
+		Key:               "config",
+		Version:           "1.0.0",
+		BackupCollections: []string{"config"},
+		MigrateFunc: func(context.Context, *version.Version, *version.Version, *mongo.Database) error {
+			store["config"] = []bson.Raw{mustMarshal(t, bson.D{{Key: "name", Value: "partial"}})}
+
+			return errors.New("failed")
+		},
+	})
+
+	_, err := reg.ApplyMigrations(ctx, db, nil)
+	require.Error(t, err)
+
+	// the collection is restored from the dump and the migration is not
+	// recorded.
+	assert.Equal(t, original, store["config"])
+	assert.Empty(t, db["config"])
+
+	matches, err := filepath.Glob(filepath.Join(DumpDirectory(), "config-config-*.json"))
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+}
@@ -2,19 +2,14 @@ package schema
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sort"
 	"sync"
 	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/tierklinik-dobersberg/cis/pkg/pkglog"
-	"github.com/tierklinik-dobersberg/cis/pkg/svcenv"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -36,19 +31,24 @@ type Migration struct {
 	MigrateFunc MigrateFunc
 	// BackupCollections can be set to a list of collection names
 	// that should be dumped to disk prior to calling MigrateFunc.
-	// In case an error is returned by MigrateFunc, all collections
-	// listed here are dropped and re-created from the saved dump.
+	// In case an error is returned by MigrateFunc, the documents of all
+	// collections listed here are replaced with the saved dump.
 	BackupCollections []string
 }
 
 type Registry struct {
 	lock     sync.Mutex
 	migrates map[string][]Migration
+
+	// collections returns the store used to dump and restore the
+	// BackupCollections of migrations.
+	collections func(cli *mongo.Database) collectionStore
 }
 
 func NewRegistry() *Registry {
 	return &Registry{
-		migrates: make(map[string][]Migration),
+		migrates:    make(map[string][]Migration),
+		collections: newMongoStore,
 	}
 }
 
@@ -61,6 +61,9 @@ func (sr *Registry) Add(migration ...Migration) {
 	}
 }
 
+// ApplyMigrations applies all pending migrations in version order. If a
+// MigrateFunc fails, the collections listed in BackupCollections of all
+// migrations to the same version are restored from their dumps.
 func (sr *Registry) ApplyMigrations(ctx context.Context, db Database, cli *mongo.Database) (bool, error) {
 	sr.lock.Lock()
 	defer sr.lock.Unlock()
@@ -80,105 +83,139 @@ func (sr *Registry) ApplyMigrations(ctx context.Context, db Database, cli *mongo
 	return migrated, nil
 }
 
-// backupCollections creates a backup of all 'collections' storing them at the StateDirectory using t
-// for the filename.
-func (sr *Registry) backupCollections(ctx context.Context, cli *mongo.Database, key string, collections []string, t time.Time) error {
-	if len(collections) == 0 {
-		return nil
-	}
-	dumpDir := filepath.Join(
-		svcenv.Env().StateDirectory,
-		"dumps",
-	)
-	if err := os.MkdirAll(dumpDir, 0700); err != nil {
-		return fmt.Errorf("failed to create migration dump directory at %s: %q", dumpDir, err)
-	}
-	for _, colName := range collections {
-		col := cli.Collection(colName)
-		cursor, err := col.Find(ctx, bson.M{})
+// MigrationStatus describes the migration state of a single key.
+type MigrationStatus struct {
+	// Key identifies the subsystem.
+	Key string
+	// Current is the version of the last applied migration. It's empty
+	// if no migration has been applied yet.
+	Current string
+	// Latest is the version of the last registered migration.
+	Latest string
+	// Pending holds all migrations that would be applied by
+	// ApplyMigrations ordered by version.
+	Pending []Migration
+}
+
+// Status returns the migration status of all keys sorted by key.
+func (sr *Registry) Status(ctx context.Context, db Database) ([]MigrationStatus, error) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	result := make([]MigrationStatus, 0, len(sr.migrates))
+	for key, migrations := range sr.migrates {
+		lm, versions, err := groupByVersion(migrations)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("%s: %w", key, err)
 		}
-		defer cursor.Close(ctx)
-
-		dumpFile, err := os.Create(
-			filepath.Join(
-				dumpDir,
-				fmt.Sprintf("%s-%s-%d.json", key, colName, t.UnixNano()),
-			),
-		)
+
+		current, err := loadVersion(ctx, db, key)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("%s: %w", key, err)
 		}
 
-		encoder := json.NewEncoder(dumpFile)
-		encoder.SetIndent("", "  ")
-		encoder.SetEscapeHTML(false)
+		status := MigrationStatus{
+			Key: key,
+		}
+		if current != nil {
+			status.Current = current.String()
+		}
+		if len(versions) > 0 {
+			status.Latest = versions[len(versions)-1].String()
+		}
 
-		for cursor.Next(ctx) {
-			var x interface{}
-			if err := cursor.Decode(&x); err != nil {
-				return err
-			}
-			if err := encoder.Encode(x); err != nil {
-				return err
+		for _, ver := range versions {
+			if current == nil || ver.GreaterThan(current) {
+				status.Pending = append(status.Pending, lm[ver.String()]...)
 			}
 		}
-		if err := dumpFile.Close(); err != nil {
-			return err
-		}
+
+		result = append(result, status)
 	}
-	return nil
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+
+	return result, nil
 }
 
-func (sr *Registry) migrate(ctx context.Context, db Database, cli *mongo.Database, key string, migrations []Migration) (
-	from,
-	to *version.Version,
-	steps int, err error) {
-	// split them up by version
+// groupByVersion groups migrations by their normalized version and
+// returns the distinct versions in ascending order.
+func groupByVersion(migrations []Migration) (map[string][]Migration, version.Collection, error) {
 	lm := make(map[string][]Migration)
 	versions := version.Collection{}
 
 	for _, m := range migrations {
 		ver, err := version.NewSemver(m.Version)
 		if err != nil {
-			return nil, nil, 0, err
+			return nil, nil, err
+		}
+
+		if _, ok := lm[ver.String()]; !ok {
+			versions = append(versions, ver)
 		}
 		lm[ver.String()] = append(lm[ver.String()], m)
-		versions = append(versions, ver)
 	}
-
 	sort.Sort(versions)
 
+	return lm, versions, nil
+}
+
+// loadVersion returns the version of the last migration applied to key
+// or nil if there is none.
+func loadVersion(ctx context.Context, db Database, key string) (*version.Version, error) {
 	current, err := db.Load(ctx, key)
-	if err != nil && !errors.Is(err, ErrNotFound) {
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return version.NewSemver(current.Version)
+}
+
+func (sr *Registry) migrate(ctx context.Context, db Database, cli *mongo.Database, key string, migrations []Migration) (
+	from,
+	to *version.Version,
+	steps int, err error) {
+	// split them up by version
+	lm, versions, err := groupByVersion(migrations)
+	if err != nil {
 		return nil, nil, 0, err
 	}
 
-	var currentVersion *version.Version
-	if current != nil {
-		currentVersion, err = version.NewSemver(current.Version)
-		if err != nil {
-			return nil, nil, 0, err
-		}
+	currentVersion, err := loadVersion(ctx, db, key)
+	if err != nil {
+		return nil, nil, 0, err
 	}
 	from = currentVersion
 
+	store := sr.collections(cli)
+
 	for _, ver := range versions {
 		// skip this migration if the current version is already higher.
 		if currentVersion != nil && currentVersion.GreaterThanOrEqual(ver) {
 			continue
 		}
-		migrations := lm[ver.String()]
-		for _, m := range migrations {
-			t := time.Now()
-			if err := sr.backupCollections(ctx, cli, key, m.BackupCollections, t); err != nil {
+
+		var backups []dump
+		for _, m := range lm[ver.String()] {
+			dumps, err := dumpCollections(ctx, store, key, m.BackupCollections, time.Now())
+			if err != nil {
 				return from, currentVersion, steps, fmt.Errorf("failed to create backup: %w", err)
 			}
+			backups = append(backups, dumps...)
+
 			if err := m.MigrateFunc(ctx, currentVersion, ver, cli); err != nil {
-				// TODO(ppacher): should we try to restore the collections? We should at least have a dump
-				// file around.
-				return from, currentVersion, steps, fmt.Errorf("%s -> %s: %w", currentVersion, ver, err)
+				err = fmt.Errorf("%s -> %s: %w", currentVersion, ver, err)
+
+				if restoreErr := restoreDumps(ctx, store, backups); restoreErr != nil {
+					return from, currentVersion, steps, fmt.Errorf("%w (failed to restore backup: %s)", err, restoreErr)
+				}
+
+				return from, currentVersion, steps, err
 			}
 		}
 		steps++
@@ -205,3 +242,9 @@ func Add(m ...Migration) {
 func ApplyMigrations(ctx context.Context, db Database, cli *mongo.Database) (bool, error) {
 	return Default.ApplyMigrations(ctx, db, cli)
 }
+
+// Status returns the migration status of the default schema registry.
+// See Registry.Status for more information.
+func Status(ctx context.Context, db Database) ([]MigrationStatus, error) {
+	return Default.Status(ctx, db)
+}
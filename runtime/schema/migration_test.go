@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type memoryDatabase map[string]string
+
+func (db memoryDatabase) Load(_ context.Context, key string) (*MigrationRecord, error) {
+	ver, ok := db[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &MigrationRecord{Key: key, Version: ver}, nil
+}
+
+func (db memoryDatabase) Save(_ context.Context, key, ver string) error {
+	db[key] = ver
+
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	db := memoryDatabase{"door": "1.0.0"}
+
+	applied := make(map[string]int)
+	record := func(name string, err error) MigrateFunc {
+		return func(context.Context, *version.Version, *version.Version, *mongo.Database) error {
+			applied[name]++
+
+			return err
+		}
+	}
+
+	reg := NewRegistry()
+	reg.Add(
+		Migration{Key: "door", Version: "2.0.0", MigrateFunc: record("door-2a", nil)},
+		Migration{Key: "door", Version: "1.0.0", MigrateFunc: record("door-1", nil)},
+		Migration{Key: "door", Version: "2.0.0", MigrateFunc: record("door-2b", nil)},
+		Migration{Key: "hours", Version: "1.0.0", MigrateFunc: record("hours-1", errors.New("failed"))},
+	)
+
+	status, err := reg.Status(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, status, 2)
+	assert.Equal(t, "door", status[0].Key)
+	assert.Equal(t, "1.0.0", status[0].Current)
+	assert.Equal(t, "2.0.0", status[0].Latest)
+	assert.Len(t, status[0].Pending, 2)
+	assert.Equal(t, "", status[1].Current)
+	assert.Len(t, status[1].Pending, 1)
+
+	_, err = reg.ApplyMigrations(ctx, db, nil)
+	require.Error(t, err)
+	assert.Equal(t, 1, applied["hours-1"])
+	assert.Equal(t, "", db["hours"])
+
+	delete(reg.migrates, "hours")
+
+	_, err = reg.ApplyMigrations(ctx, db, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", db["door"])
+
+	// each migration of a pending version runs exactly once.
+	assert.Equal(t, 0, applied["door-1"])
+	assert.Equal(t, 1, applied["door-2a"])
+	assert.Equal(t, 1, applied["door-2b"])
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 	idmv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/idm/v1"
+	"github.com/tierklinik-dobersberg/cis/pkg/httperr"
 )
 
 var userContextKey = struct{ s string }{"user-context-key"}
@@ -61,8 +62,22 @@ func Middleware(provider UserProvider) func(next echo.HandlerFunc) echo.HandlerF
 func Require() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// FIXME(ppacher)
+			if UserFromCtx(c.Request().Context()) == nil {
+				return httperr.Unauthorized("authentication required")
+			}
+
 			return next(c)
 		}
 	}
 }
+
+// RolesFromCtx returns the IDs and names of all roles of the user
+// associated with ctx.
+func RolesFromCtx(ctx context.Context) []string {
+	var roles []string
+	for _, role := range UserFromCtx(ctx).GetRoles() {
+		roles = append(roles, role.GetId(), role.GetName())
+	}
+
+	return roles
+}
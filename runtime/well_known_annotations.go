@@ -18,6 +18,10 @@ func OverviewFields(fields ...string) conf.KeyValue {
 type PossibleValue struct {
 	Display string      `json:"display"`
 	Value   interface{} `json:"value"`
+
+	// Aliases holds alternative spellings of Value that are accepted
+	// as well but not offered in a user interface.
+	Aliases []string `json:"aliases,omitempty"`
 }
 
 type OneOfReference struct {
@@ -44,11 +48,13 @@ type OneOfAnnotation struct {
 	AllowCustomValue bool `json:"allowCustomValue"`
 }
 
+const oneOfKey = "vet.dobersberg.cis:schema/oneOf"
+
 // OneOf returns a new KeyValue for a OneOfAnnotation conf.Option
 // with it's allowe .Values member set to values.
 func OneOf(values ...PossibleValue) conf.KeyValue {
 	return conf.KeyValue{
-		Key: "vet.dobersberg.cis:schema/oneOf",
+		Key: oneOfKey,
 		Value: OneOfAnnotation{
 			Values: values,
 		},
@@ -60,7 +66,7 @@ func OneOf(values ...PossibleValue) conf.KeyValue {
 // for custom values.
 func OneOfWithCustom(values ...PossibleValue) conf.KeyValue {
 	return conf.KeyValue{
-		Key: "vet.dobersberg.cis:schema/oneOf",
+		Key: oneOfKey,
 		Value: OneOfAnnotation{
 			Values:           values,
 			AllowCustomValue: true,
@@ -71,7 +77,7 @@ func OneOfWithCustom(values ...PossibleValue) conf.KeyValue {
 // OneOfRef returns a new KeyValue for a OneOfReference conf.Option annotation.
 func OneOfRef(ref, valueField, displayField string, allowCustomValue ...bool) conf.KeyValue {
 	return conf.KeyValue{
-		Key: "vet.dobersberg.cis:schema/oneOf",
+		Key: oneOfKey,
 		Value: OneOfReference{
 			SchemaType:       ref,
 			ValueField:       valueField,
@@ -81,10 +87,15 @@ func OneOfRef(ref, valueField, displayField string, allowCustomValue ...bool) co
 	}
 }
 
+const readonlyKey = "vet.dobersberg.cis:schema/readonly"
+
 // Readonly returns a new Keyvalue that marks an entity as read-only.
+// When used on a Section it marks a configuration instance that cannot
+// be modified using ConfigSchema, e.g. because it's defined in a
+// configuration file.
 func Readonly() conf.KeyValue {
 	return conf.KeyValue{
-		Key:   "vet.dobersberg.cis:schema/readonly",
+		Key:   readonlyKey,
 		Value: true,
 	}
 }
@@ -111,20 +122,54 @@ type StringFormatAnnotation struct {
 	Format string `json:"format"`
 }
 
+const stringFormatKey = "vet.dobersberg.cis:schema/stringFormat"
+
 func StringFormat(format string) conf.KeyValue {
 	return conf.KeyValue{
-		Key: "vet.dobersberg.cis:schema/stringFormat",
+		Key: stringFormatKey,
 		Value: StringFormatAnnotation{
 			Format: format,
 		},
 	}
 }
 
+const secretKey = "vet.dobersberg.cis:schema/secret"
+
+// Secret returns a new KeyValue that marks a string option as secret.
+// Values of secret options are redacted by ConfigSchema.SchemaAsMap,
+// Export and revisions and may be encrypted at rest by the
+// configuration provider.
+func Secret() conf.KeyValue {
+	return conf.KeyValue{
+		Key:   secretKey,
+		Value: true,
+	}
+}
+
+// IsSecret reports whether spec is annotated with Secret.
+func IsSecret(spec conf.OptionSpec) bool {
+	secret, _ := spec.Annotations.Get(secretKey).(bool)
+
+	return secret
+}
+
+// Schema types of OneOfReference annotations that refer to the identity
+// provider instead of a configuration schema. Values are resolved using
+// the ReferenceResolver registered for the respective type.
+const (
+	RolesRef = "identity:roles"
+	UsersRef = "identity:users"
+)
+
 var (
-	OneOfRoles = OneOfRef("identity:roles", "name", "")
-	OneOfUsers = OneOfRef("identity:users", "name", "")
+	OneOfRoles = OneOfRef(RolesRef, "name", "")
+	OneOfUsers = OneOfRef(UsersRef, "name", "")
 )
 
 var (
 	IDRef = "_id"
+
+	// ReadonlyRef is set to true in maps returned by
+	// ConfigSchema.SchemaAsMap for read-only configuration instances.
+	ReadonlyRef = "_readonly"
 )
@@ -314,11 +314,13 @@ export class ConfigAPI {
     key: string,
     testID: string,
     config: SchemaInstance,
-    testConfig: SchemaInstance
+    testConfig: SchemaInstance,
+    id?: string
   ): Observable<{ error?: string } | null> {
     return this.http.post<{ error?: string } | null>(
       `/api/config/v1/test/${key}/${testID}`,
       {
+        id: id,
         config: config,
         testConfig: testConfig,
       }
@@ -50,6 +50,11 @@ export class SettingTestComponent implements OnInit {
     return this.data.config
   }
 
+  /** The ID of the tested instance, used to restore redacted secrets. */
+  get instanceID(): string | undefined {
+    return this.data.id
+  }
+
   ngOnInit(): void {
     this.destroy$.next();
     this.destroy$.complete();
@@ -65,7 +70,8 @@ export class SettingTestComponent implements OnInit {
         this.schema.name,
         this.selectedTest.id,
         this.config,
-        this.values
+        this.values,
+        this.instanceID
       )
       .subscribe({
         next: (res) => {
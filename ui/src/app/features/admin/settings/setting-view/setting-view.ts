@@ -173,6 +173,7 @@ export class SettingViewComponent implements OnInit, OnDestroy {
       context: {
         schema: this.schema,
         config: this.configs,
+        id: this.singleModeID,
       },
       contentClass: 'w-1/2',
     });